@@ -5,7 +5,6 @@ go 1.21
 require (
 	github.com/PagerDuty/go-pagerduty v0.0.0-20170914160704-078a3284fb0e
 	github.com/Sirupsen/logrus v1.0.3
-	github.com/carlescere/scheduler v0.0.0-20170109141437-ee74d2f83d82
 	github.com/gorilla/mux v1.6.0
 	github.com/onsi/ginkgo/v2 v2.12.1
 	github.com/onsi/gomega v1.27.10
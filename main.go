@@ -1,43 +1,72 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"reflect"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/alphagov/paas-rubbernecker/pkg/github"
+	"github.com/alphagov/paas-rubbernecker/pkg/ical"
+	"github.com/alphagov/paas-rubbernecker/pkg/jira"
+	"github.com/alphagov/paas-rubbernecker/pkg/memory"
+	"github.com/alphagov/paas-rubbernecker/pkg/notify"
+	"github.com/alphagov/paas-rubbernecker/pkg/opsgenie"
 	"github.com/alphagov/paas-rubbernecker/pkg/pagerduty"
 	"github.com/alphagov/paas-rubbernecker/pkg/pivotal"
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
-	"github.com/carlescere/scheduler"
+	"github.com/alphagov/paas-rubbernecker/pkg/storage"
 	"github.com/gorilla/mux"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 	yaml "gopkg.in/yaml.v2"
 )
 
 var (
-	etag      time.Time
-	cards     rubbernecker.Cards
-	doneCards rubbernecker.Cards
-	members   rubbernecker.Members
-	support   = rubbernecker.SupportRota{
-		"in-hours":           &rubbernecker.Support{},
-		"in-hours-comms":     &rubbernecker.Support{},
-		"out-of-hours":       &rubbernecker.Support{},
-		"out-of-hours-comms": &rubbernecker.Support{},
-		"escalations":        &rubbernecker.Support{},
-	}
-
 	verbose = kingpin.Flag("verbose", "Will enable the DEBUG logging level.").Default("false").Short('v').OverrideDefaultFromEnvar("DEBUG").Bool()
 	port    = kingpin.Flag("port", "Port the application should listen for the traffic on.").Default("8080").Short('p').OverrideDefaultFromEnvar("PORT").Int64()
 
-	pivotalProjectID   = kingpin.Flag("pivotal-project", "Pivotal Tracker project ID rubbernecker will be using.").OverrideDefaultFromEnvar("PIVOTAL_TRACKER_PROJECT_ID").Int64()
-	pivotalAPIToken    = kingpin.Flag("pivotal-token", "Pivotal Tracker API token rubbernecker will use to communicate with Pivotal API.").OverrideDefaultFromEnvar("PIVOTAL_TRACKER_API_TOKEN").String()
-	pagerdutyAuthToken = kingpin.Flag("pagerduty-token", "PagerDuty auth token rubbernecker will use to communicate with PagerDuty API.").OverrideDefaultFromEnvar("PAGERDUTY_AUTHTOKEN").String()
+	pivotalProjects     = kingpin.Flag("pivotal-project", "Pivotal Tracker project ID rubbernecker will be using, optionally named e.g. platform=123. Can be repeated or comma separated.").OverrideDefaultFromEnvar("PIVOTAL_TRACKER_PROJECT_ID").Strings()
+	pivotalAPIToken     = kingpin.Flag("pivotal-token", "Pivotal Tracker API token rubbernecker will use to communicate with Pivotal API.").OverrideDefaultFromEnvar("PIVOTAL_TRACKER_API_TOKEN").String()
+	pivotalWebhookToken = kingpin.Flag("pivotal-webhook-token", "Token the Pivotal Tracker activity webhooks should be sent with, e.g. /webhooks/pivotal?token=<token>. The webhooks are not accepted if not set.").OverrideDefaultFromEnvar("PIVOTAL_WEBHOOK_TOKEN").String()
+	pivotalReconcile    = kingpin.Flag("pivotal-reconcile-interval", "How often all the stories should be fetched when the Pivotal Tracker activity webhooks are accepted.").Default("5m").OverrideDefaultFromEnvar("PIVOTAL_RECONCILE_INTERVAL").Duration()
+	githubProjects      = kingpin.Flag("github-project", "GitHub project rubbernecker will be using, as owner/number, optionally named e.g. platform=alphagov/5. Can be repeated or comma separated.").OverrideDefaultFromEnvar("GITHUB_PROJECT").Strings()
+	githubToken         = kingpin.Flag("github-token", "GitHub token rubbernecker will use to communicate with GitHub API.").OverrideDefaultFromEnvar("GITHUB_TOKEN").String()
+	jiraURL             = kingpin.Flag("jira-url", "Jira instance rubbernecker will be using, e.g. https://example.atlassian.net.").OverrideDefaultFromEnvar("JIRA_URL").String()
+	jiraProjects        = kingpin.Flag("jira-project", "Jira project key rubbernecker will be using, optionally named e.g. platform=PAAS. Can be repeated or comma separated.").OverrideDefaultFromEnvar("JIRA_PROJECT").Strings()
+	jiraUsername        = kingpin.Flag("jira-username", "Jira user rubbernecker will communicate with Jira API as.").OverrideDefaultFromEnvar("JIRA_USERNAME").String()
+	jiraToken           = kingpin.Flag("jira-token", "Jira API token rubbernecker will use to communicate with Jira API.").OverrideDefaultFromEnvar("JIRA_API_TOKEN").String()
+	pagerdutyAuthToken  = kingpin.Flag("pagerduty-token", "PagerDuty auth token rubbernecker will use to communicate with PagerDuty API.").OverrideDefaultFromEnvar("PAGERDUTY_AUTHTOKEN").String()
+	pagerdutyServices   = kingpin.Flag("pagerduty-service", "PagerDuty service ID the open incidents should be shown for. Can be repeated or comma separated. The incidents are not shown if not set.").OverrideDefaultFromEnvar("PAGERDUTY_SERVICE_IDS").Strings()
+
+	supportSource = kingpin.Flag("support-source", "Where the support rota should be fetched from: pagerduty, opsgenie or ical.").Default("pagerduty").OverrideDefaultFromEnvar("SUPPORT_SOURCE").Enum("pagerduty", "opsgenie", "ical")
+	opsgenieURL   = kingpin.Flag("opsgenie-url", "Opsgenie API rubbernecker will fetch the support rota from, e.g. https://api.eu.opsgenie.com for the accounts hosted in the EU.").Default(opsgenie.DefaultBaseURL).OverrideDefaultFromEnvar("OPSGENIE_API_URL").String()
+	opsgenieKey   = kingpin.Flag("opsgenie-key", "Opsgenie API key rubbernecker will use to communicate with Opsgenie API.").OverrideDefaultFromEnvar("OPSGENIE_API_KEY").String()
+	icalRota      = kingpin.Flag("ical-rota", "iCalendar (.ics) file, or URL, of the events of the support rota.").OverrideDefaultFromEnvar("ICAL_ROTA").String()
+	leaveCalendar = kingpin.Flag("leave-calendar", "iCalendar (.ics) file, or URL, of the leave of the team, which is not free to pick up new work while away.").OverrideDefaultFromEnvar("LEAVE_CALENDAR").String()
+
+	doneWindow  = kingpin.Flag("done-window", "How far back the done cards should be shown from by default: days:N, working-days:N, iteration or since:<weekday>.").Default("days:5").OverrideDefaultFromEnvar("DONE_WINDOW").String()
+	doneHistory = kingpin.Flag("done-history", "How far back the done cards should be fetched from, for the done query parameter to choose from. Same format as, and defaults to, the done-window.").OverrideDefaultFromEnvar("DONE_HISTORY").String()
+
+	staleAfter = kingpin.Flag("stale-after", "How old the data can get before the application is considered unhealthy, or twice the refresh interval of the source if longer.").Default("10m").OverrideDefaultFromEnvar("STALE_AFTER").Duration()
+
+	workflowFile = kingpin.Flag("workflow", "YAML file describing the columns of the board and the upstream states mapped into them.").Default("workflow.yml").OverrideDefaultFromEnvar("WORKFLOW_FILE").String()
+
+	rotaFile = kingpin.Flag("rota", "YAML file describing the support roles shown on the board and the schedules, by ID or name, they are taken from.").Default("rota.yml").OverrideDefaultFromEnvar("ROTA_FILE").String()
+
+	filtersFile = kingpin.Flag("filters", "YAML file describing the quick filters shown above the board.").Default("filters.yml").OverrideDefaultFromEnvar("FILTERS_FILE").String()
+
+	notificationsFile = kingpin.Flag("notifications", "YAML file describing which of the events on the board the team should be notified about.").Default("notifications.yml").OverrideDefaultFromEnvar("NOTIFICATIONS_FILE").String()
+	slackWebhookURL   = kingpin.Flag("slack-webhook-url", "Slack incoming webhook the notifications should be posted to. These are only logged if not set.").OverrideDefaultFromEnvar("SLACK_WEBHOOK_URL").String()
+
+	storageDir        = kingpin.Flag("storage-dir", "Directory the snapshots of the board should be persisted in. These are kept in memory only if not set.").OverrideDefaultFromEnvar("STORAGE_DIR").String()
+	snapshotRetention = kingpin.Flag("snapshot-retention", "How long the previous versions of the board should be kept for. Only the latest one is kept if zero.").Default("168h").OverrideDefaultFromEnvar("SNAPSHOT_RETENTION").Duration()
 )
 
 func setupLogger() {
@@ -65,130 +94,636 @@ func combineCards(collections ...rubbernecker.Cards) rubbernecker.Cards {
 	return all
 }
 
-func fetchStories(pt *pivotal.Tracker) error {
-	if members == nil {
-		return fmt.Errorf("rubbernecker: could not find any members")
+type namedProject struct {
+	value string
+	name  string
+	id    string
+}
+
+// parseNamedProjects reads the values of the project flags, which can be
+// repeated or comma separated. Each value is the ID of the project, optionally
+// prefixed with a name the cards will be tagged with, e.g. "platform=123".
+func parseNamedProjects(values []string) []namedProject {
+	projects := []namedProject{}
+
+	for _, value := range values {
+		for _, project := range strings.Split(value, ",") {
+			project = strings.TrimSpace(project)
+			if project == "" {
+				continue
+			}
+
+			name, id := project, project
+			if i := strings.Index(project, "="); i >= 0 {
+				name, id = project[:i], project[i+1:]
+			}
+
+			projects = append(projects, namedProject{value: project, name: name, id: id})
+		}
+	}
+
+	return projects
+}
+
+// parsePivotalProjects turns the values of the pivotal-project flag into
+// sources. Each value is a project ID, optionally prefixed with a name the
+// cards will be tagged with, e.g. "platform=123".
+func parsePivotalProjects(values []string, token string) (rubbernecker.Sources, error) {
+	sources := rubbernecker.Sources{}
+
+	for _, project := range parseNamedProjects(values) {
+		projectID, err := strconv.ParseInt(project.id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("rubbernecker: invalid pivotal project %q: %s", project.value, err)
+		}
+
+		pt, err := pivotal.New(projectID, token)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, &rubbernecker.Source{Name: project.name, Service: pt})
+	}
+
+	return sources, nil
+}
+
+// parseGitHubProjects turns the values of the github-project flag into
+// sources. Each value is the owner and the number of the project, optionally
+// prefixed with a name, e.g. "platform=alphagov/5".
+func parseGitHubProjects(values []string, token string) (rubbernecker.Sources, error) {
+	sources := rubbernecker.Sources{}
+
+	for _, project := range parseNamedProjects(values) {
+		i := strings.LastIndex(project.id, "/")
+		if i < 0 {
+			return nil, fmt.Errorf("rubbernecker: invalid github project %q: expected owner/number", project.value)
+		}
+
+		number, err := strconv.Atoi(project.id[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("rubbernecker: invalid github project %q: %s", project.value, err)
+		}
+
+		p, err := github.New(project.id[:i], number, token)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, &rubbernecker.Source{Name: project.name, Service: p})
+	}
+
+	return sources, nil
+}
+
+// parseJiraProjects turns the values of the jira-project flag into sources.
+// Each value is the key of the project, optionally prefixed with a name, e.g.
+// "platform=PAAS".
+func parseJiraProjects(values []string, baseURL, username, token string) (rubbernecker.Sources, error) {
+	sources := rubbernecker.Sources{}
+
+	for _, project := range parseNamedProjects(values) {
+		b, err := jira.New(baseURL, project.id, username, token)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, &rubbernecker.Source{Name: project.name, Service: b})
+	}
+
+	return sources, nil
+}
+
+// combineSources will compose the board of the projects of all the
+// extensions, of which there has to be at least one.
+func combineSources(collections ...rubbernecker.Sources) (rubbernecker.Sources, error) {
+	all := rubbernecker.Sources{}
+
+	for _, collection := range collections {
+		all = append(all, collection...)
+	}
+
+	if len(all) == 0 {
+		return nil, fmt.Errorf("rubbernecker: at least one pivotal, github or jira project is required")
 	}
 
-	err := pt.FetchCards(rubbernecker.StatusAll, map[string]string{})
+	return all, nil
+}
+
+// parsePagerDutyServices will read the IDs of the PagerDuty services, which can
+// be repeated or comma separated.
+func parsePagerDutyServices(values []string) []string {
+	ids := []string{}
+
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// setupSupport will compose the service the support rota is fetched from. The
+// support rota is not fetched from PagerDuty without the auth token.
+func setupSupport(source, pagerdutyToken, opsgenieURL, opsgenieKey, icalRota string) (rubbernecker.SupportService, error) {
+	switch source {
+	case "", "pagerduty":
+		if pagerdutyToken == "" {
+			return nil, nil
+		}
+		return pagerduty.New(pagerdutyToken), nil
+	case "opsgenie":
+		return opsgenie.New(opsgenieURL, opsgenieKey)
+	case "ical":
+		return ical.New(icalRota)
+	}
+
+	return nil, fmt.Errorf("rubbernecker: unknown support source %q", source)
+}
+
+// loadWorkflow will read the columns of the board from the YAML file.
+func loadWorkflow(path string) (rubbernecker.Workflow, error) {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var workflow rubbernecker.Workflow
+	if err := yaml.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("rubbernecker: invalid workflow %s: %s", path, err)
 	}
 
-	c, err := pt.FlattenStories()
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// loadRota will read the support roles from the YAML file.
+func loadRota(path string) (rubbernecker.Rota, error) {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Debug(err)
+		return nil, err
 	}
 
-	year, month, day := time.Now().Date()
-	past := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -5).UnixNano() / int64(time.Millisecond)
-	err = pt.FetchCards(rubbernecker.StatusDone, map[string]string{
-		"accepted_after": fmt.Sprintf("%d", past),
-	})
+	var rota rubbernecker.Rota
+	if err := yaml.Unmarshal(data, &rota); err != nil {
+		return nil, fmt.Errorf("rubbernecker: invalid rota %s: %s", path, err)
+	}
+
+	if err := rota.Validate(); err != nil {
+		return nil, err
+	}
+
+	return rota, nil
+}
+
+// loadFilters will read the quick filters from the YAML file.
+func loadFilters(path string) ([]rubbernecker.Filter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters rubbernecker.SavedFilters
+	if err := yaml.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("rubbernecker: invalid filters %s: %s", path, err)
+	}
+
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return filters.Filters(), nil
+}
+
+// loadNotificationRules will read the rules of the notifications from the YAML
+// file. The rules left out are disabled.
+func loadNotificationRules(path string, workflow rubbernecker.Workflow) (notify.Rules, error) {
+	var rules notify.Rules
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("rubbernecker: invalid notifications %s: %s", path, err)
+	}
+
+	return rules, rules.Validate(workflow)
+}
+
+// setupNotifier will post the notifications to Slack, or only log them if the
+// webhook is not set.
+func setupNotifier(url string) (notify.Notifier, error) {
+	if url == "" {
+		log.Debug("Notifications will be logged only.")
+		return notify.Log{}, nil
+	}
+
+	return notify.NewSlack(url)
+}
+
+func setupStorage(dir string) (rubbernecker.PersistanceEngine, error) {
+	if dir == "" {
+		log.Debug("Snapshots will be kept in memory.")
+		return memory.SetupEngine(), nil
+	}
+
+	return storage.SetupEngine(dir)
+}
+
+// restoreSnapshot will warm start the board from the latest persisted
+// snapshot, so that it can be served while the first fetch is in flight. The
+// changes to the cards are worked out from the persisted history, so that the
+// digest still knows what has moved.
+func restoreSnapshot(board *rubbernecker.Board, engine rubbernecker.PersistanceEngine) error {
+	snapshot, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
 	if err != nil {
 		return err
 	}
 
-	d, err := pt.FlattenStories()
+	board.Restore(snapshot)
+
+	log.Debug("Board has been restored from the snapshot ", rubbernecker.SnapshotKey(snapshot))
+
+	changes, err := rubbernecker.LoadChanges(engine)
 	if err != nil {
-		log.Debug(err)
+		return err
 	}
-	d.Reverse()
 
-	for s, story := range c {
-		if story.Assignees == nil {
-			continue
+	board.RestoreChanges(changes)
+
+	return nil
+}
+
+// persistSnapshots will write every new version of the board received through
+// the engine, keeping the previous ones for the retention.
+func persistSnapshots(updates <-chan *rubbernecker.Snapshot, engine rubbernecker.PersistanceEngine, retention time.Duration) {
+	for snapshot := range updates {
+		if err := rubbernecker.SaveSnapshot(engine, snapshot, retention); err != nil {
+			log.Error(err)
 		}
+	}
+}
+
+// parseDoneWindows turns the values of the done-window and done-history flags
+// into windows. The history defaults to the window.
+func parseDoneWindows(window, history string) (rubbernecker.DoneWindow, rubbernecker.DoneWindow, error) {
+	w, err := rubbernecker.ParseDoneWindow(window)
+	if err != nil {
+		return w, w, err
+	}
+
+	if history == "" {
+		return w, w, nil
+	}
+
+	h, err := rubbernecker.ParseDoneWindow(history)
+
+	return w, h, err
+}
+
+// staleAfterInterval works out how old the data from the source refreshed at
+// the interval can get, before it is considered stale.
+func staleAfterInterval(staleAfter, interval time.Duration) time.Duration {
+	if staleAfter < 2*interval {
+		return 2 * interval
+	}
+
+	return staleAfter
+}
+
+// server holds the dependencies of the HTTP handlers.
+type server struct {
+	board        *rubbernecker.Board
+	sources      rubbernecker.Sources
+	upstreams    *rubbernecker.Upstreams
+	config       *rubbernecker.Config
+	keepAlive    time.Duration
+	doneWindow   rubbernecker.DoneWindow
+	doneHistory  rubbernecker.DoneWindow
+	webhookToken string
+	overLimit    *rubbernecker.Sticker
+	filters      []rubbernecker.Filter
+}
+
+// newServer will compose the server with the defaults for the board.
+func newServer(board *rubbernecker.Board) *server {
+	return &server{
+		board:     board,
+		upstreams: rubbernecker.NewUpstreams(),
+		config: &rubbernecker.Config{
+			Workflow: rubbernecker.DefaultWorkflow(),
+			Rota:     rubbernecker.DefaultRota(),
+		},
+		keepAlive: 30 * time.Second,
+	}
+}
 
-		for i, a := range story.Assignees {
-			if member, ok := members[a.ID]; ok {
-				(c[s].Assignees)[i] = member
+// newRefresher will compose the refresher of the upstream service, recording
+// the outcome of each of the fetches and logging the failures.
+func (s *server) newRefresher(name string, interval time.Duration, fetch refresher.Fetch) *refresher.Refresher {
+	r := refresher.New(name, interval, fetch)
+	r.Recorder = s.upstreams
+	r.OnError = func(err error) {
+		log.Error(err)
+	}
+
+	return r
+}
+
+// assignMembers will replace the assignees of the cards, which are only known
+// by their IDs, with the team members.
+func assignMembers(cards rubbernecker.Cards, members rubbernecker.Members) {
+	for _, card := range cards {
+		for id := range card.Assignees {
+			if member, ok := members[id]; ok {
+				card.Assignees[id] = member
 			}
 		}
 	}
+}
+
+// fetchStories will fetch the cards in play and the done cards accepted
+// within the earliest of the windows.
+func fetchStories(ctx context.Context, board *rubbernecker.Board, sources rubbernecker.Sources, windows ...rubbernecker.DoneWindow) error {
+	snapshot := board.Snapshot()
+	members := snapshot.Members
+	if members == nil {
+		return fmt.Errorf("rubbernecker: could not find any members")
+	}
 
-	if !reflect.DeepEqual(cards, c) {
-		cards = c
-		etag = time.Now()
+	now := time.Now()
+	var past time.Time
+	for i, w := range windows {
+		since, err := w.Since(now, snapshot.IterationStart)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 || since.Before(past) {
+			past = since
+		}
 	}
 
-	if !reflect.DeepEqual(doneCards, d) {
-		doneCards = d
-		etag = time.Now()
+	if past.IsZero() {
+		return fmt.Errorf("rubbernecker: the done cards cannot be fetched without a done window")
 	}
 
+	started := time.Now()
+	c, err := sources.FetchCards(ctx, rubbernecker.StatusAll, map[string]string{})
+	if err != nil {
+		return err
+	}
+
+	d, err := sources.FetchCards(ctx, rubbernecker.StatusDone, map[string]string{
+		"accepted_after": fmt.Sprintf("%d", past.UnixNano()/int64(time.Millisecond)),
+	})
+	if err != nil {
+		return err
+	}
+	d.SortByAcceptedAt()
+
+	assignMembers(c, members)
+	assignMembers(d, members)
+
+	board.PublishFetchedCards(started, c, d)
+
 	log.Debug("Stories have been fetched.")
 
 	return nil
 }
 
-func fetchSupport(pd *pagerduty.Schedule) error {
-	if pd.Client == nil {
-		return fmt.Errorf("PAGERDUTY_AUTHTOKEN is not set, support rota will not be fetched")
+// fetchSupport will fetch who is on call for the schedules of the rota. The
+// schedules of the rota which cannot be found are warned about, as these may
+// have been renamed.
+func fetchSupport(ctx context.Context, board *rubbernecker.Board, service rubbernecker.SupportService, rota rubbernecker.Rota) error {
+	err := service.FetchSupport(ctx)
+	if err != nil {
+		return err
+	}
+
+	s, err := service.FlattenSupport()
+	if err != nil {
+		return err
+	}
+
+	if missing, available := rota.Unmatched(s); len(missing) > 0 {
+		log.Warnf("Support rota schedules %q could not be found, the schedules on call are %q", missing, available)
+	}
+
+	board.PublishSupport(rota.Map(s))
+
+	log.Debug("Support Rota have been fetched.")
+
+	return nil
+}
+
+// fetchIncidents will fetch the incidents which are yet to be resolved.
+func fetchIncidents(ctx context.Context, board *rubbernecker.Board, service rubbernecker.IncidentService) error {
+	err := service.FetchIncidents(ctx)
+	if err != nil {
+		return err
 	}
 
-	err := pd.FetchSupport()
+	incidents, err := service.FlattenIncidents()
 	if err != nil {
 		return err
 	}
 
-	s, err := pd.FlattenSupport()
+	board.PublishIncidents(incidents)
+
+	log.Debug("Incidents have been fetched.")
+
+	return nil
+}
+
+// fetchLeave will fetch the absences of the team.
+func fetchLeave(ctx context.Context, board *rubbernecker.Board, service rubbernecker.LeaveService) error {
+	err := service.FetchLeave(ctx)
 	if err != nil {
 		return err
 	}
 
-	s = formatSupportNames(s)
+	leave, err := service.FlattenLeave()
+	if err != nil {
+		return err
+	}
+
+	board.PublishLeave(leave)
+
+	log.Debug("Leave has been fetched.")
+
+	return nil
+}
+
+// applyActivity will update the board with the stories the activity of the
+// PivotalTracker project has affected. The stories moved off the board, such
+// as into the icebox, are removed from it.
+func applyActivity(ctx context.Context, board *rubbernecker.Board, source *rubbernecker.Source, tracker *pivotal.Tracker, activity *pivotal.Activity) error {
+	for _, id := range activity.Deleted {
+		board.RemoveCard(source.Name, id)
+	}
+
+	for _, id := range activity.Updated {
+		card, err := tracker.FetchCard(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if card.Status == "unknown" {
+			board.RemoveCard(source.Name, id)
+			continue
+		}
+
+		card.Project = source.Name
+		assignMembers(rubbernecker.Cards{card}, board.Snapshot().Members)
 
-	if !reflect.DeepEqual(support, s) {
-		support = s
-		etag = time.Now()
+		board.PublishCard(card)
 	}
 
-	log.Debug("Support Rota have been fetched.")
+	log.Debug("Activity has been applied: ", activity.Kind)
 
 	return nil
 }
 
-func fetchUsers(pt *pivotal.Tracker) error {
-	err := pt.FetchMembers()
+// fetchIteration will find out the start of the current iteration, for the
+// done windows relying on it.
+func fetchIteration(ctx context.Context, board *rubbernecker.Board, sources rubbernecker.Sources) error {
+	start, err := sources.IterationStart(ctx)
 	if err != nil {
 		return err
 	}
 
-	m, err := pt.FlattenMembers()
+	board.PublishIterationStart(start)
+
+	log.Debug("Iteration start has been fetched.")
+
+	return nil
+}
+
+func fetchUsers(ctx context.Context, board *rubbernecker.Board, sources rubbernecker.Sources) error {
+	m, err := sources.FetchMembers(ctx)
 	if err != nil {
 		return err
 	}
 
-	if !reflect.DeepEqual(members, m) {
-		members = m
-		etag = time.Now()
-	}
+	board.PublishMembers(m)
 
 	log.Debug("Team Members have been fetched.")
 
 	return nil
 }
 
-func formatSupportNames(s rubbernecker.SupportRota) rubbernecker.SupportRota {
-	return rubbernecker.SupportRota{
-		"in-hours":           s.Get("PaaS team rota - in hours"),
-		"in-hours-comms":     s.Get("PaaS team rota - comms lead (in Hours)"),
-		"out-of-hours":       s.Get("PaaS team rota - out of hours"),
-		"out-of-hours-comms": s.Get("PaaS team rota - comms lead (OOH)"),
-		"escalations":        s.Get("P&T SCS Escalation"),
+// doneSince works out the beginning of the requested done window, falling back
+// to the default one if the requested window is invalid or cannot be resolved.
+// The windows going beyond the done history are cut to it, as the older done
+// cards have not been fetched.
+func (s *server) doneSince(snapshot *rubbernecker.Snapshot, requested string) (time.Time, error) {
+	now := time.Now()
+	since, err := s.doneWindow.Since(now, snapshot.IterationStart)
+	if requested == "" {
+		return since, err
+	}
+
+	window, err := rubbernecker.ParseDoneWindow(requested)
+	if err != nil {
+		return since, err
+	}
+
+	requestedSince, err := window.Since(now, snapshot.IterationStart)
+	if err != nil {
+		return since, err
+	}
+
+	historySince, err := s.doneHistory.Since(now, snapshot.IterationStart)
+	if err == nil && requestedSince.Before(historySince) {
+		return historySince, fmt.Errorf("rubbernecker: done window %s goes beyond the done history %s, only the cards done since %s are shown", window, s.doneHistory, historySince.Format(time.RFC3339))
+	}
+
+	return requestedSince, nil
+}
+
+// prepareResponse composes the board response out of the snapshot, with the
+// filters and the done window, if any, from the query applied.
+func (s *server) prepareResponse(resp *rubbernecker.Response, snapshot *rubbernecker.Snapshot, query url.Values) {
+	// The limits are checked before filtering, so that the cards over them
+	// can be filtered by the sticker.
+	cards := snapshot.Cards
+	breaches := s.config.Workflow.Breaches(cards)
+	if s.overLimit != nil {
+		cards = breaches.Apply(cards, *s.overLimit)
+	}
+
+	filterQueries := query["filter"]
+	filteredCards, filteredDoneCards := cards, snapshot.DoneCards
+	if filter, err := rubbernecker.ParseQueries(filterQueries); err != nil {
+		resp.WithError(err)
+	} else {
+		filteredCards = cards.Matching(filter)
+		filteredDoneCards = snapshot.DoneCards.Matching(filter)
+	}
+
+	since, err := s.doneSince(snapshot, query.Get("done"))
+	if err != nil {
+		resp.WithError(err)
+	}
+	filteredDoneCards = filteredDoneCards.AcceptedSince(since)
+
+	resp.
+		WithConfig(s.config).
+		WithCards(combineCards(filteredCards, filteredDoneCards), false).
+		WithSampleCard(&rubbernecker.Card{}).
+		WithTeamMembers(snapshot.Members).
+		WithFilters(s.filters).
+		WithAppliedFilterQueries(filterQueries).
+		WithTextFilters(filterQueries).
+		WithSupport(snapshot.Support).
+		WithAvailability(snapshot.Cards, s.config.Rota, snapshot.Leave, time.Now()).
+		WithIncidents(snapshot.Incidents)
+
+	if len(breaches) > 0 {
+		resp.WithBreaches(breaches)
+	}
+
+	if since, stale := s.upstreams.StaleSince(); stale {
+		resp.WithStaleSince(since)
 	}
 }
 
-func healthcheckHandler(w http.ResponseWriter, r *http.Request) {
+// healthcheckHandler reports the status of each of the upstream services. The
+// application is considered unhealthy once the data from any of them is stale.
+func (s *server) healthcheckHandler(w http.ResponseWriter, r *http.Request) {
 	resp := rubbernecker.Response{Message: "OK"}
-	resp.JSON(200, w)
+	code := http.StatusOK
+
+	if since, stale := s.upstreams.StaleSince(); stale {
+		code = http.StatusServiceUnavailable
+		resp.Message = "Stale"
+		resp.WithError(fmt.Errorf("rubbernecker: data is stale since %s", since.Format(time.RFC3339))).
+			WithStaleSince(since)
+	}
+
+	statuses := s.upstreams.Statuses()
+	if len(statuses) > 0 {
+		resp.WithUpstreams(statuses)
+	}
+
+	resp.JSON(code, w)
 }
 
-func indexHandler(w http.ResponseWriter, r *http.Request) {
+func (s *server) indexHandler(w http.ResponseWriter, r *http.Request) {
 	var err error
+	snapshot := s.board.Snapshot()
 	resp := rubbernecker.Response{}
-	et := strconv.FormatInt(etag.Unix(), 10)
+	et := strconv.FormatInt(snapshot.ETag.Unix(), 10)
 
 	if r.Header.Get("If-None-Match") == et {
 		resp.
@@ -197,24 +732,7 @@ func indexHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	filterQueries := r.URL.Query()["filter"]
-
-	filteredCards := cards.FilterBy(filterQueries)
-	filteredDoneCards := doneCards.FilterBy(filterQueries)
-
-	resp.
-		WithConfig(&rubbernecker.Config{
-			ReviewalLimit: 4,
-			ApprovalLimit: 5,
-		}).
-		WithCards(combineCards(filteredCards, filteredDoneCards), false).
-		WithSampleCard(&rubbernecker.Card{}).
-		WithTeamMembers(members).
-		WithFreeTeamMembers().
-		WithFilters(rubbernecker.DefaultFilterSet()).
-		WithAppliedFilterQueries(filterQueries).
-		WithTextFilters(filterQueries).
-		WithSupport(support)
+	s.prepareResponse(&resp, snapshot, r.URL.Query())
 
 	if strings.Contains(r.Header.Get("Accept"), "json") {
 		w.Header().Set("ETag", et)
@@ -233,18 +751,298 @@ func indexHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// pivotalSource finds the source of the PivotalTracker project with the ID.
+func (s *server) pivotalSource(projectID int64) (*rubbernecker.Source, *pivotal.Tracker) {
+	for _, source := range s.sources {
+		if tracker, ok := source.Service.(*pivotal.Tracker); ok && tracker.ProjectID() == projectID {
+			return source, tracker
+		}
+	}
+
+	return nil, nil
+}
+
+// pivotalWebhookHandler applies the activity of the PivotalTracker projects to
+// the board straight away, rather than waiting for the next fetch. The webhooks
+// have to be sent with the token, as PivotalTracker does not sign them.
+func (s *server) pivotalWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	resp := rubbernecker.Response{}
+
+	if r.Method != http.MethodPost {
+		resp.WithError(fmt.Errorf("rubbernecker: method not allowed")).JSON(http.StatusMethodNotAllowed, w)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if s.webhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.webhookToken)) != 1 {
+		resp.WithError(fmt.Errorf("rubbernecker: invalid webhook token")).JSON(http.StatusUnauthorized, w)
+		return
+	}
+
+	activity, err := pivotal.ParseActivity(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		resp.WithError(err).JSON(http.StatusBadRequest, w)
+		return
+	}
+
+	source, tracker := s.pivotalSource(activity.ProjectID)
+	if source == nil {
+		resp.WithError(fmt.Errorf("rubbernecker: unknown pivotal project %d", activity.ProjectID)).JSON(http.StatusBadRequest, w)
+		return
+	}
+
+	if !activity.Supported() {
+		resp.Message = "Ignored"
+		resp.JSON(http.StatusAccepted, w)
+		return
+	}
+
+	if err := applyActivity(r.Context(), s.board, source, tracker, activity); err != nil {
+		log.Error(err)
+		resp.WithError(err).JSON(http.StatusBadGateway, w)
+		return
+	}
+
+	resp.Message = "OK"
+	resp.JSON(http.StatusOK, w)
+}
+
+// changesHandler lists what has happened to the cards since the version of the
+// board provided with the since param, as found in the ETag.
+func (s *server) changesHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	resp := rubbernecker.Response{}
+
+	if param := r.URL.Query().Get("since"); param != "" {
+		etag, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			resp.WithError(fmt.Errorf("rubbernecker: invalid since param %q", param)).JSON(http.StatusBadRequest, w)
+			return
+		}
+
+		since = time.Unix(etag, 0)
+	}
+
+	err := resp.
+		WithChanges(s.board.Changes(since)).
+		JSON(http.StatusOK, w)
+
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// metricsHandler exposes the metrics of the board and the upstream services
+// in the Prometheus text exposition format.
+func (s *server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	err := rubbernecker.WritePrometheus(w, s.board.Snapshot(), s.config, s.upstreams.Statuses())
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// digestHandler summarises the board for the standup, in Markdown by default
+// or in plain text with the format param. The cards are considered stuck once
+// in play for more than the stuck param of working days.
+func (s *server) digestHandler(w http.ResponseWriter, r *http.Request) {
+	resp := rubbernecker.Response{}
+	query := r.URL.Query()
+
+	stuckAfter := 3
+	if param := query.Get("stuck"); param != "" {
+		days, err := strconv.Atoi(param)
+		if err != nil || days < 0 {
+			resp.WithError(fmt.Errorf("rubbernecker: invalid stuck param %q", param)).JSON(http.StatusBadRequest, w)
+			return
+		}
+		stuckAfter = days
+	}
+
+	now := time.Now()
+	previousDay, _ := rubbernecker.ParseDoneWindow("working-days:1")
+	since, _ := previousDay.Since(now, time.Time{})
+
+	snapshot := s.board.Snapshot()
+	resp.
+		WithCards(snapshot.Cards, false).
+		WithTeamMembers(snapshot.Members).
+		WithSupport(snapshot.Support).
+		WithAvailability(snapshot.Cards, s.config.Rota, snapshot.Leave, now)
+
+	free := resp.Availability.Members(rubbernecker.AvailabilityFree)
+	digest := rubbernecker.NewDigest(snapshot, s.board.Changes(since), s.config.Workflow, s.config.Rota, free, now, since, stuckAfter)
+
+	switch query.Get("format") {
+	case "", "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=UTF-8")
+		fmt.Fprint(w, digest.Markdown())
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		fmt.Fprint(w, digest.Text())
+	default:
+		resp = rubbernecker.Response{}
+		resp.WithError(fmt.Errorf("rubbernecker: invalid format param %q", query.Get("format"))).JSON(http.StatusBadRequest, w)
+	}
+}
+
+// supportHandler shows who is on call for each of the entries of the rota,
+// and when they hand over to whom, for as far ahead as fetched.
+func (s *server) supportHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	resp := rubbernecker.Response{}
+
+	resp.
+		WithConfig(s.config).
+		WithSupport(s.board.Timeline())
+
+	if strings.Contains(r.Header.Get("Accept"), "json") {
+		err = resp.JSON(http.StatusOK, w)
+	} else {
+		err = resp.Template(http.StatusOK, w, "./build/views/support.html")
+	}
+
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// flowHandler summarises the flow of the done cards, optionally filtered.
+func (s *server) flowHandler(w http.ResponseWriter, r *http.Request) {
+	doneCards := s.board.Snapshot().DoneCards
+	resp := rubbernecker.Response{}
+
+	filter, err := rubbernecker.ParseQueries(r.URL.Query()["filter"])
+	if err != nil {
+		resp.WithError(err).JSON(http.StatusBadRequest, w)
+		return
+	}
+
+	err = resp.
+		WithFlow(doneCards.Matching(filter).FlowMetrics()).
+		JSON(http.StatusOK, w)
+
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// eventsHandler streams the versions of the board to the client as
+// Server-Sent Events. The current version is sent straight away and each new
+// one as soon as it has been published, leaving it to the client to fetch the
+// board it shows. The data becoming stale, or fresh again, is only noticed with
+// the keep-alive.
+func (s *server) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		resp := rubbernecker.Response{}
+		resp.WithError(fmt.Errorf("rubbernecker: streaming is not supported")).JSON(http.StatusInternalServerError, w)
+		return
+	}
+
+	updates, unsubscribe := s.board.Subscribe()
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(s.keepAlive)
+	defer keepAlive.Stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	lastID, err := s.writeEvent(w, s.board.Snapshot())
+	flusher.Flush()
+
+	for err == nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			snapshot := s.board.Snapshot()
+			if s.eventID(snapshot) != lastID {
+				lastID, err = s.writeEvent(w, snapshot)
+			} else {
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			}
+		case snapshot := <-updates:
+			lastID, err = s.writeEvent(w, snapshot)
+		}
+
+		flusher.Flush()
+	}
+
+	log.Debug(err)
+}
+
+// eventID identifies the version of the board, as well as whether its data is
+// stale, so that the clients know when to show it again.
+func (s *server) eventID(snapshot *rubbernecker.Snapshot) string {
+	id := strconv.FormatInt(snapshot.ETag.UnixNano(), 10)
+
+	if since, stale := s.upstreams.StaleSince(); stale {
+		id = fmt.Sprintf("%s-stale-%d", id, since.Unix())
+	}
+
+	return id
+}
+
+// writeEvent sends the version of the board as the event. The browsers only
+// dispatch the events with some data, therefore the ID is sent as both.
+func (s *server) writeEvent(w http.ResponseWriter, snapshot *rubbernecker.Snapshot) (string, error) {
+	id := s.eventID(snapshot)
+	_, err := fmt.Fprintf(w, "id: %s\nevent: board\ndata: %s\n\n", id, id)
+
+	return id, err
+}
+
 func main() {
 	kingpin.Parse()
 	setupLogger()
 
-	var pd = &pagerduty.Schedule{
-		Client: nil,
+	supportService, err := setupSupport(*supportSource, *pagerdutyAuthToken, *opsgenieURL, *opsgenieKey, *icalRota)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var leaveService rubbernecker.LeaveService
+	if *leaveCalendar != "" {
+		leaveService, err = ical.New(*leaveCalendar)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var incidentService rubbernecker.IncidentService
+	if services := parsePagerDutyServices(*pagerdutyServices); len(services) > 0 {
+		if *pagerdutyAuthToken == "" {
+			log.Fatal("PAGERDUTY_AUTHTOKEN is not set, the incidents of the services cannot be fetched")
+		}
+		incidentService = pagerduty.NewIncidents(*pagerdutyAuthToken, services)
 	}
-	if pagerdutyAuthToken != nil && *pagerdutyAuthToken != "" {
-		pd = pagerduty.New(*pagerdutyAuthToken)
+
+	pivotalSources, err := parsePivotalProjects(*pivotalProjects, *pivotalAPIToken)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	pt, err := pivotal.New(*pivotalProjectID, *pivotalAPIToken)
+	githubSources, err := parseGitHubProjects(*githubProjects, *githubToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	jiraSources, err := parseJiraProjects(*jiraProjects, *jiraURL, *jiraUsername, *jiraToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sources, err := combineSources(pivotalSources, githubSources, jiraSources)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	window, history, err := parseDoneWindows(*doneWindow, *doneHistory)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -260,35 +1058,143 @@ func main() {
 		log.Fatal(err)
 	}
 
-	pt.AcceptStickers(approvedStickers)
+	workflow, err := loadWorkflow(*workflowFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rota, err := loadRota(*rotaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filters, err := loadFilters(*filtersFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, source := range sources {
+		source.Service.AcceptStickers(approvedStickers)
+
+		if ws, ok := source.Service.(rubbernecker.WorkflowService); ok {
+			ws.AcceptWorkflow(workflow)
+		}
+	}
+
+	engine, err := setupStorage(*storageDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	board := rubbernecker.NewBoard()
+	if err := restoreSnapshot(board, engine); err != nil {
+		log.Warn("Board could not be restored: ", err)
+	}
+
+	updates, _ := board.Feed()
+	go persistSnapshots(updates, engine, *snapshotRetention)
+
+	s := newServer(board)
+	s.sources = sources
+	s.config.Workflow = workflow
+	s.config.Rota = rota
+	s.filters = filters
+	s.doneWindow = window
+	s.doneHistory = history
+	s.webhookToken = *pivotalWebhookToken
+	if sticker, ok := approvedStickers.Get(rubbernecker.OverLimitSticker); ok {
+		s.overLimit = &sticker
+	} else {
+		log.Warn("The ", rubbernecker.OverLimitSticker, " sticker is not approved, the cards over the limits will not be given it")
+	}
+
+	rules, err := loadNotificationRules(*notificationsFile, workflow)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	notifier, err := setupNotifier(*slackWebhookURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	watcher := &notify.Watcher{Rules: rules, Workflow: workflow, Rota: rota, Notifier: notifier}
+	eventUpdates, _ := board.Feed()
+	go watcher.Watch(eventUpdates, board.Snapshot())
+
+	// The webhooks keep the board up to date, so that the stories only need to
+	// be fetched to correct any drift.
+	storiesInterval := 20 * time.Second
+	if s.webhookToken != "" {
+		storiesInterval = *pivotalReconcile
+	}
+
+	s.upstreams.Register("stories", staleAfterInterval(*staleAfter, storiesInterval))
+	s.upstreams.Register("members", staleAfterInterval(*staleAfter, time.Hour))
+	if supportService != nil {
+		s.upstreams.Register("support", staleAfterInterval(*staleAfter, 5*time.Minute))
+	}
+	if leaveService != nil {
+		s.upstreams.Register("leave", staleAfterInterval(*staleAfter, 15*time.Minute))
+	}
+	if incidentService != nil {
+		s.upstreams.Register("incidents", staleAfterInterval(*staleAfter, time.Minute))
+	}
+	if window.NeedsIteration() || history.NeedsIteration() {
+		s.upstreams.Register("iteration", staleAfterInterval(*staleAfter, time.Hour))
+	}
+
+	ctx := context.Background()
 
 	// We have to fetch the users synchronously first as the fetchStories call depends on it
-	if err := fetchUsers(pt); err != nil {
+	members := s.newRefresher("members", time.Hour, func(ctx context.Context) error { return fetchUsers(ctx, board, sources) })
+	delay, err := members.Refresh(ctx)
+	if err != nil {
 		log.Error(err)
 	}
+	go members.Run(ctx, delay)
 
-	scheduler.Every(1).Hours().NotImmediately().Run(func() {
-		if err := fetchUsers(pt); err != nil {
+	if window.NeedsIteration() || history.NeedsIteration() {
+		// Similarly, the done windows relying on the iteration depend on it
+		iteration := s.newRefresher("iteration", time.Hour, func(ctx context.Context) error { return fetchIteration(ctx, board, sources) })
+		delay, err := iteration.Refresh(ctx)
+		if err != nil {
 			log.Error(err)
 		}
-	})
+		go iteration.Run(ctx, delay)
+	}
 
-	scheduler.Every(5).Minutes().Run(func() {
-		if err := fetchSupport(pd); err != nil {
-			log.Error(err)
-		}
-	})
+	if supportService != nil {
+		support := s.newRefresher("support", 5*time.Minute, func(ctx context.Context) error { return fetchSupport(ctx, board, supportService, rota) })
+		go support.Run(ctx, 0)
+	} else {
+		log.Warn("PAGERDUTY_AUTHTOKEN is not set, support rota will not be fetched")
+	}
 
-	scheduler.Every(20).Seconds().Run(func() {
-		if err := fetchStories(pt); err != nil {
-			log.Error(err)
-		}
-	})
+	if leaveService != nil {
+		leave := s.newRefresher("leave", 15*time.Minute, func(ctx context.Context) error { return fetchLeave(ctx, board, leaveService) })
+		go leave.Run(ctx, 0)
+	}
+
+	if incidentService != nil {
+		incidents := s.newRefresher("incidents", time.Minute, func(ctx context.Context) error { return fetchIncidents(ctx, board, incidentService) })
+		go incidents.Run(ctx, 0)
+	}
+
+	stories := s.newRefresher("stories", storiesInterval, func(ctx context.Context) error { return fetchStories(ctx, board, sources, window, history) })
+	go stories.Run(ctx, 0)
 
 	r := mux.NewRouter()
-	r.HandleFunc("/", indexHandler)
-	r.HandleFunc("/state", indexHandler)
-	r.HandleFunc("/health-check", healthcheckHandler)
+	r.HandleFunc("/", s.indexHandler)
+	r.HandleFunc("/state", s.indexHandler)
+	r.HandleFunc("/health-check", s.healthcheckHandler)
+	r.HandleFunc("/events", s.eventsHandler)
+	r.HandleFunc("/changes", s.changesHandler)
+	r.HandleFunc("/metrics", s.metricsHandler)
+	r.HandleFunc("/metrics/flow", s.flowHandler)
+	r.HandleFunc("/digest", s.digestHandler)
+	r.HandleFunc("/support", s.supportHandler)
+	r.HandleFunc("/webhooks/pivotal", s.pivotalWebhookHandler)
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./dist/")))
 
 	http.ListenAndServe(fmt.Sprintf(":%d", *port), r)
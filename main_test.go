@@ -1,16 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alphagov/paas-rubbernecker/pkg/helpers"
+	"github.com/alphagov/paas-rubbernecker/pkg/ical"
+	"github.com/alphagov/paas-rubbernecker/pkg/memory"
+	"github.com/alphagov/paas-rubbernecker/pkg/notify"
+	"github.com/alphagov/paas-rubbernecker/pkg/opsgenie"
 	"github.com/alphagov/paas-rubbernecker/pkg/pagerduty"
 	"github.com/alphagov/paas-rubbernecker/pkg/pivotal"
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+	"github.com/alphagov/paas-rubbernecker/pkg/storage"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	httpmock "gopkg.in/jarcoal/httpmock.v1"
@@ -23,7 +35,12 @@ var _ = Describe("Main", func() {
 			pt  *pivotal.Tracker
 			pd  *pagerduty.Schedule
 
-			year, month, day = time.Now().Date()
+			sources rubbernecker.Sources
+			board   *rubbernecker.Board
+			s       *server
+
+			window, _        = rubbernecker.ParseDoneWindow("days:5")
+			year, month, day = time.Now().UTC().Date()
 			past             = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -5).UnixNano() / int64(time.Millisecond)
 
 			apiURL          = `https://www.pivotaltracker.com/services/v5/projects/123456/stories?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate&filter=state:unstarted,planned,started,finished,delivered,rejected`
@@ -40,8 +57,13 @@ var _ = Describe("Main", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pt).NotTo(BeNil())
 
+			sources = rubbernecker.Sources{&rubbernecker.Source{Name: "123456", Service: pt}}
+
 			pd = pagerduty.New("qwerty123456")
 
+			board = rubbernecker.NewBoard()
+			s = newServer(board)
+
 			httpmock.Activate()
 		})
 
@@ -50,19 +72,23 @@ var _ = Describe("Main", func() {
 		})
 
 		It("should fail to fetchStories() due to non-responsive API", func() {
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(500, ``))
 
-			err = fetchStories(pt)
+			err = fetchStories(context.Background(), board, sources, window)
 
 			Expect(err).To(HaveOccurred())
 		})
 
 		It("should fail to fetchStories() due to faulty API", func() {
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, `[]`))
 
-			err = fetchStories(pt)
+			err = fetchStories(context.Background(), board, sources, window)
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -71,16 +97,93 @@ var _ = Describe("Main", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err = fetchStories(pt)
+			err = fetchStories(context.Background(), board, sources, window)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to fetchStories() without a done window", func() {
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+
+			err = fetchStories(context.Background(), board, sources)
+
+			Expect(err).To(MatchError(ContainSubstring("without a done window")))
+		})
+
+		It("should fail to fetchStories() since the iteration which is unknown", func() {
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+			iteration, _ := rubbernecker.ParseDoneWindow("iteration")
+
+			err = fetchStories(context.Background(), board, sources, window, iteration)
+
+			Expect(err).To(MatchError(ContainSubstring("iteration is unknown")))
+		})
+
+		It("should fetchStories() since the earliest of the windows", func() {
+			start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -10)
+			accepted := fmt.Sprintf(`https://www.pivotaltracker.com/services/v5/projects/123456/stories?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate&accepted_after=%d`, start.UnixNano()/int64(time.Millisecond))
+
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+			httpmock.RegisterResponder("GET", accepted,
+				httpmock.NewStringResponder(200, `[
+					{"id": 1, "name": "Earlier", "current_state": "accepted", "transitions": [{"state": "accepted", "occurred_at": "2018-10-01T10:00:00Z"}]},
+					{"id": 2, "name": "Unknown", "current_state": "accepted"},
+					{"id": 3, "name": "Later", "current_state": "accepted", "transitions": [{"state": "accepted", "occurred_at": "2018-10-02T10:00:00Z"}]}
+				]`))
+			httpmock.RegisterResponder("GET", `https://www.pivotaltracker.com/services/v5/projects/123456/iterations`,
+				httpmock.NewStringResponder(200, fmt.Sprintf(`[{"start": "%s"}]`, start.Format(time.RFC3339))))
+
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+			iteration, _ := rubbernecker.ParseDoneWindow("iteration")
+
+			err = fetchIteration(context.Background(), board, sources)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(board.Snapshot().IterationStart).To(BeTemporally("==", start))
+
+			err = fetchStories(context.Background(), board, sources, window, iteration)
+			Expect(err).NotTo(HaveOccurred())
+
+			doneCards := board.Snapshot().DoneCards
+			Expect(doneCards).To(HaveLen(3))
+			Expect(doneCards[0].Title).To(Equal("Later"))
+			Expect(doneCards[1].Title).To(Equal("Earlier"))
+			Expect(doneCards[2].Title).To(Equal("Unknown"))
+		})
+
+		It("should fail to fetchIteration() due to faulty API", func() {
+			httpmock.RegisterResponder("GET", `https://www.pivotaltracker.com/services/v5/projects/123456/iterations`,
+				httpmock.NewStringResponder(200, `[]`))
+
+			err = fetchIteration(context.Background(), board, sources)
 
 			Expect(err).To(HaveOccurred())
+			Expect(board.Snapshot().IterationStart.IsZero()).To(BeTrue())
+		})
+
+		It("should parseDoneWindows() correctly", func() {
+			w, h, err := parseDoneWindows("days:5", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.String()).To(Equal("days:5"))
+			Expect(h.String()).To(Equal("days:5"))
+
+			w, h, err = parseDoneWindows("working-days:1", "iteration")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.String()).To(Equal("working-days:1"))
+			Expect(h.String()).To(Equal("iteration"))
+
+			_, _, err = parseDoneWindows("days:5", "fortnight")
+			Expect(err).To(HaveOccurred())
+
+			_, _, err = parseDoneWindows("", "")
+			Expect(err).To(HaveOccurred())
 		})
 
 		It("should fail to fetchUsers() due to faulty API", func() {
 			httpmock.RegisterResponder("GET", apiURLMembers,
 				httpmock.NewStringResponder(200, `[]`))
 
-			err = fetchUsers(pt)
+			err = fetchUsers(context.Background(), board, sources)
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -89,27 +192,61 @@ var _ = Describe("Main", func() {
 			httpmock.RegisterResponder("GET", apiURLMembers,
 				httpmock.NewStringResponder(200, responseMembers))
 
-			err = fetchUsers(pt)
+			err = fetchUsers(context.Background(), board, sources)
 
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should fetchStories() successfully", func() {
+			httpmock.RegisterResponder("GET", apiURLMembers,
+				httpmock.NewStringResponder(200, responseMembers))
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 			httpmock.RegisterResponder("GET", apiURLAccepted,
 				httpmock.NewStringResponder(200, response))
 
-			err = fetchStories(pt)
+			err = fetchUsers(context.Background(), board, sources)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fetchStories(context.Background(), board, sources, window)
+			Expect(err).NotTo(HaveOccurred())
+
+			snapshot := board.Snapshot()
+			Expect(snapshot.Cards).To(HaveLen(1))
+			Expect(snapshot.Cards[0].Assignees[1234].Name).To(Equal("Tester"))
+			Expect(snapshot.DoneCards).To(HaveLen(1))
+			Expect(snapshot.ETag.IsZero()).To(BeFalse())
+		})
+
+		It("should not bump the board version if nothing has changed", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, response),
+					httpmock.NewStringResponder(200, response),
+				))
+			httpmock.RegisterResponder("GET", apiURLAccepted,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, `[]`),
+					httpmock.NewStringResponder(200, `[]`),
+				))
 
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+
+			err = fetchStories(context.Background(), board, sources, window)
 			Expect(err).NotTo(HaveOccurred())
+			first := board.Snapshot()
+
+			err = fetchStories(context.Background(), board, sources, window)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(board.Snapshot()).To(BeIdenticalTo(first))
 		})
 
 		It("should fail to fetchSupport() due to faulty API", func() {
 			httpmock.RegisterResponder("GET", apiURLSupport,
 				httpmock.NewStringResponder(200, `[]`))
 
-			err = fetchSupport(pd)
+			err = fetchSupport(context.Background(), board, pd, rubbernecker.DefaultRota())
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -122,10 +259,10 @@ var _ = Describe("Main", func() {
 			]}`
 			httpmock.RegisterResponder("GET", apiURLSupport, httpmock.NewStringResponder(200, resp))
 
-			err = fetchSupport(pd)
+			err = fetchSupport(context.Background(), board, pd, rubbernecker.DefaultRota())
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
+			Expect(board.Snapshot().Support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
 				"in-hours-comms": {
 					Type:   "PaaS team rota - comms lead (in Hours)",
 					Member: "-",
@@ -133,6 +270,7 @@ var _ = Describe("Main", func() {
 				"out-of-hours": {
 					Type:   "PaaS team rota - out of hours",
 					Member: "X",
+					Shifts: []rubbernecker.Shift{{Member: "X"}},
 				},
 				"out-of-hours-comms": {
 					Type:   "PaaS team rota - comms lead (OOH)",
@@ -141,10 +279,12 @@ var _ = Describe("Main", func() {
 				"in-hours": {
 					Type:   "PaaS team rota - in hours",
 					Member: "Y",
+					Shifts: []rubbernecker.Shift{{Member: "Y"}},
 				},
 				"escalations": {
 					Type:   "P&T SCS Escalation",
 					Member: "Z",
+					Shifts: []rubbernecker.Shift{{Member: "Z"}},
 				},
 			})))
 		})
@@ -186,10 +326,10 @@ var _ = Describe("Main", func() {
 				),
 			)
 
-			err = fetchSupport(pd)
+			err = fetchSupport(context.Background(), board, pd, rubbernecker.DefaultRota())
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
+			Expect(board.Snapshot().Support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
 				"in-hours-comms": {
 					Type:   "PaaS team rota - comms lead (in Hours)",
 					Member: "-",
@@ -197,6 +337,7 @@ var _ = Describe("Main", func() {
 				"out-of-hours": {
 					Type:   "PaaS team rota - out of hours",
 					Member: "X",
+					Shifts: []rubbernecker.Shift{{Member: "X"}},
 				},
 				"out-of-hours-comms": {
 					Type:   "PaaS team rota - comms lead (OOH)",
@@ -205,10 +346,12 @@ var _ = Describe("Main", func() {
 				"in-hours": {
 					Type:   "PaaS team rota - in hours",
 					Member: "Y",
+					Shifts: []rubbernecker.Shift{{Member: "Y"}},
 				},
 				"escalations": {
 					Type:   "P&T SCS Escalation",
 					Member: "Z",
+					Shifts: []rubbernecker.Shift{{Member: "Z"}},
 				},
 			})))
 		})
@@ -220,10 +363,10 @@ var _ = Describe("Main", func() {
 			]}`
 			httpmock.RegisterResponder("GET", apiURLSupport, httpmock.NewStringResponder(200, resp))
 
-			err = fetchSupport(pd)
+			err = fetchSupport(context.Background(), board, pd, rubbernecker.DefaultRota())
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
+			Expect(board.Snapshot().Support).To(Equal(rubbernecker.SupportRota(map[string]*rubbernecker.Support{
 				"in-hours-comms": {
 					Type:   "PaaS team rota - comms lead (in Hours)",
 					Member: "-",
@@ -231,6 +374,7 @@ var _ = Describe("Main", func() {
 				"out-of-hours": {
 					Type:   "PaaS team rota - out of hours",
 					Member: "X",
+					Shifts: []rubbernecker.Shift{{Member: "X"}},
 				},
 				"out-of-hours-comms": {
 					Type:   "PaaS team rota - comms lead (OOH)",
@@ -243,30 +387,1116 @@ var _ = Describe("Main", func() {
 				"escalations": {
 					Type:   "P&T SCS Escalation",
 					Member: "Z",
+					Shifts: []rubbernecker.Shift{{Member: "Z"}},
 				},
 			})))
 		})
 
+		It("should setupSupport() from the source chosen", func() {
+			service, err := setupSupport("pagerduty", "", opsgenie.DefaultBaseURL, "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service).To(BeNil())
+
+			service, err = setupSupport("pagerduty", "qwerty123456", opsgenie.DefaultBaseURL, "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service).To(BeAssignableToTypeOf(&pagerduty.Schedule{}))
+
+			service, err = setupSupport("opsgenie", "", opsgenie.DefaultBaseURL, "qwerty123456", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service).To(BeAssignableToTypeOf(&opsgenie.Schedule{}))
+
+			service, err = setupSupport("ical", "", opsgenie.DefaultBaseURL, "", "rota.ics")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service).To(BeAssignableToTypeOf(&ical.Calendar{}))
+
+			_, err = setupSupport("opsgenie", "", opsgenie.DefaultBaseURL, "", "")
+			Expect(err).To(MatchError(ContainSubstring("API key is required")))
+
+			_, err = setupSupport("ical", "", opsgenie.DefaultBaseURL, "", "")
+			Expect(err).To(MatchError(ContainSubstring("calendar file or URL is required")))
+
+			_, err = setupSupport("rota", "", opsgenie.DefaultBaseURL, "", "")
+			Expect(err).To(MatchError(ContainSubstring(`unknown support source "rota"`)))
+		})
+
+		It("should fetchSupport() for the rota from an iCalendar file", func() {
+			start := time.Now().UTC().Add(-time.Hour).Format("20060102T150405Z")
+			end := time.Now().UTC().Add(time.Hour).Format("20060102T150405Z")
+			path := filepath.Join(GinkgoT().TempDir(), "rota.ics")
+			Expect(os.WriteFile(path, []byte("BEGIN:VCALENDAR\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:PaaS team rota - in hours: Alice\r\nDTSTART:"+start+"\r\nDTEND:"+end+"\r\nEND:VEVENT\r\n"+
+				"END:VCALENDAR\r\n"), 0644)).To(Succeed())
+
+			calendar, err := ical.New(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fetchSupport(context.Background(), board, calendar, rubbernecker.DefaultRota())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(board.Snapshot().Support["in-hours"].Member).To(Equal("Alice"))
+			Expect(board.Snapshot().Support["out-of-hours"].Member).To(Equal("-"))
+		})
+
+		It("should parsePivotalProjects() correctly", func() {
+			s, err := parsePivotalProjects([]string{"platform=123", "456,tenant=789"}, "qwerty123456")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(HaveLen(3))
+			Expect(s[0].Name).To(Equal("platform"))
+			Expect(s[1].Name).To(Equal("456"))
+			Expect(s[2].Name).To(Equal("tenant"))
+		})
+
+		It("should fail to parsePivotalProjects() with invalid project ID", func() {
+			_, err := parsePivotalProjects([]string{"platform=abc"}, "qwerty123456")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should parseGitHubProjects() correctly", func() {
+			s, err := parseGitHubProjects([]string{"platform=alphagov/5,alphagov/6"}, "qwerty123456")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(HaveLen(2))
+			Expect(s[0].Name).To(Equal("platform"))
+			Expect(s[1].Name).To(Equal("alphagov/6"))
+		})
+
+		It("should fail to parseGitHubProjects() without project number", func() {
+			_, err := parseGitHubProjects([]string{"platform=alphagov"}, "qwerty123456")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should parseJiraProjects() correctly", func() {
+			s, err := parseJiraProjects([]string{"platform=PAAS", "TENANT"}, "https://example.atlassian.net", "tester", "qwerty123456")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(HaveLen(2))
+			Expect(s[0].Name).To(Equal("platform"))
+			Expect(s[1].Name).To(Equal("TENANT"))
+		})
+
+		It("should fail to parseJiraProjects() without the URL of Jira", func() {
+			_, err := parseJiraProjects([]string{"PAAS"}, "", "tester", "qwerty123456")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should combineSources() of all the extensions", func() {
+			pivotalSources, err := parsePivotalProjects([]string{"123"}, "qwerty123456")
+			Expect(err).NotTo(HaveOccurred())
+			jiraSources, err := parseJiraProjects([]string{"PAAS"}, "https://example.atlassian.net", "tester", "qwerty123456")
+			Expect(err).NotTo(HaveOccurred())
+
+			s, err := combineSources(pivotalSources, rubbernecker.Sources{}, jiraSources)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(HaveLen(2))
+		})
+
+		It("should fail to combineSources() without any projects", func() {
+			s, err := parsePivotalProjects([]string{}, "qwerty123456")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = combineSources(s)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fetchStories() from multiple projects", func() {
+			apiURLOther := `https://www.pivotaltracker.com/services/v5/projects/654321/stories`
+			other, err := pivotal.New(654321, "qwerty123456")
+			Expect(err).NotTo(HaveOccurred())
+
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+			httpmock.RegisterResponder("GET", apiURLAccepted,
+				httpmock.NewStringResponder(200, `[]`))
+			httpmock.RegisterResponder("GET", apiURLOther,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, `[{"id": 2, "name": "Other Rubbernecker", "current_state": "started", "owner_ids":[1234]}]`),
+					httpmock.NewStringResponder(200, `[]`),
+				),
+			)
+
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+
+			err = fetchStories(context.Background(), board, rubbernecker.Sources{
+				&rubbernecker.Source{Name: "platform", Service: pt},
+				&rubbernecker.Source{Name: "tenant", Service: other},
+			}, window)
+			Expect(err).NotTo(HaveOccurred())
+
+			cards := board.Snapshot().Cards
+			Expect(cards).To(HaveLen(2))
+			Expect(cards[0].Project).To(Equal("platform"))
+			Expect(cards[1].Project).To(Equal("tenant"))
+			Expect(cards[1].Assignees[1234].Name).To(Equal("Tester"))
+
+			query, err := rubbernecker.ParseQuery("project:tenant")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards.Matching(query)).To(HaveLen(1))
+		})
+
+		It("should fetchSupport() for the schedules of the rota by their ID", func() {
+			resp := `{"oncalls":[
+				{"user":{"summary":"X"},"schedule":{"id":"PABC123","summary":"Platform - renamed primary"}},
+				{"user":{"summary":"Y"},"schedule":{"id":"PDEF456","summary":"Platform - secondary"}}
+			]}`
+			httpmock.RegisterResponder("GET", apiURLSupport, httpmock.NewStringResponder(200, resp))
+			rota := rubbernecker.Rota{
+				{Key: "primary", Label: "Primary", Schedule: "PABC123"},
+				{Key: "secondary", Label: "Secondary", Schedule: "Platform - secondary"},
+			}
+
+			err = fetchSupport(context.Background(), board, pd, rota)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(board.Snapshot().Support).To(Equal(rubbernecker.SupportRota{
+				"primary":   {Type: "Platform - renamed primary", ScheduleID: "PABC123", Member: "X", Shifts: []rubbernecker.Shift{{Member: "X"}}},
+				"secondary": {Type: "Platform - secondary", ScheduleID: "PDEF456", Member: "Y", Shifts: []rubbernecker.Shift{{Member: "Y"}}},
+			}))
+		})
+
+		It("should loadRota() which is the default one", func() {
+			rota, err := loadRota("rota.yml")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rota).To(Equal(rubbernecker.DefaultRota()))
+		})
+
+		It("should fail to loadRota() which is invalid", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "rota.yml")
+			Expect(os.WriteFile(path, []byte("- key: primary\n"), 0644)).To(Succeed())
+
+			_, err := loadRota(path)
+			Expect(err).To(MatchError(ContainSubstring(`"primary" has no schedule`)))
+
+			Expect(os.WriteFile(path, []byte("key: primary"), 0644)).To(Succeed())
+
+			_, err = loadRota(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid rota")))
+		})
+
+		It("should render the support of the rota with indexHandler()", func() {
+			s.config.Rota = rubbernecker.Rota{
+				{Key: "primary", Label: "Primary on call", Schedule: "PABC123"},
+				{Key: "secondary", Label: "Secondary on call", Schedule: "PDEF456"},
+			}
+			board.PublishSupport(rubbernecker.SupportRota{"primary": {Member: "Alice"}})
+
+			req, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "text/html")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchRegexp(`<strong>Primary on call</strong>:\s*Alice`))
+			Expect(rr.Body.String()).To(MatchRegexp(`<strong>Secondary on call</strong>:\s*-`))
+			Expect(rr.Body.String()).NotTo(ContainSubstring("Escalations"))
+		})
+
+		It("should render the next handover of the rota with indexHandler()", func() {
+			s.config.Rota = rubbernecker.Rota{{Key: "primary", Label: "Primary on call", Schedule: "PABC123"}}
+			start := time.Date(2018, 3, 8, 9, 30, 0, 0, time.UTC)
+			board.PublishSupport(rubbernecker.SupportRota{"primary": {
+				Member: "Alice",
+				Next:   &rubbernecker.Shift{Member: "Bob", Start: &start},
+			}})
+
+			req, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring("then Bob from Thu 09:30"))
+		})
+
+		It("should show the rota timeline with supportHandler()", func() {
+			s.config.Rota = rubbernecker.Rota{
+				{Key: "primary", Label: "Primary on call", Schedule: "PABC123"},
+				{Key: "secondary", Label: "Secondary on call", Schedule: "PDEF456"},
+			}
+			start := time.Date(2018, 3, 8, 9, 30, 0, 0, time.UTC)
+			end := start.Add(24 * time.Hour)
+			board.PublishSupport(rubbernecker.SupportRota{"primary": {
+				Member: "Alice",
+				Shifts: []rubbernecker.Shift{
+					{Member: "Alice", End: &start},
+					{Member: "Bob", Start: &start, End: &end},
+				},
+			}})
+
+			get := func(accept string) *httptest.ResponseRecorder {
+				req, err := http.NewRequest("GET", "/support", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Accept", accept)
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.supportHandler)
+				handler.ServeHTTP(rr, req)
+
+				return rr
+			}
+
+			rr := get("application/json")
+			Expect(rr.Code).To(Equal(http.StatusOK))
+
+			var resp struct {
+				Support rubbernecker.SupportRota `json:"support"`
+				Config  rubbernecker.Config      `json:"config"`
+			}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &resp)).To(Succeed())
+			Expect(resp.Support["primary"].Shifts).To(HaveLen(2))
+			Expect(resp.Support["primary"].Shifts[1].Start.Equal(start)).To(BeTrue())
+			Expect(board.Snapshot().Support["primary"].Shifts).To(BeEmpty())
+			Expect(resp.Config.Rota).To(Equal(s.config.Rota))
+
+			rr = get("text/html")
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchRegexp(`<td>Bob</td>\s*<td>Thu 8 Mar 09:30</td>\s*<td>Fri 9 Mar 09:30</td>`))
+			Expect(rr.Body.String()).To(MatchRegexp(`Secondary on call</h2>\s*<p>Nobody is on call.</p>`))
+		})
+
+		It("should loadWorkflow() which is the default one", func() {
+			workflow, err := loadWorkflow("workflow.yml")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workflow).To(Equal(rubbernecker.DefaultWorkflow()))
+		})
+
+		It("should fail to loadWorkflow() which is invalid", func() {
+			dir := GinkgoT().TempDir()
+
+			_, err := loadWorkflow(filepath.Join(dir, "missing.yml"))
+			Expect(err).To(HaveOccurred())
+
+			path := filepath.Join(dir, "workflow.yml")
+			Expect(os.WriteFile(path, []byte("- name: doing\n  states: [started]\n"), 0644)).To(Succeed())
+
+			_, err = loadWorkflow(path)
+			Expect(err).To(MatchError(ContainSubstring(`no "done" column`)))
+
+			Expect(os.WriteFile(path, []byte("name: doing"), 0644)).To(Succeed())
+
+			_, err = loadWorkflow(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid workflow")))
+		})
+
+		It("should loadFilters() which are the default ones", func() {
+			filters, err := loadFilters("filters.yml")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filters).To(HaveLen(10))
+			Expect(filters[0].DisplayText()).To(Equal("Decommission"))
+			Expect(filters[9].IsApplied([]string{"not-sticker:non-tech"})).To(BeTrue())
+		})
+
+		It("should fail to loadFilters() which are invalid", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "filters.yml")
+			Expect(os.WriteFile(path, []byte("- display: Unknown\n  query: colour:red\n"), 0644)).To(Succeed())
+
+			_, err := loadFilters(path)
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "colour"`)))
+
+			Expect(os.WriteFile(path, []byte("display: Blocked"), 0644)).To(Succeed())
+
+			_, err = loadFilters(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid filters")))
+		})
+
+		It("should render the filters in their groups with indexHandler()", func() {
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{}))
+			s.filters = rubbernecker.SavedFilters{
+				{Display: "Blocked", Query: "sticker:blocked"},
+				{Display: "Security", Query: "sticker:security OR title:cve", Group: "Incidents"},
+			}.Filters()
+
+			req, err := http.NewRequest("GET", "/?filter=sticker:blocked", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "text/html")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchRegexp(`class="filter filter--active" href="\?filter=sticker%3Ablocked">\s*Blocked`))
+			Expect(rr.Body.String()).To(ContainSubstring(`<span class="filters__group">Incidents:</span>`))
+			Expect(rr.Body.String()).To(ContainSubstring(`href="?filter=sticker%3Asecurity&#43;OR&#43;title%3Acve"`))
+		})
+
+		It("should render the columns of the workflow with indexHandler()", func() {
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{}))
+			board.PublishCards(rubbernecker.Cards{
+				&rubbernecker.Card{Title: "Testing Rubbernecker", Status: "in-qa"},
+				&rubbernecker.Card{Title: "Reviewing Rubbernecker", Status: "reviewing"},
+			}, rubbernecker.Cards{})
+			s.config.Workflow = rubbernecker.Workflow{
+				{Name: "in-qa", Title: "In QA", Limit: 3},
+				{Name: "done", Title: "Done", ShowCount: true},
+			}
+
+			req, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "text/html")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`In QA (1/3)`))
+			Expect(rr.Body.String()).To(ContainSubstring(`Testing Rubbernecker`))
+			Expect(rr.Body.String()).NotTo(ContainSubstring(`Reviewing Rubbernecker`))
+		})
+
+		It("should flag the limits breached with indexHandler()", func() {
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{}))
+			board.PublishCards(rubbernecker.Cards{
+				&rubbernecker.Card{Title: "Reviewing for long", Status: "reviewing", Elapsed: 5},
+				&rubbernecker.Card{Title: "Reviewing since today", Status: "reviewing", Elapsed: 0},
+			}, rubbernecker.Cards{})
+			s.config.Workflow = rubbernecker.Workflow{
+				{Name: "reviewing", Title: "Reviewing", Limit: 1},
+				{Name: "done", Title: "Done"},
+			}
+			s.overLimit = &rubbernecker.Sticker{Name: rubbernecker.OverLimitSticker, Title: "Over the limit"}
+
+			get := func(url, accept string) string {
+				req, err := http.NewRequest("GET", url, nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Accept", accept)
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.indexHandler)
+				handler.ServeHTTP(rr, req)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				return rr.Body.String()
+			}
+
+			body := get("/", "application/json")
+			Expect(body).To(ContainSubstring(`"over_limit":[{"column":"reviewing","count":2,"limit":1}]`))
+
+			body = get("/?filter=sticker:overlimit", "application/json")
+			Expect(body).To(ContainSubstring("Reviewing since today"))
+			Expect(body).NotTo(ContainSubstring("Reviewing for long"))
+			Expect(board.Snapshot().Cards[1].Stickers).To(BeEmpty())
+
+			body = get("/", "text/html")
+			Expect(body).To(ContainSubstring("board__column--over-limit"))
+		})
+
+		It("should loadNotificationRules() which are the default ones", func() {
+			rules, err := loadNotificationRules("notifications.yml", rubbernecker.DefaultWorkflow())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(Equal(notify.DefaultRules()))
+		})
+
+		It("should fail to loadNotificationRules() which are invalid", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "notifications.yml")
+			Expect(os.WriteFile(path, []byte("stuck:\n  column: in-qa\n  working_days: 2\n"), 0644)).To(Succeed())
+
+			_, err := loadNotificationRules(path, rubbernecker.DefaultWorkflow())
+			Expect(err).To(MatchError(ContainSubstring(`"in-qa" is not in the workflow`)))
+
+			Expect(os.WriteFile(path, []byte("rejected: [true"), 0644)).To(Succeed())
+
+			_, err = loadNotificationRules(path, rubbernecker.DefaultWorkflow())
+			Expect(err).To(MatchError(ContainSubstring("invalid notifications")))
+		})
+
+		It("should setupNotifier() logging the notifications without Slack", func() {
+			notifier, err := setupNotifier("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notifier).To(Equal(notify.Log{}))
+
+			notifier, err = setupNotifier("https://hooks.slack.com/services/test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notifier).To(BeAssignableToTypeOf(&notify.Slack{}))
+		})
+
+		It("should setupStorage() in memory by default", func() {
+			engine, err := setupStorage("")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(engine).To(BeAssignableToTypeOf(&memory.Engine{}))
+		})
+
+		It("should setupStorage() in the directory", func() {
+			engine, err := setupStorage(GinkgoT().TempDir())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(engine).To(BeAssignableToTypeOf(&storage.Engine{}))
+		})
+
+		It("should persistSnapshots() and restoreSnapshot() them on start", func() {
+			engine, err := setupStorage(GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+
+			updates, unsubscribe := board.Subscribe()
+			defer unsubscribe()
+			go persistSnapshots(updates, engine, time.Hour)
+
+			board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+			Eventually(func() error {
+				_, err := engine.Get(rubbernecker.LatestSnapshotKey)
+				return err
+			}).Should(Succeed())
+
+			restored := rubbernecker.NewBoard()
+			Expect(restoreSnapshot(restored, engine)).To(Succeed())
+			Expect(restored.Snapshot().Members).To(HaveKey(1234))
+			Expect(restored.Snapshot().ETag.Unix()).To(Equal(board.Snapshot().ETag.Unix()))
+		})
+
+		It("should restoreSnapshot() along with the changes to the cards", func() {
+			engine := memory.SetupEngine()
+			now := time.Now()
+
+			for i, status := range []string{"doing", "reviewing", "approving"} {
+				s := &rubbernecker.Snapshot{
+					ETag:  now.Add(time.Duration(i-2) * time.Hour),
+					Cards: rubbernecker.Cards{{ID: 1, Title: "Test", Status: status}},
+				}
+				Expect(rubbernecker.SaveSnapshot(engine, s, 24*time.Hour)).To(Succeed())
+			}
+
+			Expect(restoreSnapshot(board, engine)).To(Succeed())
+
+			changes := board.Changes(now.Add(-3 * time.Hour))
+			Expect(changes).To(HaveLen(2))
+			Expect(changes[0].To).To(Equal("reviewing"))
+			Expect(changes[1].To).To(Equal("approving"))
+		})
+
+		It("should fail to restoreSnapshot() when nothing has been persisted", func() {
+			err := restoreSnapshot(board, memory.SetupEngine())
+
+			Expect(err).To(HaveOccurred())
+			Expect(board.Snapshot().ETag.IsZero()).To(BeTrue())
+		})
+
 		It("should deal healthcheckHandler() correctly", func() {
 			req, err := http.NewRequest("GET", "/health-check", nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			rr := httptest.NewRecorder()
-			handler := http.HandlerFunc(healthcheckHandler)
+			handler := http.HandlerFunc(s.healthcheckHandler)
 			handler.ServeHTTP(rr, req)
 
 			Expect(rr.Code).To(Equal(http.StatusOK))
 			Expect(rr.Body.String()).To(ContainSubstring(`{"message":"OK"}`))
 		})
 
+		It("should expose the metrics with metricsHandler()", func() {
+			httpmock.RegisterResponder("GET", apiURLMembers,
+				httpmock.NewStringResponder(200, `[]`))
+
+			board.PublishCards(rubbernecker.Cards{&rubbernecker.Card{Status: "doing"}}, rubbernecker.Cards{})
+			_, err := s.newRefresher("members", time.Hour, func(ctx context.Context) error { return fetchUsers(ctx, board, sources) }).Refresh(context.Background())
+			Expect(err).To(HaveOccurred())
+
+			req, err := http.NewRequest("GET", "/metrics", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.metricsHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+			Expect(rr.Body.String()).To(ContainSubstring(`rubbernecker_cards{status="doing"} 1`))
+			Expect(rr.Body.String()).To(ContainSubstring(`rubbernecker_cards_limit{status="reviewing"} 4`))
+			Expect(rr.Body.String()).To(ContainSubstring(`rubbernecker_upstream_fetch_errors_total{upstream="members"} 1`))
+		})
+
+		It("should work out staleAfterInterval() correctly", func() {
+			Expect(staleAfterInterval(10*time.Minute, 20*time.Second)).To(Equal(10 * time.Minute))
+			Expect(staleAfterInterval(10*time.Minute, time.Hour)).To(Equal(2 * time.Hour))
+		})
+
+		It("should report the upstreams with healthcheckHandler()", func() {
+			s.upstreams.Register("stories", time.Hour)
+			s.upstreams.Record("stories", time.Second, time.Now(), fmt.Errorf("test: 500"))
+
+			req, err := http.NewRequest("GET", "/health-check", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.healthcheckHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`"message":"OK"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"name":"stories"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"consecutive_failures":1`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"last_error":"test: 500"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"stale":false`))
+		})
+
+		It("should fail the healthcheckHandler() when the data is stale", func() {
+			s.upstreams.Register("stories", time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+
+			req, err := http.NewRequest("GET", "/health-check", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.healthcheckHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rr.Body.String()).To(ContainSubstring(`"message":"Stale"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`data is stale since`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"stale":true`))
+		})
+
+		It("should fetchIncidents() and show them in the banner with indexHandler()", func() {
+			httpmock.RegisterResponder("GET", "https://api.pagerduty.com/incidents",
+				httpmock.NewStringResponder(200, `{"incidents":[
+					{"id":"PINC1","summary":"Disk is filling up","status":"triggered","urgency":"low"},
+					{"id":"PINC2","summary":"Router is down","status":"acknowledged","urgency":"high",
+						"html_url":"https://example.pagerduty.com/incidents/PINC2","service":{"summary":"Router"},
+						"assignments":[{"assignee":{"summary":"Alice"}},{"assignee":{"summary":"Bob"}}]}
+				]}`))
+
+			err = fetchIncidents(context.Background(), board, pagerduty.NewIncidents("qwerty123456", []string{"PSVC123"}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(board.Snapshot().Incidents).To(HaveLen(2))
+
+			req, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchRegexp(`(?s)incident--high.*Router is down</a> on Router\s*\(acknowledged, Alice, Bob\).*incident--low.*Disk is filling up\s*\(triggered\)`))
+
+			req.Header.Add("Accept", "application/json")
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var resp struct {
+				Incidents rubbernecker.Incidents `json:"incidents"`
+			}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &resp)).To(Succeed())
+			Expect(resp.Incidents[0].ID).To(Equal("PINC2"))
+			Expect(resp.Incidents[0].Assignees).To(Equal([]string{"Alice", "Bob"}))
+		})
+
+		It("should fail to fetchIncidents() due to faulty API", func() {
+			httpmock.RegisterResponder("GET", "https://api.pagerduty.com/incidents",
+				httpmock.NewStringResponder(500, ``))
+
+			err = fetchIncidents(context.Background(), board, pagerduty.NewIncidents("qwerty123456", nil))
+
+			Expect(err).To(HaveOccurred())
+			Expect(board.Snapshot().Incidents).To(BeNil())
+		})
+
+		It("should parsePagerDutyServices() correctly", func() {
+			Expect(parsePagerDutyServices([]string{"PSVC123, PSVC456", "", "PSVC789"})).To(Equal([]string{"PSVC123", "PSVC456", "PSVC789"}))
+		})
+
+		It("should show the stale banner with indexHandler()", func() {
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{}))
+			s.upstreams.Register("stories", time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+
+			req, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "text/html")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.indexHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`Data is stale since`))
+		})
+
+		It("should stream the data becoming stale with eventsHandler()", func() {
+			s.keepAlive = 10 * time.Millisecond
+			s.upstreams.Register("stories", 50*time.Millisecond)
+			srv := httptest.NewServer(http.HandlerFunc(s.eventsHandler))
+			defer srv.Close()
+
+			client := &http.Client{Transport: &http.Transport{}}
+			res, err := client.Get(srv.URL)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+
+			ids := make(chan string)
+			go func() {
+				defer GinkgoRecover()
+
+				scanner := bufio.NewScanner(res.Body)
+				scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+				for scanner.Scan() {
+					if strings.HasPrefix(scanner.Text(), "id: ") {
+						ids <- scanner.Text()
+					}
+				}
+			}()
+
+			Eventually(ids).Should(Receive(Not(ContainSubstring("stale"))))
+			Eventually(ids).Should(Receive(ContainSubstring("-stale-")))
+		})
+
+		Context("receiving the pivotal webhooks", func() {
+			var (
+				apiURLStory = `https://www.pivotaltracker.com/services/v5/projects/123456/stories/561?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate`
+				activity    = `{"kind": "story_update_activity", "project": {"id": 123456}, "changes": [{"kind": "story", "change_type": "update", "id": 561}]}`
+			)
+
+			post := func(url, body string) *httptest.ResponseRecorder {
+				req, err := http.NewRequest("POST", url, strings.NewReader(body))
+				Expect(err).NotTo(HaveOccurred())
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.pivotalWebhookHandler)
+				handler.ServeHTTP(rr, req)
+
+				return rr
+			}
+
+			BeforeEach(func() {
+				s.sources = sources
+				s.webhookToken = "secret"
+
+				board.PublishMembers(rubbernecker.Members{1234: &rubbernecker.Member{ID: 1234, Name: "Tester"}})
+				board.PublishCards(rubbernecker.Cards{
+					&rubbernecker.Card{ID: 561, Project: "123456", Status: "doing", Title: "Test Rubbernecker"},
+					&rubbernecker.Card{ID: 562, Project: "123456", Status: "doing", Title: "Other"},
+				}, rubbernecker.Cards{})
+			})
+
+			It("should reject the webhooks with an invalid token", func() {
+				Expect(post("/webhooks/pivotal", activity).Code).To(Equal(http.StatusUnauthorized))
+				Expect(post("/webhooks/pivotal?token=wrong", activity).Code).To(Equal(http.StatusUnauthorized))
+
+				s.webhookToken = ""
+				Expect(post("/webhooks/pivotal?token=", activity).Code).To(Equal(http.StatusUnauthorized))
+			})
+
+			It("should reject the webhooks which are not posted", func() {
+				req, err := http.NewRequest("GET", "/webhooks/pivotal?token=secret", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.pivotalWebhookHandler)
+				handler.ServeHTTP(rr, req)
+
+				Expect(rr.Code).To(Equal(http.StatusMethodNotAllowed))
+			})
+
+			It("should reject the invalid webhooks", func() {
+				rr := post("/webhooks/pivotal?token=secret", `not json`)
+				Expect(rr.Code).To(Equal(http.StatusBadRequest))
+				Expect(rr.Body.String()).To(ContainSubstring("invalid activity"))
+
+				rr = post("/webhooks/pivotal?token=secret", `{"kind": "story_update_activity", "project": {"id": 999}}`)
+				Expect(rr.Code).To(Equal(http.StatusBadRequest))
+				Expect(rr.Body.String()).To(ContainSubstring("unknown pivotal project 999"))
+			})
+
+			It("should ignore the activity not affecting the stories", func() {
+				rr := post("/webhooks/pivotal?token=secret", `{"kind": "comment_create_activity", "project": {"id": 123456}}`)
+
+				Expect(rr.Code).To(Equal(http.StatusAccepted))
+				Expect(rr.Body.String()).To(ContainSubstring(`"message":"Ignored"`))
+			})
+
+			It("should apply the updated story to the board", func() {
+				httpmock.RegisterResponder("GET", apiURLStory,
+					httpmock.NewStringResponder(200, `{"id": 561, "transitions": [], "name": "Test Rubbernecker", "current_state": "finished", "owner_ids": [1234], "labels": []}`))
+
+				rr := post("/webhooks/pivotal?token=secret", activity)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				cards := board.Snapshot().Cards
+				Expect(cards).To(HaveLen(2))
+				Expect(cards[0].Status).To(Equal("reviewing"))
+				Expect(cards[0].Project).To(Equal("123456"))
+				Expect(cards[0].Assignees[1234].Name).To(Equal("Tester"))
+			})
+
+			It("should take the deleted stories and the ones in the icebox off the board", func() {
+				httpmock.RegisterResponder("GET", apiURLStory,
+					httpmock.NewStringResponder(200, `{"id": 561, "transitions": [], "name": "Test Rubbernecker", "current_state": "unscheduled"}`))
+
+				rr := post("/webhooks/pivotal?token=secret", `{"kind": "story_delete_activity", "project": {"id": 123456}, "changes": [{"kind": "story", "change_type": "delete", "id": 562}, {"kind": "story", "change_type": "update", "id": 561}]}`)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(board.Snapshot().Cards).To(BeEmpty())
+			})
+
+			It("should fail to apply the story which could not be fetched", func() {
+				httpmock.RegisterResponder("GET", apiURLStory,
+					httpmock.NewStringResponder(500, ``))
+
+				rr := post("/webhooks/pivotal?token=secret", activity)
+
+				Expect(rr.Code).To(Equal(http.StatusBadGateway))
+				Expect(board.Snapshot().Cards[0].Status).To(Equal("doing"))
+			})
+		})
+
+		It("should stream board updates with eventsHandler()", func() {
+			s.keepAlive = time.Hour
+			srv := httptest.NewServer(http.HandlerFunc(s.eventsHandler))
+			defer srv.Close()
+
+			client := &http.Client{Transport: &http.Transport{}}
+			res, err := client.Get(srv.URL)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+
+			Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+			events := make(chan string)
+			go func() {
+				defer GinkgoRecover()
+
+				scanner := bufio.NewScanner(res.Body)
+				for scanner.Scan() {
+					if scanner.Text() != "" {
+						events <- scanner.Text()
+					}
+				}
+			}()
+
+			first := s.eventID(board.Snapshot())
+			Eventually(events).Should(Receive(Equal("id: " + first)))
+			Eventually(events).Should(Receive(Equal("event: board")))
+			Eventually(events).Should(Receive(Equal("data: " + first)))
+
+			board.PublishCards(rubbernecker.Cards{&rubbernecker.Card{Title: "Test Rubbernecker"}}, rubbernecker.Cards{})
+
+			second := s.eventID(board.Snapshot())
+			Expect(second).NotTo(Equal(first))
+			Eventually(events).Should(Receive(Equal("id: " + second)))
+			Eventually(events).Should(Receive(Equal("event: board")))
+			Eventually(events).Should(Receive(Equal("data: " + second)))
+		})
+
+		It("should list the changes with changesHandler()", func() {
+			board.PublishCards(rubbernecker.Cards{&rubbernecker.Card{ID: 1, Title: "Test Rubbernecker", Status: "doing"}}, rubbernecker.Cards{})
+			since := board.Snapshot().ETag.Add(-time.Second)
+			board.PublishCards(rubbernecker.Cards{&rubbernecker.Card{ID: 1, Title: "Test Rubbernecker", Status: "reviewing"}}, rubbernecker.Cards{})
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("/changes?since=%d", since.Unix()), nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.changesHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`"type":"moved"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"from":"doing","to":"reviewing"`))
+		})
+
+		It("should fail to list the changes with changesHandler() due to invalid since param", func() {
+			req, err := http.NewRequest("GET", "/changes?since=yesterday", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.changesHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(ContainSubstring(`invalid since param`))
+		})
+
+		It("should summarise the done cards with flowHandler()", func() {
+			lead, cycle := 48.0, 24.0
+			board.PublishCards(rubbernecker.Cards{}, rubbernecker.Cards{
+				&rubbernecker.Card{Title: "Test Rubbernecker", Flow: &rubbernecker.Flow{
+					InState:   map[string]float64{"doing": 20},
+					LeadTime:  &lead,
+					CycleTime: &cycle,
+				}},
+				&rubbernecker.Card{Title: "Filtered out", Flow: &rubbernecker.Flow{}},
+			})
+
+			req, err := http.NewRequest("GET", "/metrics/flow?filter=title:rubber", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.flowHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`"count":1`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"lead_time_hours":{"p50":48,"p75":48,"p85":48,"p95":48}`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"in_state_hours":{"doing":{"p50":20`))
+		})
+
+		It("should fail to summarise the done cards with flowHandler() due to invalid filter", func() {
+			req, err := http.NewRequest("GET", "/metrics/flow?filter=colour:red", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.flowHandler)
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(ContainSubstring(`unknown field \"colour\"`))
+		})
+
+		It("should filter the cards with the query language in indexHandler()", func() {
+			board.PublishCards(rubbernecker.Cards{
+				&rubbernecker.Card{Title: "Fix the build", Status: "doing"},
+				&rubbernecker.Card{Title: "Upgrade the database", Status: "reviewing"},
+				&rubbernecker.Card{Title: "Rotate the certificates", Status: "next"},
+			}, rubbernecker.Cards{})
+
+			get := func(filter string) string {
+				req, err := http.NewRequest("GET", "/?filter="+url.QueryEscape(filter), nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Accept", "application/json")
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.indexHandler)
+				handler.ServeHTTP(rr, req)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				return rr.Body.String()
+			}
+
+			body := get("status:doing OR (title:database -status:next)")
+			Expect(body).To(ContainSubstring("Fix the build"))
+			Expect(body).To(ContainSubstring("Upgrade the database"))
+			Expect(body).NotTo(ContainSubstring("Rotate the certificates"))
+
+			body = get("status:doing OR")
+			Expect(body).To(ContainSubstring(`"error":"rubbernecker: invalid filter`))
+			Expect(body).To(ContainSubstring("Rotate the certificates"))
+		})
+
+		It("should summarise the board with digestHandler()", func() {
+			alice := &rubbernecker.Member{ID: 1, Name: "Alice"}
+			board.PublishMembers(rubbernecker.Members{1: alice, 2: {ID: 2, Name: "Bob"}})
+			board.PublishSupport(rubbernecker.SupportRota{"in-hours": {Type: "in-hours", Member: "Carol"}})
+			board.PublishCards(rubbernecker.Cards{
+				&rubbernecker.Card{ID: 1, Title: "Fix the build", Status: "doing", Assignees: rubbernecker.Members{1: alice}},
+			}, rubbernecker.Cards{})
+			board.PublishCards(rubbernecker.Cards{
+				&rubbernecker.Card{ID: 1, Title: "Fix the build", Status: "reviewing", Elapsed: 4, Assignees: rubbernecker.Members{1: alice}},
+			}, rubbernecker.Cards{})
+
+			get := func(url string) *httptest.ResponseRecorder {
+				req, err := http.NewRequest("GET", url, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.digestHandler)
+				handler.ServeHTTP(rr, req)
+
+				return rr
+			}
+
+			rr := get("/digest")
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring("text/markdown"))
+			Expect(rr.Body.String()).To(ContainSubstring("- Fix the build: Doing to Reviewing\n"))
+			Expect(rr.Body.String()).To(ContainSubstring("- Fix the build: Reviewing for 4 working days\n"))
+			Expect(rr.Body.String()).To(ContainSubstring("## Free to pick up new work\n\n- Bob\n"))
+			Expect(rr.Body.String()).To(ContainSubstring("- In hours: Carol\n"))
+
+			rr = get("/digest?format=text&stuck=5")
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring("text/plain"))
+			Expect(rr.Body.String()).To(ContainSubstring("In play for more than 5 working days:\n  * Nothing is stuck\n"))
+
+			rr = get("/digest?stuck=soon")
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(ContainSubstring("invalid stuck param"))
+
+			rr = get("/digest?format=pdf")
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(ContainSubstring("invalid format param"))
+		})
+
+		It("should leave out the members on support or on leave from the free ones", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "leave.ics")
+			start := time.Now().UTC().Add(-time.Hour).Format("20060102T150405Z")
+			end := time.Now().UTC().Add(time.Hour).Format("20060102T150405Z")
+			Expect(os.WriteFile(path, []byte("BEGIN:VCALENDAR\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:dave@example.com: Annual leave\r\nDTSTART:"+start+"\r\nDTEND:"+end+"\r\nEND:VEVENT\r\n"+
+				"END:VCALENDAR\r\n"), 0644)).To(Succeed())
+
+			calendar, err := ical.New(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchLeave(context.Background(), board, calendar)).To(Succeed())
+			Expect(board.Snapshot().Leave).To(HaveLen(1))
+
+			board.PublishMembers(rubbernecker.Members{
+				1: {ID: 1, Name: "Alice"},
+				2: {ID: 2, Name: "Bob"},
+				3: {ID: 3, Name: "Carol", Email: "carol@example.com"},
+				4: {ID: 4, Name: "Dave", Email: "dave@example.com"},
+			})
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{
+				"PaaS team rota - in hours":     {Type: "PaaS team rota - in hours", Member: "Carol"},
+				"PaaS team rota - out of hours": {Type: "PaaS team rota - out of hours", Member: "Bob"},
+			}))
+
+			req, err := http.NewRequest("GET", "/digest", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.digestHandler).ServeHTTP(rr, req)
+
+			Expect(rr.Body.String()).To(ContainSubstring("## Free to pick up new work\n\n- Alice\n- Bob\n\n"))
+
+			req, err = http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "application/json")
+
+			rr = httptest.NewRecorder()
+			http.HandlerFunc(s.indexHandler).ServeHTTP(rr, req)
+
+			var resp struct {
+				Availability rubbernecker.Availabilities `json:"availability"`
+			}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &resp)).To(Succeed())
+
+			statuses := []rubbernecker.AvailabilityStatus{}
+			for _, a := range resp.Availability {
+				statuses = append(statuses, a.Status)
+			}
+			Expect(statuses).To(Equal([]rubbernecker.AvailabilityStatus{
+				rubbernecker.AvailabilityFree,
+				rubbernecker.AvailabilityFree,
+				rubbernecker.AvailabilitySupport,
+				rubbernecker.AvailabilityLeave,
+			}))
+			Expect(resp.Availability[2].Support).To(Equal([]string{"In hours"}))
+			Expect(resp.Availability[3].Leave.Reason).To(Equal("Annual leave"))
+		})
+
+		It("should work out the same availability on every page out of the cards in play", func() {
+			board.PublishMembers(rubbernecker.Members{
+				1: {ID: 1, Name: "Alice"},
+				2: {ID: 2, Name: "Bob"},
+			})
+			accepted := time.Now()
+			board.PublishCards(rubbernecker.Cards{
+				{ID: 1, Title: "Upgrade the database", Status: "doing", Assignees: rubbernecker.Members{1: {ID: 1, Name: "Alice"}}},
+			}, rubbernecker.Cards{
+				{ID: 2, Title: "Fix the build", Status: "done", AcceptedAt: &accepted, Assignees: rubbernecker.Members{2: {ID: 2, Name: "Bob"}}},
+			})
+
+			req, err := http.NewRequest("GET", "/digest", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(s.digestHandler).ServeHTTP(rr, req)
+
+			Expect(rr.Body.String()).To(ContainSubstring("## Free to pick up new work\n\n- Bob\n\n"))
+
+			req, err = http.NewRequest("GET", "/?filter=title:build", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add("Accept", "application/json")
+
+			rr = httptest.NewRecorder()
+			http.HandlerFunc(s.indexHandler).ServeHTTP(rr, req)
+
+			var resp struct {
+				Availability rubbernecker.Availabilities `json:"availability"`
+			}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &resp)).To(Succeed())
+
+			Expect(resp.Availability).To(HaveLen(2))
+			Expect(resp.Availability[0].Member.Name).To(Equal("Alice"))
+			Expect(resp.Availability[0].Status).To(Equal(rubbernecker.AvailabilityWorking))
+			Expect(resp.Availability[1].Member.Name).To(Equal("Bob"))
+			Expect(resp.Availability[1].Status).To(Equal(rubbernecker.AvailabilityFree))
+		})
+
+		It("should fail to fetchLeave() from a missing calendar", func() {
+			calendar, err := ical.New(filepath.Join(GinkgoT().TempDir(), "missing.ics"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fetchLeave(context.Background(), board, calendar)).NotTo(Succeed())
+			Expect(board.Snapshot().Leave).To(BeNil())
+		})
+
+		It("should limit the done cards with the done query param in indexHandler()", func() {
+			recently := time.Now()
+			earlier := recently.AddDate(0, 0, -14)
+			s.doneWindow = window
+			board.PublishCards(rubbernecker.Cards{}, rubbernecker.Cards{
+				&rubbernecker.Card{Title: "Recently accepted", AcceptedAt: &recently},
+				&rubbernecker.Card{Title: "Earlier accepted", AcceptedAt: &earlier},
+			})
+
+			get := func(url string) string {
+				req, err := http.NewRequest("GET", url, nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Accept", "application/json")
+
+				rr := httptest.NewRecorder()
+				handler := http.HandlerFunc(s.indexHandler)
+				handler.ServeHTTP(rr, req)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				return rr.Body.String()
+			}
+
+			body := get("/")
+			Expect(body).To(ContainSubstring("Recently accepted"))
+			Expect(body).NotTo(ContainSubstring("Earlier accepted"))
+
+			body = get("/?done=days:30")
+			Expect(body).To(ContainSubstring("Recently accepted"))
+			Expect(body).To(ContainSubstring("Earlier accepted"))
+
+			body = get("/?done=iteration")
+			Expect(body).NotTo(ContainSubstring("Earlier accepted"))
+			Expect(body).To(ContainSubstring("iteration is unknown"))
+
+			body = get("/?done=fortnight")
+			Expect(body).NotTo(ContainSubstring("Earlier accepted"))
+			Expect(body).To(ContainSubstring("invalid done window"))
+
+			s.doneHistory, _ = rubbernecker.ParseDoneWindow("days:7")
+
+			body = get("/?done=days:7")
+			Expect(body).NotTo(ContainSubstring("goes beyond the done history"))
+
+			body = get("/?done=days:30")
+			Expect(body).To(ContainSubstring("Recently accepted"))
+			Expect(body).NotTo(ContainSubstring("Earlier accepted"))
+			Expect(body).To(ContainSubstring("done window days:30 goes beyond the done history days:7"))
+		})
+
 		It("should deal indexHandler() correctly expecting Not Modified", func() {
 			req, err := http.NewRequest("GET", "/", nil)
 			Expect(err).NotTo(HaveOccurred())
 			req.Header.Add("Accept", "application/json")
-			req.Header.Add("If-None-Match", strconv.FormatInt(etag.Unix(), 10))
+			req.Header.Add("If-None-Match", strconv.FormatInt(board.Snapshot().ETag.Unix(), 10))
 
 			rr := httptest.NewRecorder()
-			handler := http.HandlerFunc(indexHandler)
+			handler := http.HandlerFunc(s.indexHandler)
 			handler.ServeHTTP(rr, req)
 
 			Expect(rr.Code).To(Equal(http.StatusNotModified))
@@ -274,12 +1504,14 @@ var _ = Describe("Main", func() {
 		})
 
 		It("should deal indexHandler() correctly expecting JSON", func() {
+			board.PublishCards(rubbernecker.Cards{&rubbernecker.Card{Title: "Test Rubbernecker"}}, rubbernecker.Cards{})
+
 			req, err := http.NewRequest("GET", "/", nil)
 			Expect(err).NotTo(HaveOccurred())
 			req.Header.Add("Accept", "application/json")
 
 			rr := httptest.NewRecorder()
-			handler := http.HandlerFunc(indexHandler)
+			handler := http.HandlerFunc(s.indexHandler)
 			handler.ServeHTTP(rr, req)
 
 			Expect(rr.Code).To(Equal(http.StatusOK))
@@ -288,12 +1520,14 @@ var _ = Describe("Main", func() {
 		})
 
 		It("should deal indexHandler() correctly expecting HTML", func() {
+			board.PublishSupport(s.config.Rota.Map(rubbernecker.SupportRota{}))
+
 			req, err := http.NewRequest("GET", "/", nil)
 			Expect(err).NotTo(HaveOccurred())
 			req.Header.Add("Accept", "text/html")
 
 			rr := httptest.NewRecorder()
-			handler := http.HandlerFunc(indexHandler)
+			handler := http.HandlerFunc(s.indexHandler)
 			handler.ServeHTTP(rr, req)
 
 			Expect(rr.Code).To(Equal(http.StatusOK))
@@ -0,0 +1,241 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+const (
+	apiURL = "https://api.github.com/graphql"
+
+	pageSize = 100
+)
+
+// The queries go through repositoryOwner, so that the projects owned by both
+// organisations and users can be tracked.
+const itemsQuery = `query($owner: String!, $number: Int!, $first: Int!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        items(first: $first, after: $cursor) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            databaseId
+            type
+            status: fieldValueByName(name: "Status") {
+              ... on ProjectV2ItemFieldSingleSelectValue { name updatedAt }
+            }
+            estimate: fieldValueByName(name: "Estimate") {
+              ... on ProjectV2ItemFieldNumberValue { number }
+            }
+            content {
+              ... on DraftIssue { title createdAt assignees(first: 10) { nodes { databaseId login name email } } }
+              ... on Issue { title url createdAt labels(first: 20) { nodes { name } } assignees(first: 10) { nodes { databaseId login name email } } }
+              ... on PullRequest { title url createdAt labels(first: 20) { nodes { name } } assignees(first: 10) { nodes { databaseId login name email } } }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const membersQuery = `query($owner: String!, $first: Int!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    __typename
+    ... on Organization {
+      membersWithRole(first: $first, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes { databaseId login name email }
+      }
+    }
+    ... on User { databaseId login name email }
+  }
+}`
+
+// DefaultStatusMapping is the set of project "Status" field values GitHub
+// creates for new projects, along with the few extra columns the rubbernecker
+// wall has.
+var DefaultStatusMapping = map[string]rubbernecker.Status{
+	"todo":        rubbernecker.StatusScheduled,
+	"backlog":     rubbernecker.StatusScheduled,
+	"ready":       rubbernecker.StatusScheduled,
+	"in progress": rubbernecker.StatusDoing,
+	"in review":   rubbernecker.StatusReviewal,
+	"approval":    rubbernecker.StatusApproval,
+	"rejected":    rubbernecker.StatusRejected,
+	"done":        rubbernecker.StatusDone,
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type itemsResponse struct {
+	Data struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				Items struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Nodes    []*item  `json:"nodes"`
+				} `json:"items"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// ownerUser is the type name of the repository owners which are users rather
+// than organisations.
+const ownerUser = "User"
+
+type membersResponse struct {
+	Data struct {
+		RepositoryOwner *struct {
+			user
+			Typename        string `json:"__typename"`
+			MembersWithRole *struct {
+				PageInfo pageInfo `json:"pageInfo"`
+				Nodes    []*user  `json:"nodes"`
+			} `json:"membersWithRole"`
+		} `json:"repositoryOwner"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+type item struct {
+	DatabaseID int    `json:"databaseId"`
+	Type       string `json:"type"`
+	Status     *struct {
+		Name      string     `json:"name"`
+		UpdatedAt *time.Time `json:"updatedAt"`
+	} `json:"status"`
+	Estimate *struct {
+		Number *float64 `json:"number"`
+	} `json:"estimate"`
+	Content *struct {
+		Title     string     `json:"title"`
+		URL       string     `json:"url"`
+		CreatedAt *time.Time `json:"createdAt"`
+		Labels    struct {
+			Nodes []struct {
+				Name string `json:"name"`
+			} `json:"nodes"`
+		} `json:"labels"`
+		Assignees struct {
+			Nodes []*user `json:"nodes"`
+		} `json:"assignees"`
+	} `json:"content"`
+}
+
+type user struct {
+	DatabaseID int    `json:"databaseId"`
+	Login      string `json:"login"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+}
+
+func (u *user) displayName() string {
+	if u.Name != "" {
+		return u.Name
+	}
+
+	return u.Login
+}
+
+func (p *Project) query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return refresher.NewResponseError(resp, fmt.Errorf("github extension: unexpected response code %d", resp.StatusCode))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func joinErrors(errors []graphQLError) error {
+	messages := []string{}
+	for _, e := range errors {
+		messages = append(messages, e.Message)
+	}
+
+	return fmt.Errorf("github extension: %s", strings.Join(messages, "; "))
+}
+
+func calculateWorkingDays(since, until time.Time) int {
+	days := 0
+
+	for {
+		if since.After(until) {
+			break
+		}
+
+		if since.Weekday() != time.Saturday && since.Weekday() != time.Sunday {
+			days++
+		}
+
+		since = since.Add(24 * time.Hour)
+	}
+
+	return days
+}
+
+// composeStatuses works out which rubbernecker statuses should be returned for
+// a FetchCards call. Similar to the PivotalTracker extension, StatusAll means
+// everything that is not done yet.
+func composeStatuses(status rubbernecker.Status) []rubbernecker.Status {
+	if status != rubbernecker.StatusAll {
+		return []rubbernecker.Status{status}
+	}
+
+	return []rubbernecker.Status{
+		rubbernecker.StatusScheduled,
+		rubbernecker.StatusDoing,
+		rubbernecker.StatusReviewal,
+		rubbernecker.StatusApproval,
+		rubbernecker.StatusRejected,
+	}
+}
+
+func (p *Project) convertStatus(name string) rubbernecker.Status {
+	if status, ok := p.statuses[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return status
+	}
+
+	return rubbernecker.StatusAll
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("GitHub internal functionality", func() {
+	It("should calculateWorkingDays() over weekend correctly", func() {
+		days := calculateWorkingDays(time.Date(2017, 10, 27, 12, 0, 0, 0, time.Local), time.Date(2017, 11, 1, 12, 0, 0, 0, time.Local))
+
+		Expect(days).To(Equal(4))
+	})
+
+	It("should composeStatuses() correctly", func() {
+		Expect(composeStatuses(rubbernecker.StatusDone)).To(Equal([]rubbernecker.Status{rubbernecker.StatusDone}))
+		Expect(composeStatuses(rubbernecker.StatusAll)).NotTo(ContainElement(rubbernecker.StatusDone))
+		Expect(composeStatuses(rubbernecker.StatusAll)).To(HaveLen(5))
+	})
+
+	It("should convertStatus() correctly", func() {
+		p, err := New("alphagov", 1, "test")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.convertStatus("Todo")).To(Equal(rubbernecker.StatusScheduled))
+		Expect(p.convertStatus(" in progress ")).To(Equal(rubbernecker.StatusDoing))
+		Expect(p.convertStatus("In Review")).To(Equal(rubbernecker.StatusReviewal))
+		Expect(p.convertStatus("Done")).To(Equal(rubbernecker.StatusDone))
+		Expect(p.convertStatus("testing")).To(Equal(rubbernecker.StatusAll))
+	})
+
+	It("should convertStatus() using a custom mapping", func() {
+		p, err := New("alphagov", 1, "test")
+		Expect(err).NotTo(HaveOccurred())
+
+		p.MapStatuses(map[string]rubbernecker.Status{
+			"Being Built": rubbernecker.StatusDoing,
+		})
+
+		Expect(p.convertStatus("being built")).To(Equal(rubbernecker.StatusDoing))
+		Expect(p.convertStatus("In Progress")).To(Equal(rubbernecker.StatusAll))
+	})
+})
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// FetchMembers will contact the GitHub API to get the list of the members of
+// the organisation owning the project. A project owned by a user has that user
+// as its only member.
+func (p *Project) FetchMembers(ctx context.Context) error {
+	members := []*user{}
+	variables := map[string]interface{}{
+		"owner":  p.owner,
+		"first":  pageSize,
+		"cursor": nil,
+	}
+
+	for {
+		var resp membersResponse
+
+		err := p.query(ctx, membersQuery, variables, &resp)
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Errors) > 0 {
+			return joinErrors(resp.Errors)
+		}
+
+		owner := resp.Data.RepositoryOwner
+		if owner == nil {
+			return fmt.Errorf("github extension: owner %s not found", p.owner)
+		}
+
+		if owner.Typename == ownerUser {
+			members = append(members, &owner.user)
+			break
+		}
+
+		if owner.MembersWithRole == nil {
+			return fmt.Errorf("github extension: members of %s not found", p.owner)
+		}
+
+		page := owner.MembersWithRole
+		members = append(members, page.Nodes...)
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = page.PageInfo.EndCursor
+	}
+
+	p.members = members
+
+	return nil
+}
+
+// FlattenMembers will convert the GitHub organisation members, or the user
+// owning the project, into rubbernecker users.
+func (p *Project) FlattenMembers() (rubbernecker.Members, error) {
+	if len(p.members) == 0 {
+		return nil, fmt.Errorf("github extension: no members to be flattened")
+	}
+
+	members := rubbernecker.Members{}
+
+	for _, m := range p.members {
+		members[m.DatabaseID] = &rubbernecker.Member{
+			ID:    m.DatabaseID,
+			Name:  m.displayName(),
+			Email: m.Email,
+		}
+	}
+
+	return members, nil
+}
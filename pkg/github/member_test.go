@@ -0,0 +1,107 @@
+package github_test
+
+import (
+	"context"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/github"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("GitHub Members", func() {
+	Context("Project setup", func() {
+		var (
+			p rubbernecker.MemberService
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			p, err = github.New("alphagov", 1, "test")
+			httpmock.Activate()
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchMembers() from an API", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(404, ``))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FetchMembers() when GraphQL returns errors", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("errors.json")))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FlattenMembers() due to faulty API", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, `{"data":{"repositoryOwner":{"__typename":"Organization","membersWithRole":{"nodes":[]}}}}`))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+
+			members, err := p.FlattenMembers()
+
+			Expect(err).To(HaveOccurred())
+			Expect(members).To(BeNil())
+		})
+
+		It("should FlattenMembers() correctly", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("members.json")))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+
+			members, err := p.FlattenMembers()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(2))
+			Expect(members[1234].Name).To(Equal("Test Er"))
+			Expect(members[1234].Email).To(Equal("tester@example.com"))
+			Expect(members[4321].Name).To(Equal("octocat"))
+		})
+
+		It("should FlattenMembers() of a project owned by a user", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, `{"data":{"repositoryOwner":{"__typename":"User","databaseId":4321,"login":"octocat","name":"","email":""}}}`))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+
+			members, err := p.FlattenMembers()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(1))
+			Expect(members[4321].Name).To(Equal("octocat"))
+		})
+
+		It("should fail to FetchMembers() of an unknown owner", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, `{"data":{"repositoryOwner":null}}`))
+
+			err := p.FetchMembers(context.Background())
+
+			Expect(err).To(MatchError(ContainSubstring("owner alphagov not found")))
+		})
+	})
+})
@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// Project will be responsible for acting as the project items resource
+// returned by the GitHub GraphQL API.
+type Project struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	owner    string
+	number   int
+	statuses map[string]rubbernecker.Status
+	items    []*item
+	stickers rubbernecker.Stickers
+	members  []*user
+}
+
+// New will compose a Project struct ready to use by the rubbernecker. The
+// owner is the login of the organisation or the user owning the project and number is the
+// project number visible in its URL.
+func New(owner string, number int, token string) (*Project, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("github extension: project owner is required")
+	}
+
+	return &Project{
+		client:   http.DefaultClient,
+		endpoint: apiURL,
+		token:    token,
+		owner:    owner,
+		number:   number,
+		statuses: DefaultStatusMapping,
+		stickers: rubbernecker.Stickers{},
+	}, nil
+}
+
+// MapStatuses will replace the default mapping of the project "Status" field
+// values onto rubbernecker statuses. Keys are matched case insensitively.
+func (p *Project) MapStatuses(mapping map[string]rubbernecker.Status) {
+	p.statuses = map[string]rubbernecker.Status{}
+
+	for name, status := range mapping {
+		p.statuses[strings.ToLower(strings.TrimSpace(name))] = status
+	}
+}
+
+// AcceptStickers will make a note of enabled stickers in the application and
+// attempt to assign them to each item.
+func (p *Project) AcceptStickers(stickers rubbernecker.Stickers) {
+	p.stickers = stickers
+}
+
+// FetchCards will fetch the project items from GitHub. The GraphQL API does
+// not allow filtering items by a field value, therefore all of them are
+// requested and only the ones matching the status are kept. The
+// "accepted_after" parameter (milliseconds since epoch) is understood, to keep
+// the behaviour in line with the PivotalTracker extension.
+func (p *Project) FetchCards(ctx context.Context, status rubbernecker.Status, params map[string]string) error {
+	var acceptedAfter *time.Time
+
+	if value, ok := params["accepted_after"]; ok {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("github extension: invalid accepted_after: %s", err)
+		}
+
+		t := time.Unix(0, ms*int64(time.Millisecond))
+		acceptedAfter = &t
+	}
+
+	wanted := map[rubbernecker.Status]bool{}
+	for _, s := range composeStatuses(status) {
+		wanted[s] = true
+	}
+
+	items := []*item{}
+	variables := map[string]interface{}{
+		"owner":  p.owner,
+		"number": p.number,
+		"first":  pageSize,
+		"cursor": nil,
+	}
+
+	for {
+		var resp itemsResponse
+
+		err := p.query(ctx, itemsQuery, variables, &resp)
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Errors) > 0 {
+			return joinErrors(resp.Errors)
+		}
+
+		if resp.Data.RepositoryOwner == nil || resp.Data.RepositoryOwner.ProjectV2 == nil {
+			return fmt.Errorf("github extension: project %s/%d not found", p.owner, p.number)
+		}
+
+		page := resp.Data.RepositoryOwner.ProjectV2.Items
+
+		for _, i := range page.Nodes {
+			if i.Content == nil || i.Status == nil || !wanted[p.convertStatus(i.Status.Name)] {
+				continue
+			}
+
+			if acceptedAfter != nil && (i.Status.UpdatedAt == nil || i.Status.UpdatedAt.Before(*acceptedAfter)) {
+				continue
+			}
+
+			items = append(items, i)
+		}
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = page.PageInfo.EndCursor
+	}
+
+	p.items = items
+
+	return nil
+}
+
+// FlattenStories function will take what we have so far and convert it into the
+// rubbernecker standard.
+func (p *Project) FlattenStories() (rubbernecker.Cards, error) {
+	if len(p.items) == 0 {
+		return nil, fmt.Errorf("github extension: %w", rubbernecker.ErrNoCards)
+	}
+
+	cards := rubbernecker.Cards{}
+
+	for _, i := range p.items {
+		stickers := rubbernecker.Stickers{}
+
+		for _, l := range i.Content.Labels.Nodes {
+			if sticker, ok := p.stickers.Get(l.Name); ok && !stickers.Has(sticker.Name) {
+				stickers = append(stickers, sticker)
+			}
+		}
+
+		var estimate *float64
+		if i.Estimate != nil {
+			estimate = i.Estimate.Number
+		}
+
+		if estimate != nil && *estimate == 0 {
+			if zeroPointsSticker, ok := p.stickers.Get("zero-points"); ok {
+				stickers = append(stickers, zeroPointsSticker)
+			}
+		}
+
+		sort.Sort(stickers)
+
+		assignees := rubbernecker.Members{}
+
+		for _, a := range i.Content.Assignees.Nodes {
+			assignees[a.DatabaseID] = &rubbernecker.Member{
+				ID:    a.DatabaseID,
+				Name:  a.displayName(),
+				Email: a.Email,
+			}
+		}
+
+		elapsed := 0
+		if i.Status.UpdatedAt != nil {
+			elapsed = calculateWorkingDays(*i.Status.UpdatedAt, time.Now())
+		}
+
+		status := p.convertStatus(i.Status.Name)
+
+		var acceptedAt *time.Time
+		if status == rubbernecker.StatusDone {
+			acceptedAt = i.Status.UpdatedAt
+		}
+
+		cards = append(cards, &rubbernecker.Card{
+			ID:         i.DatabaseID,
+			Assignees:  assignees,
+			Elapsed:    elapsed,
+			Status:     status.String(),
+			Stickers:   stickers,
+			Title:      i.Content.Title,
+			URL:        i.Content.URL,
+			StoryType:  strings.ToLower(i.Type),
+			Estimate:   estimate,
+			AcceptedAt: acceptedAt,
+		})
+	}
+
+	return cards, nil
+}
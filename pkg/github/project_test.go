@@ -0,0 +1,237 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/github"
+	"github.com/alphagov/paas-rubbernecker/pkg/helpers"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+const apiURL = `https://api.github.com/graphql`
+
+func fixture(name string) string {
+	b, err := os.ReadFile("./test/" + name)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(b)
+}
+
+var _ = Describe("GitHub Project Items", func() {
+	Context("Project not setup", func() {
+		It("should create a New() project", func() {
+			p, err := github.New("alphagov", 1, "test")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).NotTo(BeNil())
+		})
+
+		It("should fail to create a New() project without an owner", func() {
+			_, err := github.New("", 1, "test")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Project setup", func() {
+		var (
+			p rubbernecker.ProjectManagementService
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			p, err = github.New("alphagov", 1, "test")
+			httpmock.Activate()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			p.AcceptStickers(rubbernecker.Stickers{
+				rubbernecker.Sticker{
+					Name: "test",
+				},
+				rubbernecker.Sticker{
+					Name: "blocked",
+				},
+				rubbernecker.Sticker{
+					Name: "zero-points",
+				},
+			})
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchCards() from an API", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(401, `{"message":"Bad credentials"}`))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FetchCards() when GraphQL returns errors", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("errors.json")))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Could not resolve to an Organization"))
+		})
+
+		It("should FetchCards() from an API", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("items.json")))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should FetchCards() of any repository owner a page at a time", func() {
+			var request struct {
+				Query     string                 `json:"query"`
+				Variables map[string]interface{} `json:"variables"`
+			}
+
+			httpmock.RegisterResponder("POST", apiURL, func(req *http.Request) (*http.Response, error) {
+				Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+
+				return httpmock.NewStringResponse(200, fixture("items.json")), nil
+			})
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request.Query).To(ContainSubstring("repositoryOwner(login: $owner)"))
+			Expect(request.Variables).To(HaveKeyWithValue("first", BeNumerically("==", 100)))
+		})
+
+		It("should fail to FetchCards() of an unknown project", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, `{"data":{"repositoryOwner":{}}}`))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).To(MatchError(ContainSubstring("project alphagov/1 not found")))
+		})
+
+		It("should FetchCards() next page", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, fixture("items_page_1.json")),
+					httpmock.NewStringResponder(200, fixture("items_page_2.json")),
+				),
+			)
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["POST "+apiURL]).To(BeNumerically("==", 2))
+
+			cards, err := p.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(2))
+			Expect(cards[1].Title).To(Equal("Second page"))
+		})
+
+		It("should fail to FlattenStories() due to faulty API", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("items.json")))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusApproval, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := p.FlattenStories()
+
+			Expect(err).To(HaveOccurred())
+			Expect(cards).To(BeNil())
+		})
+
+		It("should FlattenStories() correctly", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("items.json")))
+
+			err := p.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := p.FlattenStories()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(3))
+
+			Expect(cards[0].ID).To(Equal(1001))
+			Expect(cards[0].Title).To(Equal("Test Rubbernecker"))
+			Expect(cards[0].Status).To(Equal("doing"))
+			Expect(cards[0].StoryType).To(Equal("issue"))
+			Expect(*cards[0].Estimate).To(Equal(3.0))
+			Expect(cards[0].Stickers).To(HaveLen(1))
+			Expect(cards[0].Stickers.Has("test")).To(BeTrue())
+			Expect(cards[0].Assignees).To(HaveLen(2))
+			Expect(cards[0].Assignees[1234].Name).To(Equal("Test Er"))
+			Expect(cards[0].Assignees[4321].Name).To(Equal("octocat"))
+
+			Expect(cards[1].Status).To(Equal("reviewing"))
+			Expect(cards[1].Estimate).To(BeNil())
+
+			Expect(cards[2].Status).To(Equal("next"))
+			Expect(cards[2].Stickers.Has("zero-points")).To(BeTrue())
+			Expect(cards[2].AcceptedAt).To(BeNil())
+		})
+
+		It("should FetchCards() done items accepted after given time", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("items.json")))
+
+			past := time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+			err := p.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+				"accepted_after": fmt.Sprintf("%d", past),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := p.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(1))
+			Expect(cards[0].Status).To(Equal("done"))
+			Expect(cards[0].Stickers.Has("blocked")).To(BeTrue())
+			Expect(cards[0].AcceptedAt).NotTo(BeNil())
+			Expect(cards[0].AcceptedAt.After(time.Unix(0, past*int64(time.Millisecond)))).To(BeTrue())
+		})
+
+		It("should not FetchCards() done items accepted before given time", func() {
+			httpmock.RegisterResponder("POST", apiURL,
+				httpmock.NewStringResponder(200, fixture("items.json")))
+
+			past := time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+			err := p.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+				"accepted_after": fmt.Sprintf("%d", past),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = p.FlattenStories()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FetchCards() with invalid accepted_after", func() {
+			err := p.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+				"accepted_after": "yesterday",
+			})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package ical_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestICal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rubbernecker iCalendar Suite")
+}
@@ -0,0 +1,412 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// event is a single rota event of the calendar. The recurring events carry
+// the rule they repeat by, along with the starts of the occurrences left out.
+type event struct {
+	Summary    string
+	Start      time.Time
+	End        time.Time
+	UID        string
+	Recurrence *recurrence
+	Exceptions []time.Time
+
+	// RecurrenceID is the start of the occurrence of the recurring event of
+	// the same UID the event replaces.
+	RecurrenceID time.Time
+}
+
+// recurrence is the RRULE the event repeats by. Only the daily and the weekly
+// rules are supported.
+type recurrence struct {
+	Freq      string
+	Interval  int
+	Count     int
+	Until     time.Time
+	ByDay     []time.Weekday
+	WeekStart time.Weekday
+}
+
+// property is a single content line of the calendar, such as
+// DTSTART;TZID=Europe/London:20180307T090000.
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// unfold will join the content lines folded over several lines, which are
+// continued with a leading space or tab.
+func unfold(content []byte) []string {
+	lines := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func parseProperty(line string) (property, error) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return property{}, fmt.Errorf("ical extension: invalid line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := property{
+		Name:   strings.ToUpper(parts[0]),
+		Params: map[string]string{},
+		Value:  line[colon+1:],
+	}
+
+	for _, param := range parts[1:] {
+		if i := strings.Index(param, "="); i >= 0 {
+			p.Params[strings.ToUpper(param[:i])] = strings.Trim(param[i+1:], `"`)
+		}
+	}
+
+	return p, nil
+}
+
+// parseTime reads the date or date-time of the property, either in UTC, in the
+// time zone given with TZID, or in the local time. The time zones which are
+// not known are refused rather than mistaken for the local time.
+func parseTime(p property) (time.Time, bool, error) {
+	loc := time.Local
+	if tzid := p.Params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown time zone %q", tzid)
+		}
+		loc = l
+	}
+
+	if p.Params["VALUE"] == "DATE" || len(p.Value) == 8 {
+		t, err := time.ParseInLocation("20060102", p.Value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(p.Value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.Value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", p.Value, loc)
+	return t, false, err
+}
+
+var unescaper = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+// parseCalendar will read the events of the calendar, along with its name.
+// The recurring events are read along with their rule, see expand. The
+// occurrences replaced by events of their own are left out of them. The events
+// which cannot be understood, such as the ones repeated by rules which are not
+// supported or in unknown time zones, are warned about and left out.
+func parseCalendar(content []byte) (string, []event, error) {
+	var (
+		name    string
+		events  []event
+		current *event
+		allDay  bool
+		hasEnd  bool
+		rule    string
+		invalid error
+	)
+
+	for _, line := range unfold(content) {
+		p, err := parseProperty(line)
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VEVENT"):
+			current, allDay, hasEnd, rule, invalid = &event{}, false, false, "", nil
+		case p.Name == "END" && strings.EqualFold(p.Value, "VEVENT"):
+			if current == nil {
+				return "", nil, fmt.Errorf("ical extension: unexpected end of event")
+			}
+
+			if !hasEnd && allDay {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+
+			if rule != "" && invalid == nil {
+				current.Recurrence, err = parseRecurrence(rule, current.Start)
+				if err != nil {
+					invalid = fmt.Errorf("invalid recurrence %q: %s", rule, err)
+				}
+			}
+
+			if invalid != nil {
+				log.Warnf("ical extension: skipping event %q: %s", current.UID, invalid)
+			} else if current.End.After(current.Start) {
+				events = append(events, *current)
+			}
+			current = nil
+		case current == nil:
+			if p.Name == "X-WR-CALNAME" {
+				name = unescaper.Replace(p.Value)
+			}
+		case p.Name == "SUMMARY":
+			current.Summary = strings.TrimSpace(unescaper.Replace(p.Value))
+		case p.Name == "DTSTART":
+			current.Start, allDay, err = parseTime(p)
+			if err != nil && invalid == nil {
+				invalid = fmt.Errorf("invalid start %q: %s", p.Value, err)
+			}
+		case p.Name == "DTEND":
+			current.End, _, err = parseTime(p)
+			if err != nil && invalid == nil {
+				invalid = fmt.Errorf("invalid end %q: %s", p.Value, err)
+			}
+			hasEnd = true
+		case p.Name == "UID":
+			current.UID = p.Value
+		case p.Name == "RRULE":
+			rule = p.Value
+		case p.Name == "EXDATE":
+			for _, value := range strings.Split(p.Value, ",") {
+				t, _, err := parseTime(property{Name: p.Name, Params: p.Params, Value: value})
+				if err != nil && invalid == nil {
+					invalid = fmt.Errorf("invalid exception %q: %s", value, err)
+				}
+				current.Exceptions = append(current.Exceptions, t)
+			}
+		case p.Name == "RECURRENCE-ID":
+			current.RecurrenceID, _, err = parseTime(p)
+			if err != nil && invalid == nil {
+				invalid = fmt.Errorf("invalid recurrence id %q: %s", p.Value, err)
+			}
+		}
+	}
+
+	if current != nil {
+		return "", nil, fmt.Errorf("ical extension: unterminated event")
+	}
+
+	for _, e := range events {
+		if e.RecurrenceID.IsZero() {
+			continue
+		}
+
+		for i := range events {
+			if events[i].Recurrence != nil && events[i].UID == e.UID {
+				events[i].Exceptions = append(events[i].Exceptions, e.RecurrenceID)
+			}
+		}
+	}
+
+	return name, events, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRecurrence reads the RRULE of the event starting at the given time,
+// such as FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20180430T090000Z. The rules
+// which cannot be followed are refused rather than read as a single event.
+func parseRecurrence(value string, start time.Time) (*recurrence, error) {
+	r := &recurrence{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(value, ";") {
+		i := strings.Index(part, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid part %q", part)
+		}
+		name, v := strings.ToUpper(part[:i]), strings.ToUpper(part[i+1:])
+
+		switch name {
+		case "FREQ":
+			if v != "DAILY" && v != "WEEKLY" {
+				return nil, fmt.Errorf("unsupported frequency %s", v)
+			}
+			r.Freq = v
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s %q", strings.ToLower(name), v)
+			}
+			if name == "INTERVAL" {
+				r.Interval = n
+			} else {
+				r.Count = n
+			}
+		case "UNTIL":
+			until, allDay, err := parseTime(property{Params: map[string]string{"TZID": start.Location().String()}, Value: v})
+			if err != nil {
+				return nil, fmt.Errorf("invalid until %q: %s", v, err)
+			}
+			if allDay {
+				until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+			r.Until = until
+		case "BYDAY", "WKST":
+			days := []time.Weekday{}
+			for _, d := range strings.Split(v, ",") {
+				day, ok := weekdays[d]
+				if !ok {
+					return nil, fmt.Errorf("unsupported day %s", d)
+				}
+				days = append(days, day)
+			}
+			if name == "BYDAY" {
+				r.ByDay = days
+			} else {
+				r.WeekStart = days[0]
+			}
+		default:
+			return nil, fmt.Errorf("unsupported %s", name)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("missing frequency")
+	}
+
+	return r, nil
+}
+
+// starts lists the starts of the occurrences of the event starting at the
+// given time, up to the given time.
+func (r *recurrence) starts(start, until time.Time) []time.Time {
+	starts := []time.Time{}
+
+	over := func(t time.Time) bool {
+		return !t.Before(until) ||
+			(!r.Until.IsZero() && t.After(r.Until)) ||
+			(r.Count > 0 && len(starts) >= r.Count)
+	}
+
+	// Each period is a day, or a week starting on the WeekStart, with the
+	// days of it the event happens on as offsets from its first day.
+	first, step, offsets := start, 1, []int{0}
+	if r.Freq == "WEEKLY" {
+		step = 7
+
+		if len(r.ByDay) > 0 {
+			first = start.AddDate(0, 0, -r.offset(start.Weekday()))
+			offsets = []int{}
+			for _, day := range r.ByDay {
+				offsets = append(offsets, r.offset(day))
+			}
+			sort.Ints(offsets)
+		}
+	}
+
+	for period := 0; ; period += r.Interval {
+		for _, offset := range offsets {
+			t := first.AddDate(0, 0, period*step+offset)
+			if t.Before(start) {
+				continue
+			}
+			if over(t) {
+				return starts
+			}
+			if r.Freq == "DAILY" && len(r.ByDay) > 0 && !r.on(t.Weekday()) {
+				continue
+			}
+
+			starts = append(starts, t)
+		}
+	}
+}
+
+// offset is how many days into the week starting on the WeekStart the day is.
+func (r *recurrence) offset(day time.Weekday) int {
+	return (int(day) - int(r.WeekStart) + 7) % 7
+}
+
+func (r *recurrence) on(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expand will repeat the recurring events up to the given time, leaving out
+// the exceptions. The events which do not repeat are kept as they are.
+func expand(events []event, until time.Time) []event {
+	tmp := []event{}
+
+	for _, e := range events {
+		if e.Recurrence == nil {
+			tmp = append(tmp, e)
+			continue
+		}
+
+		duration := e.End.Sub(e.Start)
+		for _, start := range e.Recurrence.starts(e.Start, until) {
+			if e.excepted(start) {
+				continue
+			}
+
+			tmp = append(tmp, event{Summary: e.Summary, Start: start, End: start.Add(duration), UID: e.UID})
+		}
+	}
+
+	return tmp
+}
+
+func (e event) excepted(start time.Time) bool {
+	for _, t := range e.Exceptions {
+		if t.Equal(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scheduleOf works out the schedule and the member the event is for, from its
+// summary, such as "In hours: Alice", or from the name of the calendar.
+func scheduleOf(calendar, summary string) (string, string) {
+	if i := strings.Index(summary, ":"); i >= 0 {
+		return strings.TrimSpace(summary[:i]), strings.TrimSpace(summary[i+1:])
+	}
+
+	return calendar, summary
+}
+
+// leaveOf works out the member away and the reason why from the summary of the
+// event, such as "Alice: Annual leave".
+func leaveOf(summary string) (string, string) {
+	if i := strings.Index(summary, ":"); i >= 0 {
+		return strings.TrimSpace(summary[:i]), strings.TrimSpace(summary[i+1:])
+	}
+
+	return summary, ""
+}
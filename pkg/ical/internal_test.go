@@ -0,0 +1,226 @@
+package ical
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("iCalendar internal functionality", func() {
+	It("should parseCalendar() correctly", func() {
+		content, err := os.ReadFile("test/rota.ics")
+		Expect(err).NotTo(HaveOccurred())
+
+		name, events, err := parseCalendar(content)
+		Expect(err).NotTo(HaveOccurred())
+
+		london, err := time.LoadLocation("Europe/London")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(name).To(Equal("PaaS team rota - in hours"))
+		Expect(events).To(HaveLen(3))
+
+		Expect(events[0].Summary).To(Equal("Alice"))
+		Expect(events[0].Start).To(Equal(time.Date(2018, 3, 5, 9, 0, 0, 0, time.UTC)))
+		Expect(events[0].End).To(Equal(time.Date(2018, 3, 7, 9, 0, 0, 0, time.UTC)))
+
+		Expect(events[1].Summary).To(Equal("Comms lead: Bob, the builder"))
+		Expect(events[1].Start.Equal(time.Date(2018, 7, 5, 8, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(events[1].End.Location()).To(Equal(london))
+
+		Expect(events[2].Summary).To(Equal("Carol"))
+		Expect(events[2].End.Sub(events[2].Start)).To(Equal(24 * time.Hour))
+	})
+
+	It("should fail to parseCalendar() which is invalid", func() {
+		invalid := map[string]string{
+			"BEGIN:VEVENT\nSUMMARY:Alice":        "unterminated event",
+			"END:VEVENT":                         "unexpected end of event",
+			"BEGIN:VEVENT\nnonsense\nEND:VEVENT": "invalid line",
+		}
+
+		for content, message := range invalid {
+			_, _, err := parseCalendar([]byte(content))
+			Expect(err).To(MatchError(ContainSubstring(message)), content)
+		}
+	})
+
+	Context("with the events which cannot be understood", func() {
+		var output *bytes.Buffer
+
+		BeforeEach(func() {
+			output = &bytes.Buffer{}
+			log.SetOutput(output)
+		})
+
+		AfterEach(func() {
+			log.SetOutput(os.Stderr)
+		})
+
+		It("should parseCalendar() skipping them and warning about each of them", func() {
+			content := `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:good-1
+SUMMARY:Alice
+DTSTART:20180305T090000Z
+DTEND:20180306T090000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:monthly
+SUMMARY:Bob
+DTSTART:20180306T090000Z
+DTEND:20180307T090000Z
+RRULE:FREQ=MONTHLY
+END:VEVENT
+BEGIN:VEVENT
+UID:windows
+SUMMARY:Carol
+DTSTART;TZID=GMT Standard Time:20180307T090000
+DTEND;TZID=GMT Standard Time:20180308T090000
+END:VEVENT
+BEGIN:VEVENT
+UID:good-2
+SUMMARY:Dave
+DTSTART:20180308T090000Z
+DTEND:20180309T090000Z
+RRULE:FREQ=WEEKLY;BYDAY=TH
+END:VEVENT
+END:VCALENDAR
+`
+
+			_, events, err := parseCalendar([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].UID).To(Equal("good-1"))
+			Expect(events[1].UID).To(Equal("good-2"))
+			Expect(events[1].Recurrence).NotTo(BeNil())
+
+			Expect(output.String()).To(ContainSubstring(`skipping event \"monthly\": invalid recurrence \"FREQ=MONTHLY\"`))
+			Expect(output.String()).To(ContainSubstring(`skipping event \"windows\": invalid start`))
+			Expect(output.String()).To(ContainSubstring(`unknown time zone \"GMT Standard Time\"`))
+		})
+
+		It("should parseCalendar() skipping the events which are invalid", func() {
+			invalid := map[string]string{
+				"DTSTART:yesterday":  "invalid start",
+				"DTEND:20180305T25":  "invalid end",
+				"EXDATE:someday":     "invalid exception",
+				"RECURRENCE-ID:soon": "invalid recurrence id",
+			}
+
+			for line, message := range invalid {
+				output.Reset()
+
+				_, events, err := parseCalendar([]byte("BEGIN:VEVENT\nUID:1\nDTSTART:20180305T090000Z\nDTEND:20180305T170000Z\n" + line + "\nEND:VEVENT"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(BeEmpty(), line)
+				Expect(output.String()).To(ContainSubstring(message), line)
+			}
+		})
+
+		It("should parseCalendar() skipping the recurrences which are not supported", func() {
+			invalid := map[string]string{
+				"FREQ=MONTHLY":             "unsupported frequency MONTHLY",
+				"FREQ=YEARLY;BYMONTH=3":    "unsupported frequency YEARLY",
+				"FREQ=WEEKLY;BYDAY=1MO":    "unsupported day 1MO",
+				"FREQ=WEEKLY;BYMONTH=3":    "unsupported BYMONTH",
+				"FREQ=DAILY;BYMONTHDAY=1":  "unsupported BYMONTHDAY",
+				"FREQ=DAILY;INTERVAL=0":    "invalid interval",
+				"FREQ=DAILY;UNTIL=someday": "invalid until",
+				"COUNT=3":                  "missing frequency",
+			}
+
+			for rule, message := range invalid {
+				output.Reset()
+
+				_, events, err := parseCalendar([]byte("BEGIN:VEVENT\nUID:1\nDTSTART:20180305T090000Z\nDTEND:20180305T170000Z\nRRULE:" + rule + "\nEND:VEVENT"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(BeEmpty(), rule)
+				Expect(output.String()).To(ContainSubstring(message), rule)
+			}
+		})
+	})
+
+	Context("expand", func() {
+		// Mon 5 Mar 2018 09:00 UTC.
+		start := time.Date(2018, 3, 5, 9, 0, 0, 0, time.UTC)
+
+		starts := func(rule string, extra ...string) []string {
+			content := "BEGIN:VEVENT\nUID:1\nSUMMARY:Alice\nDTSTART:20180305T090000Z\nDTEND:20180305T170000Z\nRRULE:" + rule + "\n"
+			for _, line := range extra {
+				content += line + "\n"
+			}
+			content += "END:VEVENT\n"
+
+			_, events, err := parseCalendar([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+
+			tmp := []string{}
+			for _, e := range expand(events, start.AddDate(0, 0, 21)) {
+				Expect(e.End.Sub(e.Start)).To(Equal(8 * time.Hour))
+				tmp = append(tmp, e.Start.Format("Mon 2 Jan"))
+			}
+			return tmp
+		}
+
+		It("should repeat the daily events", func() {
+			Expect(starts("FREQ=DAILY;COUNT=3")).To(Equal([]string{"Mon 5 Mar", "Tue 6 Mar", "Wed 7 Mar"}))
+			Expect(starts("FREQ=DAILY;INTERVAL=8")).To(Equal([]string{"Mon 5 Mar", "Tue 13 Mar", "Wed 21 Mar"}))
+			Expect(starts("FREQ=DAILY;UNTIL=20180307T090000Z")).To(Equal([]string{"Mon 5 Mar", "Tue 6 Mar", "Wed 7 Mar"}))
+			Expect(starts("FREQ=DAILY;UNTIL=20180306")).To(Equal([]string{"Mon 5 Mar", "Tue 6 Mar"}))
+			Expect(starts("FREQ=DAILY;BYDAY=SA,SU;COUNT=3")).To(Equal([]string{"Sat 10 Mar", "Sun 11 Mar", "Sat 17 Mar"}))
+		})
+
+		It("should repeat the weekly events", func() {
+			Expect(starts("FREQ=WEEKLY")).To(Equal([]string{"Mon 5 Mar", "Mon 12 Mar", "Mon 19 Mar"}))
+			Expect(starts("FREQ=WEEKLY;INTERVAL=2")).To(Equal([]string{"Mon 5 Mar", "Mon 19 Mar"}))
+			Expect(starts("FREQ=WEEKLY;BYDAY=TH,MO;COUNT=3")).To(Equal([]string{"Mon 5 Mar", "Thu 8 Mar", "Mon 12 Mar"}))
+			Expect(starts("FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,WE;WKST=SU")).To(Equal([]string{"Wed 7 Mar", "Sun 18 Mar", "Wed 21 Mar"}))
+		})
+
+		It("should leave out the exceptions", func() {
+			Expect(starts("FREQ=WEEKLY", "EXDATE:20180312T090000Z,20180319T090000Z")).To(Equal([]string{"Mon 5 Mar"}))
+		})
+
+		It("should leave out the occurrences replaced by events of their own", func() {
+			content := "BEGIN:VEVENT\nUID:1\nSUMMARY:Alice\nDTSTART:20180305T090000Z\nDTEND:20180305T170000Z\nRRULE:FREQ=WEEKLY;COUNT=2\nEND:VEVENT\n" +
+				"BEGIN:VEVENT\nUID:1\nSUMMARY:Bob\nRECURRENCE-ID:20180312T090000Z\nDTSTART:20180313T090000Z\nDTEND:20180313T170000Z\nEND:VEVENT\n"
+
+			_, events, err := parseCalendar([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+
+			events = expand(events, start.AddDate(0, 0, 21))
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Summary).To(Equal("Alice"))
+			Expect(events[0].Start).To(Equal(start))
+			Expect(events[1].Summary).To(Equal("Bob"))
+		})
+
+		It("should keep the recurring events in their time zone", func() {
+			london, err := time.LoadLocation("Europe/London")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, events, err := parseCalendar([]byte("BEGIN:VEVENT\nDTSTART;TZID=Europe/London:20180323T090000\nDTEND;TZID=Europe/London:20180323T170000\nRRULE:FREQ=WEEKLY;COUNT=2\nEND:VEVENT"))
+			Expect(err).NotTo(HaveOccurred())
+
+			events = expand(events, start.AddDate(0, 0, 40))
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].Start).To(Equal(time.Date(2018, 3, 30, 9, 0, 0, 0, london)))
+		})
+	})
+
+	It("should work out the scheduleOf() the event", func() {
+		schedule, member := scheduleOf("Calendar", "Comms lead: Bob")
+		Expect(schedule).To(Equal("Comms lead"))
+		Expect(member).To(Equal("Bob"))
+
+		schedule, member = scheduleOf("Calendar", "Alice")
+		Expect(schedule).To(Equal("Calendar"))
+		Expect(member).To(Equal("Alice"))
+	})
+})
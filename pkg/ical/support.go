@@ -0,0 +1,165 @@
+package ical
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// Calendar will hold some internal and external information, such as client
+// and the contents of the iCalendar file of the rota events, or of the leave.
+// The events are kept for the Horizon ahead.
+type Calendar struct {
+	Horizon time.Duration
+
+	client  *http.Client
+	source  string
+	content []byte
+}
+
+// New will compose a Calendar struct ready to use by the rubbernecker. The
+// source is either the path of an iCalendar (.ics) file, or an http(s) URL it
+// is downloaded from.
+func New(source string) (*Calendar, error) {
+	if source == "" {
+		return nil, fmt.Errorf("ical extension: calendar file or URL is required")
+	}
+
+	return &Calendar{
+		Horizon: 14 * 24 * time.Hour,
+		client:  http.DefaultClient,
+		source:  source,
+	}, nil
+}
+
+// FetchSupport will read the calendar from the file, or download it from the
+// URL, storing it in the Calendar for future use.
+func (c *Calendar) FetchSupport(ctx context.Context) error {
+	return c.fetch(ctx)
+}
+
+// FetchLeave will read the calendar the same way as FetchSupport.
+func (c *Calendar) FetchLeave(ctx context.Context) error {
+	return c.fetch(ctx)
+}
+
+func (c *Calendar) fetch(ctx context.Context) error {
+	if !strings.HasPrefix(c.source, "http://") && !strings.HasPrefix(c.source, "https://") {
+		content, err := os.ReadFile(c.source)
+		if err != nil {
+			return err
+		}
+
+		c.content = content
+
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.source, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return refresher.NewResponseError(resp, fmt.Errorf("ical extension: unexpected response code %d", resp.StatusCode))
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	c.content = content
+
+	return nil
+}
+
+// FlattenSupport should convert the stored calendar into rubbernecker
+// compatible SupportRota. The events summarised as "<schedule>: <member>" are
+// the shifts of that schedule, while the rest are the shifts of the schedule
+// named after the calendar.
+func (c *Calendar) FlattenSupport() (rubbernecker.SupportRota, error) {
+	name, events, err := parseCalendar(c.content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	until := now.Add(c.Horizon)
+	events = expand(events, until)
+
+	support := rubbernecker.SupportRota{}
+	shifts := map[string][]rubbernecker.Shift{}
+
+	for _, e := range events {
+		if !e.End.After(now) || !e.Start.Before(until) {
+			continue
+		}
+
+		schedule, member := scheduleOf(name, e.Summary)
+		if schedule == "" || member == "" {
+			continue
+		}
+
+		start, end := e.Start, e.End
+		shifts[schedule] = append(shifts[schedule], rubbernecker.Shift{
+			Member: member,
+			Start:  &start,
+			End:    &end,
+		})
+	}
+
+	for schedule, s := range shifts {
+		support[schedule] = rubbernecker.NewSupport(schedule, "", s, now)
+	}
+
+	return support, nil
+}
+
+// FlattenLeave should convert the stored calendar into rubbernecker compatible
+// Absences. The events are summarised as "<member>" or "<member>: <reason>",
+// naming the member by their name or email.
+func (c *Calendar) FlattenLeave() (rubbernecker.Absences, error) {
+	_, events, err := parseCalendar(c.content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	until := now.Add(c.Horizon)
+	events = expand(events, until)
+
+	leave := rubbernecker.Absences{}
+	for _, e := range events {
+		if !e.End.After(now) || !e.Start.Before(until) {
+			continue
+		}
+
+		member, reason := leaveOf(e.Summary)
+		if member == "" {
+			continue
+		}
+
+		leave = append(leave, rubbernecker.Absence{
+			Member: member,
+			Reason: reason,
+			Start:  e.Start,
+			End:    e.End,
+		})
+	}
+
+	return leave, nil
+}
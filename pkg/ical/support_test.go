@@ -0,0 +1,153 @@
+package ical_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/ical"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Calendar", func() {
+	var (
+		now     = time.Now().UTC().Truncate(time.Second)
+		content = func() string {
+			at := func(hours int) string {
+				return now.Add(time.Duration(hours) * time.Hour).Format("20060102T150405Z")
+			}
+			return fmt.Sprintf("BEGIN:VCALENDAR\r\nX-WR-CALNAME:In hours\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:Alice\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:Bob\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:Comms: Carol\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:Dave\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+				"BEGIN:VEVENT\r\nSUMMARY:Eve\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+				"END:VCALENDAR\r\n",
+				at(-6), at(2), at(2), at(26), at(-1), at(1), at(-48), at(-24), at(24*20), at(24*21))
+		}()
+	)
+
+	It("should fail to create New() calendar without the source", func() {
+		_, err := ical.New("")
+
+		Expect(err).To(MatchError(ContainSubstring("calendar file or URL is required")))
+	})
+
+	It("should FetchSupport() from a file and FlattenSupport() correctly", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "rota.ics")
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+
+		c, err := ical.New(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		var service rubbernecker.SupportService = c
+		Expect(service.FetchSupport(context.Background())).To(Succeed())
+
+		support, err := service.FlattenSupport()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(support).To(HaveLen(2))
+		Expect(support["In hours"].Member).To(Equal("Alice"))
+		Expect(*support["In hours"].Until).To(Equal(now.Add(2 * time.Hour)))
+		Expect(support["In hours"].Next.Member).To(Equal("Bob"))
+		Expect(support["In hours"].Shifts).To(HaveLen(2))
+		Expect(support["Comms"].Member).To(Equal("Carol"))
+	})
+
+	It("should FetchLeave() from a file and FlattenLeave() correctly", func() {
+		at := func(hours int) string {
+			return now.Add(time.Duration(hours) * time.Hour).Format("20060102T150405Z")
+		}
+		path := filepath.Join(GinkgoT().TempDir(), "leave.ics")
+		Expect(os.WriteFile(path, []byte(fmt.Sprintf("BEGIN:VCALENDAR\r\n"+
+			"BEGIN:VEVENT\r\nSUMMARY:alice@example.com: Annual leave\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nSUMMARY:Bob\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nSUMMARY:Carol\r\nDTSTART:%s\r\nDTEND:%s\r\nEND:VEVENT\r\n"+
+			"END:VCALENDAR\r\n", at(-24), at(24), at(48), at(72), at(-72), at(-48))), 0644)).To(Succeed())
+
+		c, err := ical.New(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		var service rubbernecker.LeaveService = c
+		Expect(service.FetchLeave(context.Background())).To(Succeed())
+
+		leave, err := service.FlattenLeave()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(leave).To(Equal(rubbernecker.Absences{
+			{Member: "alice@example.com", Reason: "Annual leave", Start: now.Add(-24 * time.Hour), End: now.Add(24 * time.Hour)},
+			{Member: "Bob", Start: now.Add(48 * time.Hour), End: now.Add(72 * time.Hour)},
+		}))
+	})
+
+	It("should FlattenSupport() the shifts repeated weekly", func() {
+		at := func(hours int) string {
+			return now.Add(time.Duration(hours) * time.Hour).Format("20060102T150405Z")
+		}
+		path := filepath.Join(GinkgoT().TempDir(), "rota.ics")
+		Expect(os.WriteFile(path, []byte(fmt.Sprintf("BEGIN:VCALENDAR\r\nX-WR-CALNAME:In hours\r\n"+
+			"BEGIN:VEVENT\r\nSUMMARY:Alice\r\nDTSTART:%s\r\nDTEND:%s\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"+
+			"END:VCALENDAR\r\n", at(-24*28-1), at(-24*28+1))), 0644)).To(Succeed())
+
+		c, err := ical.New(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.FetchSupport(context.Background())).To(Succeed())
+
+		support, err := c.FlattenSupport()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(support["In hours"].Member).To(Equal("Alice"))
+		Expect(*support["In hours"].Until).To(Equal(now.Add(time.Hour)))
+		Expect(*support["In hours"].Next.Start).To(Equal(now.Add(7*24*time.Hour - time.Hour)))
+		Expect(support["In hours"].Shifts).To(HaveLen(3))
+	})
+
+	It("should fail to FetchSupport() from a missing file", func() {
+		c, err := ical.New(filepath.Join(GinkgoT().TempDir(), "missing.ics"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.FetchSupport(context.Background())).NotTo(Succeed())
+	})
+
+	Context("calendar URL", func() {
+		calendarURL := "https://calendar.example.com/rota.ics"
+
+		BeforeEach(func() {
+			httpmock.Activate()
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should FetchSupport() from a URL", func() {
+			httpmock.RegisterResponder("GET", calendarURL, httpmock.NewStringResponder(200, content))
+
+			c, err := ical.New(calendarURL)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.FetchSupport(context.Background())).To(Succeed())
+
+			support, err := c.FlattenSupport()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(support["In hours"].Member).To(Equal("Alice"))
+		})
+
+		It("should fail to FetchSupport() from a URL", func() {
+			httpmock.RegisterResponder("GET", calendarURL, httpmock.NewStringResponder(http.StatusNotFound, ``))
+
+			c, err := ical.New(calendarURL)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.FetchSupport(context.Background())).To(MatchError(ContainSubstring("unexpected response code 404")))
+		})
+	})
+})
@@ -0,0 +1,348 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// timeLayout is the format Jira uses for the timestamps in its REST API.
+const timeLayout = "2006-01-02T15:04:05.000-0700"
+
+// jqlTimeLayout is the format accepted by the JQL date comparisons.
+const jqlTimeLayout = "2006/01/02 15:04"
+
+const pageSize = 100
+
+// DefaultStatusMapping is the conversion table between the statuses of the
+// default Jira Software workflows and rubbernecker statuses. The keys are
+// matched case insensitively.
+var DefaultStatusMapping = map[string]rubbernecker.Status{
+	"backlog":                  rubbernecker.StatusScheduled,
+	"to do":                    rubbernecker.StatusScheduled,
+	"selected for development": rubbernecker.StatusScheduled,
+	"in progress":              rubbernecker.StatusDoing,
+	"in review":                rubbernecker.StatusReviewal,
+	"code review":              rubbernecker.StatusReviewal,
+	"approval":                 rubbernecker.StatusApproval,
+	"awaiting approval":        rubbernecker.StatusApproval,
+	"rejected":                 rubbernecker.StatusRejected,
+	"done":                     rubbernecker.StatusDone,
+	"closed":                   rubbernecker.StatusDone,
+	"resolved":                 rubbernecker.StatusDone,
+}
+
+// DefaultFlagField is the custom field Jira Cloud uses for the "Flagged"
+// impediment marker.
+const DefaultFlagField = "customfield_10021"
+
+type jiraTime struct {
+	time.Time
+}
+
+func (t *jiraTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	parsed, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed
+
+	return nil
+}
+
+type searchResponse struct {
+	StartAt    int      `json:"startAt"`
+	MaxResults int      `json:"maxResults"`
+	Total      int      `json:"total"`
+	Issues     []*issue `json:"issues"`
+}
+
+type issue struct {
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	Fields    fields     `json:"fields"`
+	Changelog *changelog `json:"changelog,omitempty"`
+}
+
+type fields struct {
+	Summary   string     `json:"summary"`
+	Status    *status    `json:"status"`
+	Labels    []string   `json:"labels"`
+	Assignee  *user      `json:"assignee"`
+	IssueType *issueType `json:"issuetype"`
+	Created   *jiraTime  `json:"created"`
+
+	// custom holds all the fields, so the ones configured by their ID, such as
+	// flagged or story points, can be looked up.
+	custom map[string]json.RawMessage
+}
+
+func (f *fields) UnmarshalJSON(b []byte) error {
+	type plain fields
+
+	if err := json.Unmarshal(b, (*plain)(f)); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, &f.custom)
+}
+
+// flagged checks if the custom field holds any value. Jira stores the
+// impediment flag as a list of selected options.
+func (f *fields) flagged(field string) bool {
+	raw, ok := f.custom[field]
+	if !ok {
+		return false
+	}
+
+	var options []interface{}
+	if err := json.Unmarshal(raw, &options); err != nil {
+		return false
+	}
+
+	return len(options) > 0
+}
+
+func (f *fields) number(field string) *float64 {
+	raw, ok := f.custom[field]
+	if !ok {
+		return nil
+	}
+
+	var n *float64
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return nil
+	}
+
+	return n
+}
+
+type status struct {
+	Name string `json:"name"`
+}
+
+type issueType struct {
+	Name string `json:"name"`
+}
+
+type changelog struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	Histories  []history `json:"histories"`
+}
+
+// truncated tells whether the changelog expanded in the search results holds
+// only some of the histories of the issue.
+func (c *changelog) truncated() bool {
+	return c != nil && len(c.Histories) < c.Total
+}
+
+type changelogPage struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	IsLast     bool      `json:"isLast"`
+	Values     []history `json:"values"`
+}
+
+type history struct {
+	Created jiraTime      `json:"created"`
+	Items   []historyItem `json:"items"`
+}
+
+type historyItem struct {
+	Field    string `json:"field"`
+	ToString string `json:"toString"`
+}
+
+type transition struct {
+	State    string
+	Occurred time.Time
+}
+
+type user struct {
+	AccountID    string `json:"accountId"`
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       *bool  `json:"active"`
+}
+
+// id converts the Jira user identifier into the integer rubbernecker uses for
+// its members. Jira Cloud uses opaque account IDs and Jira Server uses keys,
+// neither of which are numeric.
+func (u *user) id() int {
+	identifier := u.AccountID
+	if identifier == "" {
+		identifier = u.Key
+	}
+	if identifier == "" {
+		identifier = u.Name
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(identifier))
+
+	return int(h.Sum32() & 0x7fffffff)
+}
+
+func (u *user) member() *rubbernecker.Member {
+	return &rubbernecker.Member{
+		ID:    u.id(),
+		Name:  u.DisplayName,
+		Email: u.EmailAddress,
+	}
+}
+
+func (b *Board) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(b.username, b.token)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return refresher.NewResponseError(resp, fmt.Errorf("jira extension: unexpected response code %d", resp.StatusCode))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// transitions extracts the status changes from the issue changelog.
+func (i *issue) transitions() []transition {
+	t := []transition{}
+
+	if i.Changelog == nil {
+		return t
+	}
+
+	for _, h := range i.Changelog.Histories {
+		for _, item := range h.Items {
+			if item.Field == "status" {
+				t = append(t, transition{State: item.ToString, Occurred: h.Created.Time})
+			}
+		}
+	}
+
+	return t
+}
+
+// calculateInState works out how long the issue has been in the given state,
+// similarly to how the PivotalTracker extension does it using transitions.
+func calculateInState(transitions []transition, state string, fallback time.Time) int {
+	var m transition
+
+	for _, e := range transitions {
+		if !strings.EqualFold(e.State, state) {
+			continue
+		}
+
+		if e.Occurred.After(m.Occurred) {
+			m = e
+		}
+	}
+
+	if m.Occurred.IsZero() {
+		if fallback.IsZero() {
+			return 0
+		}
+
+		m.Occurred = fallback
+	}
+
+	return calculateWorkingDays(m.Occurred, time.Now())
+}
+
+// calculateEnteredState finds when the issue has last moved into the given
+// state, if ever.
+func calculateEnteredState(transitions []transition, state string) *time.Time {
+	var entered *time.Time
+
+	for i, e := range transitions {
+		if strings.EqualFold(e.State, state) && (entered == nil || e.Occurred.After(*entered)) {
+			entered = &transitions[i].Occurred
+		}
+	}
+
+	return entered
+}
+
+func calculateWorkingDays(since, until time.Time) int {
+	days := 0
+
+	for {
+		if since.After(until) {
+			break
+		}
+
+		if since.Weekday() != time.Saturday && since.Weekday() != time.Sunday {
+			days++
+		}
+
+		since = since.Add(24 * time.Hour)
+	}
+
+	return days
+}
+
+// composeState lists the Jira statuses which should be requested for the
+// given rubbernecker status.
+func (b *Board) composeState(s rubbernecker.Status) []string {
+	states := []string{}
+
+	for name, mapped := range b.statuses {
+		if mapped == s || (s == rubbernecker.StatusAll && mapped != rubbernecker.StatusDone) {
+			states = append(states, name)
+		}
+	}
+
+	sort.Strings(states)
+
+	return states
+}
+
+func (b *Board) convertState(name string) string {
+	if s, ok := b.statuses[strings.ToLower(name)]; ok {
+		return s.String()
+	}
+
+	return "unknown"
+}
+
+func quoteJQL(values []string) string {
+	quoted := []string{}
+
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, strings.Replace(v, `"`, `\"`, -1)))
+	}
+
+	return strings.Join(quoted, ",")
+}
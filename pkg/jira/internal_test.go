@@ -0,0 +1,107 @@
+package jira
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Jira internal functionality", func() {
+	var b *Board
+
+	BeforeEach(func() {
+		var err error
+
+		b, err = New("https://example.atlassian.net", "PAAS", "tester", "test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should calculateWorkingDays() over weekend correctly", func() {
+		days := calculateWorkingDays(time.Date(2017, 10, 27, 12, 0, 0, 0, time.Local), time.Date(2017, 11, 1, 12, 0, 0, 0, time.Local))
+
+		Expect(days).To(Equal(4))
+	})
+
+	It("should calculateInState() using the fallback without transitions", func() {
+		Expect(calculateInState([]transition{}, "In Progress", time.Time{})).To(Equal(0))
+
+		created := time.Now().Add(-3 * 24 * time.Hour)
+		Expect(calculateInState([]transition{}, "In Progress", created)).To(Equal(calculateWorkingDays(created, time.Now())))
+	})
+
+	It("should calculateInState() correctly if it has been restarted", func() {
+		t := []transition{
+			{State: "In Progress", Occurred: time.Now().Add(-7 * 24 * time.Hour)},
+			{State: "In Review", Occurred: time.Now().Add(-5 * 24 * time.Hour)},
+			{State: "In Progress", Occurred: time.Now().Add(-4 * 24 * time.Hour)},
+		}
+
+		Expect(calculateInState(t, "in progress", time.Time{})).To(Equal(calculateWorkingDays(t[2].Occurred, time.Now())))
+	})
+
+	It("should composeState() correctly", func() {
+		Expect(b.composeState(rubbernecker.StatusDoing)).To(Equal([]string{"in progress"}))
+		Expect(b.composeState(rubbernecker.StatusDone)).To(Equal([]string{"closed", "done", "resolved"}))
+		Expect(b.composeState(rubbernecker.StatusAll)).NotTo(ContainElement("done"))
+		Expect(b.composeState(rubbernecker.StatusAll)).To(ContainElement("to do"))
+	})
+
+	It("should convertState() correctly", func() {
+		Expect(b.convertState("To Do")).To(Equal("next"))
+		Expect(b.convertState("In Progress")).To(Equal("doing"))
+		Expect(b.convertState("Code Review")).To(Equal("reviewing"))
+		Expect(b.convertState("Awaiting Approval")).To(Equal("approving"))
+		Expect(b.convertState("Rejected")).To(Equal("rejected"))
+		Expect(b.convertState("Done")).To(Equal("done"))
+		Expect(b.convertState("testing")).To(Equal("unknown"))
+	})
+
+	It("should convertState() using a custom table", func() {
+		b.MapStatuses(map[string]rubbernecker.Status{
+			"In QA": rubbernecker.StatusApproval,
+		})
+
+		Expect(b.convertState("in qa")).To(Equal("approving"))
+		Expect(b.convertState("Done")).To(Equal("unknown"))
+	})
+
+	It("should read the custom fields", func() {
+		var f fields
+
+		err := json.Unmarshal([]byte(`{"summary":"test","customfield_1":[{"value":"Impediment"}],"customfield_2":null,"customfield_3":5}`), &f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.Summary).To(Equal("test"))
+		Expect(f.flagged("customfield_1")).To(BeTrue())
+		Expect(f.flagged("customfield_2")).To(BeFalse())
+		Expect(f.flagged("customfield_4")).To(BeFalse())
+		Expect(*f.number("customfield_3")).To(Equal(5.0))
+		Expect(f.number("customfield_2")).To(BeNil())
+	})
+
+	It("should convert user identifiers consistently", func() {
+		a := &user{AccountID: "5b10a2844c20165700ede21g"}
+		c := &user{AccountID: "5b10ac8d82e05b22cc7d4ef5"}
+
+		Expect(a.id()).To(Equal(a.id()))
+		Expect(a.id()).NotTo(Equal(c.id()))
+		Expect(a.id()).To(BeNumerically(">=", 0))
+	})
+
+	It("should calculateEnteredState() correctly", func() {
+		first := time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)
+		last := first.Add(48 * time.Hour)
+		transitions := []transition{
+			{State: "Done", Occurred: first},
+			{State: "In Progress", Occurred: first.Add(time.Hour)},
+			{State: "Done", Occurred: last},
+		}
+
+		Expect(*calculateEnteredState(transitions, "done")).To(Equal(last))
+		Expect(calculateEnteredState(transitions, "In Review")).To(BeNil())
+	})
+})
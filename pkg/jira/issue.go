@@ -0,0 +1,327 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var orderByPattern = regexp.MustCompile(`(?is)(^|\s)ORDER\s+BY\s.*$`)
+
+// Board will be responsible for acting as the issue resource returned by the
+// Jira Software API.
+type Board struct {
+	client        *http.Client
+	baseURL       string
+	username      string
+	token         string
+	project       string
+	boardID       int
+	jql           string
+	flagField     string
+	estimateField string
+	location      *time.Location
+	statuses      map[string]rubbernecker.Status
+	issues        []*issue
+	stickers      rubbernecker.Stickers
+	users         []*user
+}
+
+// New will compose a Board struct ready to use by the rubbernecker. The
+// baseURL is the address of the Jira instance, such as
+// https://example.atlassian.net and the project is the key of the project the
+// issues and members will be read from.
+func New(baseURL, project, username, token string) (*Board, error) {
+	if _, err := url.Parse(baseURL); err != nil || baseURL == "" {
+		return nil, fmt.Errorf("jira extension: invalid base URL %q", baseURL)
+	}
+
+	if project == "" {
+		return nil, fmt.Errorf("jira extension: project key is required")
+	}
+
+	return &Board{
+		client:    http.DefaultClient,
+		baseURL:   strings.TrimSuffix(baseURL, "/") + "/",
+		username:  username,
+		token:     token,
+		project:   project,
+		jql:       fmt.Sprintf(`project = "%s"`, project),
+		flagField: DefaultFlagField,
+		location:  time.UTC,
+		statuses:  DefaultStatusMapping,
+		stickers:  rubbernecker.Stickers{},
+	}, nil
+}
+
+// UseBoard will read the issues through the agile API of the given board,
+// rather than searching the whole project.
+func (b *Board) UseBoard(id int) {
+	b.boardID = id
+}
+
+// UseJQL will replace the default project query with a custom one. Any
+// ORDER BY clause is dropped, as the issues are always read in the rank order.
+func (b *Board) UseJQL(jql string) {
+	b.jql = strings.TrimSpace(orderByPattern.ReplaceAllString(jql, ""))
+}
+
+// UseFields sets the IDs of the custom fields holding the impediment flag and
+// the story points estimate. Either can be left empty to disable it.
+func (b *Board) UseFields(flagField, estimateField string) {
+	b.flagField = flagField
+	b.estimateField = estimateField
+}
+
+// UseLocation sets the time zone the dates in the JQL queries are written in.
+// Jira reads them in the time zone of the profile of the user it is accessed
+// as, which is UTC unless changed.
+func (b *Board) UseLocation(location *time.Location) {
+	b.location = location
+}
+
+// MapStatuses will replace the default conversion table of the Jira workflow
+// statuses into rubbernecker statuses. Keys are matched case insensitively.
+func (b *Board) MapStatuses(mapping map[string]rubbernecker.Status) {
+	b.statuses = map[string]rubbernecker.Status{}
+
+	for name, status := range mapping {
+		b.statuses[strings.ToLower(strings.TrimSpace(name))] = status
+	}
+}
+
+// AcceptStickers will make a note of enabled stickers in the application and
+// attempt to assign them to each issue.
+func (b *Board) AcceptStickers(stickers rubbernecker.Stickers) {
+	b.stickers = stickers
+}
+
+// FetchCards will fetch the issues from Jira. The "accepted_after" parameter
+// (milliseconds since epoch) is understood, to keep the behaviour in line with
+// the PivotalTracker extension. When none of the Jira statuses is mapped onto
+// the requested status, there is nothing to fetch.
+func (b *Board) FetchCards(ctx context.Context, status rubbernecker.Status, params map[string]string) error {
+	states := b.composeState(status)
+	if len(states) == 0 {
+		b.issues = []*issue{}
+		return nil
+	}
+
+	jql := []string{}
+	if b.jql != "" {
+		jql = append(jql, "("+b.jql+")")
+	}
+	jql = append(jql, fmt.Sprintf("status in (%s)", quoteJQL(states)))
+
+	var acceptedAfter *time.Time
+	if value, ok := params["accepted_after"]; ok {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("jira extension: invalid accepted_after: %s", err)
+		}
+
+		t := time.Unix(0, ms*int64(time.Millisecond))
+		acceptedAfter = &t
+		jql = append(jql, fmt.Sprintf(`updated >= "%s"`, t.In(b.location).Format(jqlTimeLayout)))
+	}
+
+	path := "rest/api/2/search"
+	if b.boardID != 0 {
+		path = fmt.Sprintf("rest/agile/1.0/board/%d/issue", b.boardID)
+	}
+
+	fields := []string{"summary", "status", "labels", "assignee", "issuetype", "created"}
+	for _, f := range []string{b.flagField, b.estimateField} {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("jql", strings.Join(jql, " AND ")+" ORDER BY Rank ASC")
+	query.Set("fields", strings.Join(fields, ","))
+	query.Set("expand", "changelog")
+	query.Set("maxResults", strconv.Itoa(pageSize))
+
+	issues := []*issue{}
+	for {
+		query.Set("startAt", strconv.Itoa(len(issues)))
+
+		var resp searchResponse
+		err := b.get(ctx, path+"?"+query.Encode(), &resp)
+		if err != nil {
+			return err
+		}
+
+		issues = append(issues, resp.Issues...)
+
+		if len(resp.Issues) == 0 || len(issues) >= resp.Total {
+			break
+		}
+	}
+
+	for _, i := range issues {
+		if !i.Changelog.truncated() {
+			continue
+		}
+
+		histories, err := b.fetchChangelog(ctx, i.ID)
+		if err != nil {
+			return err
+		}
+
+		i.Changelog.Histories = histories
+	}
+
+	if acceptedAfter != nil {
+		issues = filterAcceptedAfter(issues, *acceptedAfter)
+	}
+
+	b.issues = issues
+
+	return nil
+}
+
+// fetchChangelog pages through the whole changelog of the issue, for when the
+// search results only expanded the part of it.
+func (b *Board) fetchChangelog(ctx context.Context, id string) ([]history, error) {
+	query := url.Values{}
+	query.Set("maxResults", strconv.Itoa(pageSize))
+
+	histories := []history{}
+	for {
+		query.Set("startAt", strconv.Itoa(len(histories)))
+
+		var page changelogPage
+		err := b.get(ctx, fmt.Sprintf("rest/api/2/issue/%s/changelog?%s", url.PathEscape(id), query.Encode()), &page)
+		if err != nil {
+			return nil, err
+		}
+
+		histories = append(histories, page.Values...)
+
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			break
+		}
+	}
+
+	return histories, nil
+}
+
+// filterAcceptedAfter keeps only the issues which moved into their current
+// status after the given time. The JQL "updated" clause is only a coarse
+// filter, as any edit of an issue bumps it.
+func filterAcceptedAfter(issues []*issue, after time.Time) []*issue {
+	filtered := []*issue{}
+
+	for _, i := range issues {
+		if i.Fields.Status == nil {
+			continue
+		}
+
+		for _, t := range i.transitions() {
+			if strings.EqualFold(t.State, i.Fields.Status.Name) && t.Occurred.After(after) {
+				filtered = append(filtered, i)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// FlattenStories function will take what we have so far and convert it into the
+// rubbernecker standard.
+func (b *Board) FlattenStories() (rubbernecker.Cards, error) {
+	if len(b.issues) == 0 {
+		return nil, fmt.Errorf("jira extension: %w", rubbernecker.ErrNoCards)
+	}
+
+	cards := rubbernecker.Cards{}
+
+	for _, i := range b.issues {
+		stickers := rubbernecker.Stickers{}
+
+		for _, l := range i.Fields.Labels {
+			if sticker, ok := b.stickers.Get(l); ok && !stickers.Has(sticker.Name) {
+				stickers = append(stickers, sticker)
+			}
+		}
+
+		if b.flagField != "" && i.Fields.flagged(b.flagField) && !stickers.Has("blocked") {
+			if sticker, ok := b.stickers.Get("blocked"); ok {
+				sticker.Title = "Flagged as an impediment"
+				stickers = append(stickers, sticker)
+			}
+		}
+
+		var estimate *float64
+		if b.estimateField != "" {
+			estimate = i.Fields.number(b.estimateField)
+		}
+
+		if estimate != nil && *estimate == 0 {
+			if zeroPointsSticker, ok := b.stickers.Get("zero-points"); ok {
+				stickers = append(stickers, zeroPointsSticker)
+			}
+		}
+
+		sort.Sort(stickers)
+
+		assignees := rubbernecker.Members{}
+		if i.Fields.Assignee != nil {
+			member := i.Fields.Assignee.member()
+			assignees[member.ID] = member
+		}
+
+		state := ""
+		if i.Fields.Status != nil {
+			state = i.Fields.Status.Name
+		}
+
+		var created time.Time
+		if i.Fields.Created != nil {
+			created = i.Fields.Created.Time
+		}
+
+		storyType := ""
+		if i.Fields.IssueType != nil {
+			storyType = strings.ToLower(i.Fields.IssueType.Name)
+		}
+
+		id, err := strconv.Atoi(i.ID)
+		if err != nil {
+			return nil, fmt.Errorf("jira extension: invalid id %q of issue %s: %s", i.ID, i.Key, err)
+		}
+
+		status := b.convertState(state)
+
+		var acceptedAt *time.Time
+		if status == rubbernecker.StatusDone.String() {
+			acceptedAt = calculateEnteredState(i.transitions(), state)
+		}
+
+		cards = append(cards, &rubbernecker.Card{
+			ID:         id,
+			Assignees:  assignees,
+			Elapsed:    calculateInState(i.transitions(), state, created),
+			Status:     status,
+			Stickers:   stickers,
+			Title:      i.Fields.Summary,
+			URL:        b.baseURL + "browse/" + i.Key,
+			StoryType:  storyType,
+			Estimate:   estimate,
+			AcceptedAt: acceptedAt,
+		})
+	}
+
+	return cards, nil
+}
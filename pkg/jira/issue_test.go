@@ -0,0 +1,322 @@
+package jira_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/helpers"
+	"github.com/alphagov/paas-rubbernecker/pkg/jira"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Jira Issues", func() {
+	Context("Board not setup", func() {
+		It("should create a New() board", func() {
+			b, err := jira.New("https://example.atlassian.net", "PAAS", "tester", "test")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).NotTo(BeNil())
+		})
+
+		It("should fail to create a New() board without a project", func() {
+			_, err := jira.New("https://example.atlassian.net", "", "tester", "test")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Board setup", func() {
+		var (
+			b *jira.Board
+
+			apiURL      = `https://example.atlassian.net/rest/api/2/search`
+			boardAPIURL = `https://example.atlassian.net/rest/agile/1.0/board/42/issue`
+			response    = `{"startAt":0,"maxResults":100,"total":1,"issues":[{
+				"id":"10001",
+				"key":"PAAS-1",
+				"fields":{
+					"summary":"Test Rubbernecker",
+					"status":{"name":"In Progress"},
+					"labels":["test","unrelated"],
+					"assignee":{"accountId":"5b10a2844c20165700ede21g","displayName":"Tester","emailAddress":"tester@example.com"},
+					"issuetype":{"name":"Story"},
+					"created":"2023-09-28T14:02:11.000+0000",
+					"customfield_10021":[{"value":"Impediment"}],
+					"customfield_10016":0
+				},
+				"changelog":{"histories":[
+					{"created":"2023-10-02T09:15:00.000+0000","items":[{"field":"status","toString":"In Progress"}]}
+				]}
+			}]}`
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			b, err = jira.New("https://example.atlassian.net/", "PAAS", "tester", "test")
+			httpmock.Activate()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			b.AcceptStickers(rubbernecker.Stickers{
+				rubbernecker.Sticker{
+					Name: "test",
+				},
+				rubbernecker.Sticker{
+					Name: "blocked",
+				},
+				rubbernecker.Sticker{
+					Name: "zero-points",
+				},
+			})
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchCards() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(401, ``))
+
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should FetchCards() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should FetchCards() from a board", func() {
+			httpmock.RegisterResponder("GET", boardAPIURL,
+				httpmock.NewStringResponder(200, response))
+
+			b.UseBoard(42)
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["GET "+boardAPIURL]).To(BeNumerically("==", 1))
+		})
+
+		It("should FetchCards() next page", func() {
+			page1 := `{"startAt":0,"maxResults":1,"total":2,"issues":[{"id":"1","key":"PAAS-1","fields":{"summary":"first","status":{"name":"In Progress"}}}]}`
+			page2 := `{"startAt":1,"maxResults":1,"total":2,"issues":[{"id":"2","key":"PAAS-2","fields":{"summary":"second","status":{"name":"In Progress"}}}]}`
+
+			httpmock.RegisterResponder("GET", apiURL,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, page1),
+					httpmock.NewStringResponder(200, page2),
+				),
+			)
+
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["GET "+apiURL]).To(BeNumerically("==", 2))
+
+			cards, err := b.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(2))
+		})
+
+		It("should fail to FlattenStories() due to faulty API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `{"startAt":0,"maxResults":100,"total":0,"issues":[]}`))
+
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := b.FlattenStories()
+
+			Expect(err).To(HaveOccurred())
+			Expect(cards).To(BeNil())
+		})
+
+		It("should FlattenStories() correctly", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			b.UseFields(jira.DefaultFlagField, "customfield_10016")
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := b.FlattenStories()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(1))
+			Expect(cards[0].ID).To(Equal(10001))
+			Expect(cards[0].Title).To(Equal("Test Rubbernecker"))
+			Expect(cards[0].Status).To(Equal("doing"))
+			Expect(cards[0].URL).To(Equal("https://example.atlassian.net/browse/PAAS-1"))
+			Expect(cards[0].StoryType).To(Equal("story"))
+			Expect(cards[0].Elapsed).To(BeNumerically(">", 1))
+			Expect(*cards[0].Estimate).To(Equal(0.0))
+			Expect(cards[0].Assignees).To(HaveLen(1))
+
+			for _, a := range cards[0].Assignees {
+				Expect(a.Name).To(Equal("Tester"))
+			}
+
+			Expect(cards[0].Stickers).To(HaveLen(3))
+			Expect(cards[0].Stickers.Has("test")).To(BeTrue())
+			Expect(cards[0].Stickers.Has("zero-points")).To(BeTrue())
+
+			blocked, ok := cards[0].Stickers.Get("blocked")
+			Expect(ok).To(BeTrue())
+			Expect(blocked.Title).To(Equal("Flagged as an impediment"))
+		})
+
+		It("should not add a blocked sticker when flag field is disabled", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			b.UseFields("", "")
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := b.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards[0].Stickers.Has("blocked")).To(BeFalse())
+			Expect(cards[0].Estimate).To(BeNil())
+		})
+
+		It("should not FetchCards() for a status no Jira status is mapped onto", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			b.MapStatuses(map[string]rubbernecker.Status{"In Progress": rubbernecker.StatusDoing})
+			err := b.FetchCards(context.Background(), rubbernecker.StatusApproval, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["GET "+apiURL]).To(BeNumerically("==", 0))
+		})
+
+		It("should fail to FlattenStories() with an invalid issue id", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `{"startAt":0,"maxResults":100,"total":1,"issues":[{"id":"PAAS-1","key":"PAAS-1","fields":{"summary":"first","status":{"name":"In Progress"}}}]}`))
+
+			err := b.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := b.FlattenStories()
+			Expect(err).To(MatchError(ContainSubstring(`invalid id "PAAS-1"`)))
+			Expect(cards).To(BeNil())
+		})
+
+		DescribeTable("should FetchCards() updated after given time in the time zone of Jira",
+			func(location *time.Location, updated string) {
+				var jql string
+
+				httpmock.RegisterResponder("GET", apiURL, func(req *http.Request) (*http.Response, error) {
+					jql = req.URL.Query().Get("jql")
+
+					return httpmock.NewStringResponse(200, `{"startAt":0,"maxResults":100,"total":0,"issues":[]}`), nil
+				})
+
+				if location != nil {
+					b.UseLocation(location)
+				}
+
+				past := time.Date(2023, 10, 1, 23, 30, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+				err := b.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+					"accepted_after": fmt.Sprintf("%d", past),
+				})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jql).To(ContainSubstring(`updated >= "` + updated + `"`))
+			},
+			Entry("UTC by default", nil, "2023/10/01 23:30"),
+			Entry("a configured zone", time.FixedZone("CEST", 2*60*60), "2023/10/02 01:30"),
+		)
+
+		It("should FetchCards() with the custom JQL without its ORDER BY clause", func() {
+			var jql string
+
+			httpmock.RegisterResponder("GET", apiURL, func(req *http.Request) (*http.Response, error) {
+				jql = req.URL.Query().Get("jql")
+
+				return httpmock.NewStringResponse(200, `{"startAt":0,"maxResults":100,"total":0,"issues":[]}`), nil
+			})
+
+			b.UseJQL(`project = PAAS AND labels = "ordered" order by created DESC`)
+			err := b.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jql).To(HavePrefix(`(project = PAAS AND labels = "ordered") AND status in (`))
+			Expect(jql).To(HaveSuffix(" ORDER BY Rank ASC"))
+			Expect(strings.Count(strings.ToUpper(jql), "ORDER BY")).To(Equal(1))
+		})
+
+		It("should FetchCards() done issues accepted after given time from the whole changelog", func() {
+			changelogURL := "https://example.atlassian.net/rest/api/2/issue/1/changelog"
+
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `{"startAt":0,"maxResults":100,"total":1,"issues":[
+					{"id":"1","key":"PAAS-1","fields":{"summary":"long history","status":{"name":"Done"}},
+					 "changelog":{"startAt":0,"maxResults":1,"total":3,"histories":[{"created":"2023-09-01T16:00:00.000+0000","items":[{"field":"status","toString":"Started"}]}]}}
+				]}`))
+			httpmock.RegisterResponder("GET", changelogURL, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Query().Get("startAt") == "0" {
+					return httpmock.NewStringResponse(200, `{"startAt":0,"maxResults":2,"total":3,"isLast":false,"values":[
+						{"created":"2023-09-01T16:00:00.000+0000","items":[{"field":"status","toString":"Started"}]},
+						{"created":"2023-09-02T16:00:00.000+0000","items":[{"field":"summary","toString":"long history"}]}
+					]}`), nil
+				}
+
+				return httpmock.NewStringResponse(200, `{"startAt":2,"maxResults":2,"total":3,"isLast":true,"values":[
+					{"created":"2023-10-04T16:00:00.000+0000","items":[{"field":"status","toString":"Done"}]}
+				]}`), nil
+			})
+
+			past := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+			err := b.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+				"accepted_after": fmt.Sprintf("%d", past),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["GET "+changelogURL]).To(Equal(2))
+
+			cards, err := b.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(1))
+			Expect(*cards[0].AcceptedAt).To(BeTemporally("==", time.Date(2023, 10, 4, 16, 0, 0, 0, time.UTC)))
+		})
+
+		It("should FetchCards() done issues accepted after given time", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `{"startAt":0,"maxResults":100,"total":2,"issues":[
+					{"id":"1","key":"PAAS-1","fields":{"summary":"recent","status":{"name":"Done"}},
+					 "changelog":{"histories":[{"created":"2023-10-04T16:00:00.000+0000","items":[{"field":"status","toString":"Done"}]}]}},
+					{"id":"2","key":"PAAS-2","fields":{"summary":"old","status":{"name":"Done"}},
+					 "changelog":{"histories":[{"created":"2023-09-01T16:00:00.000+0000","items":[{"field":"status","toString":"Done"}]}]}}
+				]}`))
+
+			past := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+			err := b.FetchCards(context.Background(), rubbernecker.StatusDone, map[string]string{
+				"accepted_after": fmt.Sprintf("%d", past),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := b.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).To(HaveLen(1))
+			Expect(cards[0].Title).To(Equal("recent"))
+			Expect(cards[0].Status).To(Equal("done"))
+			Expect(*cards[0].AcceptedAt).To(BeTemporally("==", time.Date(2023, 10, 4, 16, 0, 0, 0, time.UTC)))
+		})
+	})
+})
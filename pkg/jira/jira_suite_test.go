@@ -0,0 +1,13 @@
+package jira_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJira(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rubbernecker Jira Extension Suite")
+}
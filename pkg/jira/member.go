@@ -0,0 +1,59 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// FetchMembers will contact the Jira API to get the list of users who can be
+// assigned issues in the project.
+func (b *Board) FetchMembers(ctx context.Context) error {
+	query := url.Values{}
+	query.Set("project", b.project)
+	query.Set("maxResults", strconv.Itoa(pageSize))
+
+	users := []*user{}
+	for {
+		query.Set("startAt", strconv.Itoa(len(users)))
+
+		var page []*user
+		err := b.get(ctx, "rest/api/2/user/assignable/search?"+query.Encode(), &page)
+		if err != nil {
+			return err
+		}
+
+		users = append(users, page...)
+
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	b.users = users
+
+	return nil
+}
+
+// FlattenMembers will convert the Jira users into rubbernecker members.
+func (b *Board) FlattenMembers() (rubbernecker.Members, error) {
+	if len(b.users) == 0 {
+		return nil, fmt.Errorf("jira extension: no members to be flattened")
+	}
+
+	members := rubbernecker.Members{}
+
+	for _, u := range b.users {
+		if u.Active != nil && !*u.Active {
+			continue
+		}
+
+		member := u.member()
+		members[member.ID] = member
+	}
+
+	return members, nil
+}
@@ -0,0 +1,80 @@
+package jira_test
+
+import (
+	"context"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/jira"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Jira Members", func() {
+	Context("Board setup", func() {
+		var (
+			b rubbernecker.MemberService
+
+			apiURL   = `https://example.atlassian.net/rest/api/2/user/assignable/search`
+			response = `[{"accountId":"1","displayName":"non-tester","active":false},{"accountId":"2","displayName":"tester","emailAddress":"tester@example.com","active":true}]`
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			b, err = jira.New("https://example.atlassian.net", "PAAS", "tester", "test")
+			httpmock.Activate()
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchMembers() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(404, ``))
+
+			err := b.FetchMembers(context.Background())
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FlattenMembers() due to faulty API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `[]`))
+
+			err := b.FetchMembers(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+
+			members, err := b.FlattenMembers()
+
+			Expect(err).To(HaveOccurred())
+			Expect(members).To(BeNil())
+		})
+
+		It("should FlattenMembers() correctly", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			err := b.FetchMembers(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+
+			members, err := b.FlattenMembers()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(1))
+
+			for id, m := range members {
+				Expect(m.ID).To(Equal(id))
+				Expect(m.Name).To(Equal("tester"))
+				Expect(m.Email).To(Equal("tester@example.com"))
+			}
+		})
+	})
+})
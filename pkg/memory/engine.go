@@ -1,23 +1,42 @@
 package memory
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
 
 // SetupEngine should compose the storage.
 func SetupEngine() *Engine {
 	return &Engine{
-		storage: map[string]interface{}{},
+		storage: map[string]entry{},
 	}
 }
 
-// Engine module configuration.
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Engine module configuration. It is safe for concurrent use.
 type Engine struct {
-	storage map[string]interface{}
+	mu      sync.Mutex
+	storage map[string]entry
 }
 
 // Get a specific value from a memory store.
 func (e *Engine) Get(key string) (interface{}, error) {
-	if value, ok := e.storage[key]; ok {
-		return value, nil
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if entry, ok := e.lookup(key, time.Now()); ok {
+		return entry.value, nil
 	}
 
 	return nil, fmt.Errorf("memory: key not found in storage")
@@ -25,7 +44,93 @@ func (e *Engine) Get(key string) (interface{}, error) {
 
 // Put specific value into a memory store.
 func (e *Engine) Put(key string, value interface{}) error {
-	e.storage[key] = value
+	return e.PutWithTTL(key, value, 0)
+}
+
+// PutWithTTL will put specific value into a memory store, for it to expire
+// after the given time. Values with no TTL never expire. This is how the
+// previous snapshots of the board are expired when kept in memory.
+func (e *Engine) PutWithTTL(key string, value interface{}, ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("memory: invalid TTL %s", ttl)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry := entry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	e.storage[key] = entry
 
 	return nil
 }
+
+// Delete specific value from a memory store. Deleting a key which does not
+// exist is not an error.
+func (e *Engine) Delete(key string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.storage, key)
+
+	return nil
+}
+
+// Keys lists all the keys in a memory store, sorted.
+func (e *Engine) Keys() ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	keys := []string{}
+
+	for key := range e.storage {
+		if _, ok := e.lookup(key, now); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// List all the values in a memory store with keys starting with the prefix.
+func (e *Engine) List(prefix string) (map[string]interface{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	values := map[string]interface{}{}
+
+	for key := range e.storage {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if entry, ok := e.lookup(key, now); ok {
+			values[key] = entry.value
+		}
+	}
+
+	return values, nil
+}
+
+// lookup returns the entry unless it has expired, in which case it is removed.
+// It should be called with the lock held.
+func (e *Engine) lookup(key string, now time.Time) (entry, bool) {
+	entry, ok := e.storage[key]
+	if !ok {
+		return entry, false
+	}
+
+	if entry.expired(now) {
+		delete(e.storage, key)
+		return entry, false
+	}
+
+	return entry, true
+}
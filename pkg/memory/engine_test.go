@@ -1,6 +1,10 @@
 package memory_test
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/alphagov/paas-rubbernecker/pkg/memory"
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
 	. "github.com/onsi/ginkgo/v2"
@@ -40,4 +44,112 @@ var _ = Describe("Memory Engine", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(value).To(BeNil())
 	})
+
+	Context("Engine has been setup", func() {
+		var (
+			engine *memory.Engine
+		)
+
+		BeforeEach(func() {
+			engine = memory.SetupEngine()
+
+			Expect(engine.Put("snapshot.2", 2)).To(Succeed())
+			Expect(engine.Put("snapshot.1", 1)).To(Succeed())
+			Expect(engine.Put("members", 3)).To(Succeed())
+		})
+
+		It("should Delete() value successfully", func() {
+			Expect(engine.Delete("snapshot.1")).To(Succeed())
+			Expect(engine.Delete("snapshot.1")).To(Succeed())
+
+			_, err := engine.Get("snapshot.1")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should list the sorted Keys()", func() {
+			keys, err := engine.Keys()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(Equal([]string{"members", "snapshot.1", "snapshot.2"}))
+		})
+
+		It("should List() the values with the prefix", func() {
+			values, err := engine.List("snapshot.")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[string]interface{}{"snapshot.1": 1, "snapshot.2": 2}))
+		})
+
+		It("should expire the value PutWithTTL()", func() {
+			Expect(engine.PutWithTTL("snapshot.3", 3, 50*time.Millisecond)).To(Succeed())
+
+			value, err := engine.Get("snapshot.3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(3))
+
+			Eventually(func() error {
+				_, err := engine.Get("snapshot.3")
+				return err
+			}).Should(HaveOccurred())
+
+			Expect(engine.Keys()).NotTo(ContainElement("snapshot.3"))
+			Expect(engine.List("snapshot.")).NotTo(HaveKey("snapshot.3"))
+		})
+
+		It("should not expire the value once Put() again without TTL", func() {
+			Expect(engine.PutWithTTL("members", 4, time.Nanosecond)).To(Succeed())
+			Expect(engine.Put("members", 5)).To(Succeed())
+
+			Consistently(func() (interface{}, error) {
+				return engine.Get("members")
+			}).Should(Equal(5))
+		})
+
+		It("should expire the snapshots saved through it", func() {
+			var e rubbernecker.PersistanceEngine = engine
+
+			_, ok := e.(rubbernecker.ExpiringPersistanceEngine)
+			Expect(ok).To(BeTrue())
+
+			snapshot := &rubbernecker.Snapshot{ETag: time.Unix(1539856800, 0)}
+			Expect(rubbernecker.SaveSnapshot(e, snapshot, 50*time.Millisecond)).To(Succeed())
+
+			Expect(engine.Keys()).To(ContainElement("snapshot.1539856800"))
+			Eventually(engine.Keys).ShouldNot(ContainElement("snapshot.1539856800"))
+			Expect(engine.Keys()).To(ContainElement(rubbernecker.LatestSnapshotKey))
+		})
+
+		It("should fail to PutWithTTL() with negative TTL", func() {
+			Expect(engine.PutWithTTL("members", 4, -time.Second)).NotTo(Succeed())
+		})
+
+		It("should be safe to use concurrently", func() {
+			wg := sync.WaitGroup{}
+
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					key := fmt.Sprintf("concurrent.%d", i)
+
+					for j := 0; j < 100; j++ {
+						Expect(engine.PutWithTTL(key, j, time.Millisecond)).To(Succeed())
+						Expect(engine.Put("shared", j)).To(Succeed())
+						_, _ = engine.Get(key)
+						_, _ = engine.Get("shared")
+						_, _ = engine.Keys()
+						_, _ = engine.List("concurrent.")
+						Expect(engine.Delete(key)).To(Succeed())
+					}
+				}(i)
+			}
+
+			wg.Wait()
+
+			Expect(engine.List("concurrent.")).To(BeEmpty())
+		})
+	})
 })
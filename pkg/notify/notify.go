@@ -0,0 +1,22 @@
+package notify
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// Notifier interface will establish a standard for anything telling the team
+// about the events happening on the board.
+type Notifier interface {
+	Notify(rubbernecker.Event) error
+}
+
+// Log will tell about the events in the logs only.
+type Log struct{}
+
+// Notify will log the event.
+func (Log) Notify(event rubbernecker.Event) error {
+	log.WithField("event", event.Kind).Info(event.Text)
+	return nil
+}
@@ -0,0 +1,13 @@
+package notify_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rubbernecker Notify Suite")
+}
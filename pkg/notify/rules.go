@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// StuckRule will tell about the cards which have been in the column for more
+// than the working days. It is disabled with no working days.
+type StuckRule struct {
+	Column      string `yaml:"column"`
+	WorkingDays int    `yaml:"working_days"`
+}
+
+// Rules will describe which of the events the team should be told about.
+type Rules struct {
+	Rejected       bool      `yaml:"rejected"`
+	OverLimit      bool      `yaml:"over_limit"`
+	Stuck          StuckRule `yaml:"stuck"`
+	SupportChanged bool      `yaml:"support_changed"`
+}
+
+// DefaultRules are the rules unless configured otherwise, telling about all
+// of the events and the cards in doing for more than 3 working days.
+func DefaultRules() Rules {
+	return Rules{
+		Rejected:       true,
+		OverLimit:      true,
+		Stuck:          StuckRule{Column: rubbernecker.StatusDoing.String(), WorkingDays: 3},
+		SupportChanged: true,
+	}
+}
+
+// Validate will make sure the column of the stuck rule is in the workflow.
+func (r Rules) Validate(workflow rubbernecker.Workflow) error {
+	if r.Stuck.WorkingDays < 0 {
+		return fmt.Errorf("notify: stuck rule has negative working days")
+	}
+
+	if r.Stuck.WorkingDays > 0 {
+		if _, ok := workflow.Column(r.Stuck.Column); !ok {
+			return fmt.Errorf("notify: stuck rule column %q is not in the workflow", r.Stuck.Column)
+		}
+	}
+
+	return nil
+}
+
+// Watcher will compare every new version of the board with the previous one,
+// and tell the notifier about the events the rules ask for. The support roles
+// are named after their labels in the rota.
+type Watcher struct {
+	Rules    Rules
+	Workflow rubbernecker.Workflow
+	Rota     rubbernecker.Rota
+	Notifier Notifier
+}
+
+// Watch will keep notifying about the events until the updates are closed.
+// The initial snapshot is what the first update is compared with, and every
+// update with the one handled before it, so the updates should come from the
+// Board.Feed for none of the events to be missed.
+func (w *Watcher) Watch(updates <-chan *rubbernecker.Snapshot, initial *rubbernecker.Snapshot) {
+	previous := initial
+
+	for snapshot := range updates {
+		for _, event := range w.Events(previous, snapshot, time.Now()) {
+			if err := w.Notifier.Notify(event); err != nil {
+				log.Error(err)
+			}
+		}
+
+		previous = snapshot
+	}
+}
+
+// Events will find what has happened between the two versions of the board.
+// Nothing is told about the cards, or the support rota, until they have been
+// fetched once, so that starting up is not mistaken for everything changing.
+func (w *Watcher) Events(previous, current *rubbernecker.Snapshot, at time.Time) []rubbernecker.Event {
+	events := []rubbernecker.Event{}
+
+	if previous.Cards != nil {
+		if w.Rules.Rejected {
+			events = append(events, w.rejectedEvents(previous.Cards, current.Cards, at)...)
+		}
+
+		if w.Rules.OverLimit {
+			breached, recovered := w.Workflow.Breaches(current.Cards).Compare(w.Workflow.Breaches(previous.Cards))
+			events = append(events, rubbernecker.LimitEvents(breached, recovered, at)...)
+		}
+
+		if w.Rules.Stuck.WorkingDays > 0 {
+			events = append(events, stuckEvents(w.Rules.Stuck, previous.Cards, current.Cards, at)...)
+		}
+	}
+
+	if previous.Support != nil && w.Rules.SupportChanged {
+		events = append(events, supportEvents(previous.Support, current.Support, w.Rota, at)...)
+	}
+
+	return events
+}
+
+// rejectedEvents tells about the cards moved into the rejected column of the
+// workflow, if it has one.
+func (w *Watcher) rejectedEvents(previous, current rubbernecker.Cards, at time.Time) []rubbernecker.Event {
+	events := []rubbernecker.Event{}
+
+	rejected, ok := w.Workflow.Rejected()
+	if !ok {
+		return events
+	}
+
+	for _, change := range rubbernecker.Diff(previous, current) {
+		if change.Type != rubbernecker.ChangeMoved || change.To != rejected.Name {
+			continue
+		}
+
+		events = append(events, rubbernecker.Event{
+			Kind: rubbernecker.EventCardRejected,
+			At:   at,
+			Text: fmt.Sprintf("%s has been rejected", change.Title),
+			Card: findCard(current, change.Project, change.CardID),
+		})
+	}
+
+	return events
+}
+
+func stuckEvents(rule StuckRule, previous, current rubbernecker.Cards, at time.Time) []rubbernecker.Event {
+	events := []rubbernecker.Event{}
+
+	for _, card := range current.Filter(rule.Column) {
+		if card.Elapsed <= rule.WorkingDays {
+			continue
+		}
+
+		before := findCard(previous, card.Project, card.ID)
+		if before != nil && before.Status == card.Status && before.Elapsed > rule.WorkingDays {
+			continue
+		}
+
+		events = append(events, rubbernecker.Event{
+			Kind: rubbernecker.EventCardStuck,
+			At:   at,
+			Text: fmt.Sprintf("%s has been in %s for %d working days", card.Title, rule.Column, card.Elapsed),
+			Card: card,
+		})
+	}
+
+	return events
+}
+
+// supportEvents tells about the support rota changing. Nobody being known to
+// be on support, as before the rota has been fetched, is not worth telling.
+func supportEvents(previous, current rubbernecker.SupportRota, rota rubbernecker.Rota, at time.Time) []rubbernecker.Event {
+	events := []rubbernecker.Event{}
+
+	types := make([]string, 0, len(current))
+	for t := range current {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		before, now := previous[t], current[t]
+		if before == nil || now == nil || before.Member == "-" || before.Member == now.Member {
+			continue
+		}
+
+		events = append(events, rubbernecker.Event{
+			Kind: rubbernecker.EventSupportChanged,
+			At:   at,
+			Text: fmt.Sprintf("%s is now on %s support, taking over from %s", now.Member, rota.Label(t), before.Member),
+		})
+	}
+
+	return events
+}
+
+func findCard(cards rubbernecker.Cards, project string, id int) *rubbernecker.Card {
+	for _, card := range cards {
+		if card.Project == project && card.ID == id {
+			return card
+		}
+	}
+
+	return nil
+}
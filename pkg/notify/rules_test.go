@@ -0,0 +1,224 @@
+package notify_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/notify"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+type testNotifier struct {
+	events []rubbernecker.Event
+	err    error
+}
+
+func (n *testNotifier) Notify(event rubbernecker.Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func kinds(events []rubbernecker.Event) []string {
+	tmp := []string{}
+	for _, e := range events {
+		tmp = append(tmp, e.Kind)
+	}
+	return tmp
+}
+
+var _ = Describe("Rules", func() {
+	var (
+		at       time.Time
+		notifier *testNotifier
+		watcher  *notify.Watcher
+		previous *rubbernecker.Snapshot
+	)
+
+	BeforeEach(func() {
+		at = time.Unix(1539856800, 0)
+		notifier = &testNotifier{}
+		watcher = &notify.Watcher{
+			Rules:    notify.DefaultRules(),
+			Workflow: rubbernecker.DefaultWorkflow(),
+			Notifier: notifier,
+		}
+		previous = &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix the build", Status: "approving"},
+				{ID: 2, Title: "Upgrade the database", Status: "doing", Elapsed: 3},
+			},
+			Support: rubbernecker.SupportRota{
+				"in-hours":     {Type: "in-hours", Member: "Alice"},
+				"out-of-hours": {Type: "out-of-hours", Member: "Bob"},
+			},
+		}
+	})
+
+	It("should Validate() the DefaultRules()", func() {
+		Expect(notify.DefaultRules().Validate(rubbernecker.DefaultWorkflow())).To(Succeed())
+	})
+
+	It("should fail to Validate() the rules which are invalid", func() {
+		rules := notify.Rules{Stuck: notify.StuckRule{Column: "in-qa", WorkingDays: 2}}
+		Expect(rules.Validate(rubbernecker.DefaultWorkflow())).To(MatchError(ContainSubstring(`"in-qa" is not in the workflow`)))
+
+		rules = notify.Rules{Stuck: notify.StuckRule{Column: "doing", WorkingDays: -1}}
+		Expect(rules.Validate(rubbernecker.DefaultWorkflow())).To(MatchError(ContainSubstring("negative working days")))
+	})
+
+	It("should find no Events() when nothing has changed", func() {
+		Expect(watcher.Events(previous, previous, at)).To(BeEmpty())
+	})
+
+	It("should find the Events() the rules ask for", func() {
+		current := &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix the build", Status: "rejected", URL: "https://example.com/1"},
+				{ID: 2, Title: "Upgrade the database", Status: "doing", Elapsed: 4},
+			},
+			Support: rubbernecker.SupportRota{
+				"in-hours":     {Type: "in-hours", Member: "Carol"},
+				"out-of-hours": {Type: "out-of-hours", Member: "Bob"},
+			},
+		}
+
+		events := watcher.Events(previous, current, at)
+
+		Expect(kinds(events)).To(Equal([]string{
+			rubbernecker.EventCardRejected,
+			rubbernecker.EventCardStuck,
+			rubbernecker.EventSupportChanged,
+		}))
+		Expect(events[0].Text).To(Equal("Fix the build has been rejected"))
+		Expect(events[0].Card.URL).To(Equal("https://example.com/1"))
+		Expect(events[0].At).To(Equal(at))
+		Expect(events[1].Text).To(Equal("Upgrade the database has been in doing for 4 working days"))
+		Expect(events[2].Text).To(Equal("Carol is now on in-hours support, taking over from Alice"))
+
+		Expect(watcher.Events(current, current, at)).To(BeEmpty())
+	})
+
+	It("should find the Events() of the support roles named after their labels in the rota", func() {
+		watcher.Rota = rubbernecker.DefaultRota()
+		previous.Support["in-hours-comms"] = &rubbernecker.Support{Type: "in-hours-comms", Member: "Dave"}
+
+		current := &rubbernecker.Snapshot{
+			Cards: previous.Cards,
+			Support: rubbernecker.SupportRota{
+				"in-hours":       {Type: "in-hours", Member: "Carol"},
+				"in-hours-comms": {Type: "in-hours-comms", Member: "Erin"},
+				"out-of-hours":   {Type: "out-of-hours", Member: "Bob"},
+			},
+		}
+
+		events := watcher.Events(previous, current, at)
+
+		Expect(kinds(events)).To(Equal([]string{rubbernecker.EventSupportChanged, rubbernecker.EventSupportChanged}))
+		Expect(events[0].Text).To(Equal("Carol is now on In hours support, taking over from Alice"))
+		Expect(events[1].Text).To(Equal("Erin is now on Comms (In hours) support, taking over from Dave"))
+	})
+
+	It("should find the Events() of the cards moved into the rejected column of the workflow", func() {
+		watcher.Workflow = rubbernecker.Workflow{
+			{Name: "doing", States: []string{"started"}},
+			{Name: "rework", States: []string{"rejected"}},
+			{Name: "approving", States: []string{"delivered"}},
+			{Name: "done", States: []string{"accepted"}},
+		}
+		watcher.Rules.Stuck.WorkingDays = 0
+		watcher.Rules.SupportChanged = false
+
+		current := &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix the build", Status: "rework"},
+				{ID: 2, Title: "Upgrade the database", Status: "rejected"},
+			},
+		}
+
+		events := watcher.Events(previous, current, at)
+
+		Expect(kinds(events)).To(Equal([]string{rubbernecker.EventCardRejected}))
+		Expect(events[0].Text).To(Equal("Fix the build has been rejected"))
+	})
+
+	It("should find the Events() of the limits", func() {
+		current := &rubbernecker.Snapshot{Cards: rubbernecker.Cards{}}
+		for i := 0; i < 5; i++ {
+			current.Cards = append(current.Cards, &rubbernecker.Card{ID: i, Status: "reviewing"})
+		}
+
+		events := watcher.Events(previous, current, at)
+		Expect(kinds(events)).To(Equal([]string{rubbernecker.EventLimitBreached}))
+
+		events = watcher.Events(current, previous, at)
+		Expect(kinds(events)).To(Equal([]string{rubbernecker.EventLimitRecovered}))
+	})
+
+	It("should only find the Events() the rules ask for", func() {
+		watcher.Rules = notify.Rules{}
+		current := &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix the build", Status: "rejected"},
+				{ID: 2, Title: "Upgrade the database", Status: "doing", Elapsed: 4},
+			},
+			Support: rubbernecker.SupportRota{
+				"in-hours": {Type: "in-hours", Member: "Carol"},
+			},
+		}
+
+		Expect(watcher.Events(previous, current, at)).To(BeEmpty())
+	})
+
+	It("should find no Events() until the board has been fetched", func() {
+		empty := &rubbernecker.Snapshot{
+			Support: rubbernecker.SupportRota{
+				"in-hours": {Type: "in-hours", Member: "-"},
+			},
+		}
+		current := &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix the build", Status: "rejected"},
+				{ID: 2, Title: "Upgrade the database", Status: "doing", Elapsed: 4},
+			},
+			Support: rubbernecker.SupportRota{
+				"in-hours": {Type: "in-hours", Member: "Carol"},
+			},
+		}
+
+		Expect(watcher.Events(empty, current, at)).To(BeEmpty())
+	})
+
+	It("should Watch() the Feed() of the board without missing any of the events", func() {
+		board := rubbernecker.NewBoard()
+		board.PublishCards(previous.Cards, nil)
+		feed, stop := board.Feed()
+		defer stop()
+
+		board.PublishCards(rubbernecker.Cards{{ID: 1, Title: "Fix the build", Status: "rejected"}}, nil)
+		board.PublishCards(rubbernecker.Cards{{ID: 1, Title: "Fix the build", Status: "doing"}}, nil)
+
+		updates := make(chan *rubbernecker.Snapshot, 2)
+		updates <- <-feed
+		updates <- <-feed
+		close(updates)
+
+		watcher.Watch(updates, previous)
+
+		Expect(kinds(notifier.events)).To(ContainElement(rubbernecker.EventCardRejected))
+	})
+
+	It("should Watch() the updates and Notify() about the events", func() {
+		notifier.err = fmt.Errorf("test: slack is down")
+		updates := make(chan *rubbernecker.Snapshot, 2)
+		updates <- &rubbernecker.Snapshot{Cards: rubbernecker.Cards{{ID: 1, Title: "Fix the build", Status: "rejected"}}}
+		updates <- &rubbernecker.Snapshot{Cards: rubbernecker.Cards{{ID: 1, Title: "Fix the build", Status: "doing"}}}
+		close(updates)
+
+		watcher.Watch(updates, previous)
+
+		Expect(kinds(notifier.events)).To(Equal([]string{rubbernecker.EventCardRejected}))
+	})
+})
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// Slack will post the events to the channel of the Slack incoming webhook.
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// NewSlack will compose the Slack notifier posting to the incoming webhook.
+func NewSlack(url string) (*Slack, error) {
+	if url == "" {
+		return nil, fmt.Errorf("notify: slack webhook url is required")
+	}
+
+	return &Slack{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify will post the event, linking the card if there is one.
+func (s *Slack) Notify(event rubbernecker.Event) error {
+	text := slackEscaper.Replace(event.Text)
+	if event.Card != nil && event.Card.URL != "" {
+		text = fmt.Sprintf("%s <%s|#%d>", text, event.Card.URL, event.Card.ID)
+	}
+
+	body, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notify: could not post to slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("notify: slack responded with %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/notify"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Slack", func() {
+	var (
+		server   *httptest.Server
+		requests []string
+		status   int
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		status = http.StatusOK
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			message := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&message)
+			requests = append(requests, r.Header.Get("Content-Type")+" "+message["text"])
+
+			w.WriteHeader(status)
+			w.Write([]byte("invalid_payload"))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should fail to compose NewSlack() without the url", func() {
+		_, err := notify.NewSlack("")
+
+		Expect(err).To(MatchError(ContainSubstring("url is required")))
+	})
+
+	It("should Notify() the incoming webhook", func() {
+		slack, err := notify.NewSlack(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		slack.Client = &http.Client{Transport: &http.Transport{}}
+
+		err = slack.Notify(rubbernecker.Event{
+			Kind: rubbernecker.EventCardRejected,
+			Text: "Fix <the> build & deploy has been rejected",
+			Card: &rubbernecker.Card{ID: 123, URL: "https://example.com/123"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{
+			"application/json Fix &lt;the&gt; build &amp; deploy has been rejected <https://example.com/123|#123>",
+		}))
+	})
+
+	It("should fail to Notify() when the incoming webhook fails", func() {
+		status = http.StatusBadRequest
+		slack, err := notify.NewSlack(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		slack.Client = &http.Client{Transport: &http.Transport{}}
+
+		err = slack.Notify(rubbernecker.Event{Text: "test"})
+
+		Expect(err).To(MatchError("notify: slack responded with 400: invalid_payload"))
+	})
+})
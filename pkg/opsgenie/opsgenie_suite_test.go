@@ -0,0 +1,13 @@
+package opsgenie_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOpsgenie(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rubbernecker Opsgenie Suite")
+}
@@ -0,0 +1,185 @@
+package opsgenie
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// DefaultBaseURL is the address of the Opsgenie API, other than for the
+// accounts hosted in the EU, which use https://api.eu.opsgenie.com instead.
+const DefaultBaseURL = "https://api.opsgenie.com"
+
+type schedule struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type schedulesResponse struct {
+	Data []schedule `json:"data"`
+}
+
+type recipient struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type period struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Type      string    `json:"type"`
+	Recipient recipient `json:"recipient"`
+}
+
+type rotation struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Periods []period `json:"periods"`
+}
+
+type timelineResponse struct {
+	Data struct {
+		FinalTimeline struct {
+			Rotations []rotation `json:"rotations"`
+		} `json:"finalTimeline"`
+	} `json:"data"`
+}
+
+type timeline struct {
+	schedule  schedule
+	rotations []rotation
+}
+
+// Schedule will hold some internal and external information, such as client
+// and the timelines of all the schedules. The timelines are fetched for the
+// Horizon ahead.
+type Schedule struct {
+	Horizon time.Duration
+
+	client    *http.Client
+	baseURL   string
+	apiKey    string
+	timelines []timeline
+}
+
+// New will compose a Schedule struct ready to use by the rubbernecker. The
+// baseURL is the address of the Opsgenie API, such as the DefaultBaseURL, and
+// the apiKey is the key of an API integration allowed to read the schedules.
+func New(baseURL, apiKey string) (*Schedule, error) {
+	if _, err := url.Parse(baseURL); err != nil || baseURL == "" {
+		return nil, fmt.Errorf("opsgenie extension: invalid base URL %q", baseURL)
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("opsgenie extension: API key is required")
+	}
+
+	return &Schedule{
+		Horizon: 14 * 24 * time.Hour,
+		client:  http.DefaultClient,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+	}, nil
+}
+
+// FetchSupport will make calls to the Opsgenie API obtaining the final
+// timeline of each of the enabled schedules and storing them in the Schedule
+// for future use.
+func (o *Schedule) FetchSupport(ctx context.Context) error {
+	schedules := schedulesResponse{}
+	if err := o.get(ctx, "/v2/schedules", &schedules); err != nil {
+		return err
+	}
+
+	days := int(o.Horizon / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+
+	query := url.Values{
+		"identifierType": {"id"},
+		"interval":       {fmt.Sprint(days)},
+		"intervalUnit":   {"days"},
+		"date":           {time.Now().UTC().Format(time.RFC3339)},
+	}
+
+	timelines := []timeline{}
+	for _, s := range schedules.Data {
+		if !s.Enabled {
+			continue
+		}
+
+		t := timelineResponse{}
+		if err := o.get(ctx, "/v2/schedules/"+url.PathEscape(s.ID)+"/timeline?"+query.Encode(), &t); err != nil {
+			return err
+		}
+
+		timelines = append(timelines, timeline{schedule: s, rotations: t.Data.FinalTimeline.Rotations})
+	}
+
+	o.timelines = timelines
+
+	return nil
+}
+
+// FlattenSupport should convert the stored timelines from Opsgenie into
+// rubbernecker compatible SupportRota. The periods of all the rotations of each
+// of the schedules are kept as the shifts.
+func (o *Schedule) FlattenSupport() (rubbernecker.SupportRota, error) {
+	support := rubbernecker.SupportRota{}
+	now := time.Now()
+
+	for _, t := range o.timelines {
+		shifts := []rubbernecker.Shift{}
+
+		for _, r := range t.rotations {
+			for _, p := range r.Periods {
+				if p.Recipient.Name == "" {
+					continue
+				}
+
+				start, end := p.StartDate, p.EndDate
+				shifts = append(shifts, rubbernecker.Shift{
+					Member: p.Recipient.Name,
+					Start:  &start,
+					End:    &end,
+				})
+			}
+		}
+
+		support[t.schedule.Name] = rubbernecker.NewSupport(t.schedule.Name, t.schedule.ID, shifts, now)
+	}
+
+	return support, nil
+}
+
+func (o *Schedule) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", o.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		return refresher.NewResponseError(resp, fmt.Errorf("opsgenie extension: unexpected response code %d", resp.StatusCode))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
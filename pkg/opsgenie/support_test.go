@@ -0,0 +1,121 @@
+package opsgenie_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/opsgenie"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Opsgenie", func() {
+	Context("Schedule not setup", func() {
+		It("should create a New() schedule", func() {
+			og, err := opsgenie.New(opsgenie.DefaultBaseURL, "test")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(og).NotTo(BeNil())
+		})
+
+		It("should fail to create a New() schedule without the API key", func() {
+			_, err := opsgenie.New(opsgenie.DefaultBaseURL, "")
+
+			Expect(err).To(MatchError(ContainSubstring("API key is required")))
+		})
+
+		It("should fail to create a New() schedule without the base URL", func() {
+			_, err := opsgenie.New("", "test")
+
+			Expect(err).To(MatchError(ContainSubstring("invalid base URL")))
+		})
+	})
+
+	Context("Schedule setup", func() {
+		var (
+			og rubbernecker.SupportService
+
+			schedulesURL = `https://api.opsgenie.com/v2/schedules`
+			timelineURL  = `https://api.opsgenie.com/v2/schedules/PABC123/timeline`
+			schedules    = `{"data":[
+				{"id":"PABC123","name":"In hours","enabled":true},
+				{"id":"PDEF456","name":"Disabled","enabled":false}
+			]}`
+
+			now      = time.Now().UTC().Truncate(time.Second)
+			at       = func(hours int) string { return now.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+			timeline = fmt.Sprintf(`{"data":{"finalTimeline":{"rotations":[
+				{"id":"R1","name":"Weekly","periods":[
+					{"startDate":%q,"endDate":%q,"type":"default","recipient":{"id":"U1","type":"user","name":"alice@example.com"}},
+					{"startDate":%q,"endDate":%q,"type":"override","recipient":{"id":"U2","type":"user","name":"bob@example.com"}},
+					{"startDate":%q,"endDate":%q,"type":"default","recipient":{}}
+				]}
+			]}}}`, at(-6), at(2), at(2), at(26), at(26), at(50))
+		)
+
+		BeforeEach(func() {
+			var err error
+			og, err = opsgenie.New(opsgenie.DefaultBaseURL, "test")
+			Expect(err).NotTo(HaveOccurred())
+
+			httpmock.Activate()
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchSupport() from an API", func() {
+			httpmock.RegisterResponder("GET", schedulesURL,
+				httpmock.NewStringResponder(401, `{"message":"Could not authenticate"}`))
+
+			err := og.FetchSupport(context.Background())
+
+			Expect(err).To(MatchError(ContainSubstring("unexpected response code 401")))
+		})
+
+		It("should FetchSupport() the timelines of the enabled schedules", func() {
+			httpmock.RegisterResponder("GET", schedulesURL,
+				func(req *http.Request) (*http.Response, error) {
+					Expect(req.Header.Get("Authorization")).To(Equal("GenieKey test"))
+
+					return httpmock.NewStringResponse(200, schedules), nil
+				})
+			httpmock.RegisterResponder("GET", timelineURL,
+				func(req *http.Request) (*http.Response, error) {
+					Expect(req.URL.Query().Get("interval")).To(Equal("14"))
+					Expect(req.URL.Query().Get("intervalUnit")).To(Equal("days"))
+
+					return httpmock.NewStringResponse(200, timeline), nil
+				})
+
+			err := og.FetchSupport(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpmock.GetCallCountInfo()["GET "+timelineURL]).To(Equal(1))
+		})
+
+		It("should FlattenSupport() correctly", func() {
+			httpmock.RegisterResponder("GET", schedulesURL, httpmock.NewStringResponder(200, schedules))
+			httpmock.RegisterResponder("GET", timelineURL, httpmock.NewStringResponder(200, timeline))
+
+			Expect(og.FetchSupport(context.Background())).To(Succeed())
+
+			support, err := og.FlattenSupport()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(support).To(HaveLen(1))
+			Expect(support["In hours"].ScheduleID).To(Equal("PABC123"))
+			Expect(support["In hours"].Member).To(Equal("alice@example.com"))
+			Expect(support["In hours"].Until.Format(time.RFC3339)).To(Equal(at(2)))
+			Expect(support["In hours"].Next.Member).To(Equal("bob@example.com"))
+			Expect(support["In hours"].Shifts).To(HaveLen(2))
+		})
+	})
+})
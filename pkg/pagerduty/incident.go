@@ -0,0 +1,92 @@
+package pagerduty
+
+import (
+	"context"
+
+	pd "github.com/PagerDuty/go-pagerduty"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+// Incidents will hold some internal and external information, such as client
+// and the incidents of the services, which are yet to be resolved.
+type Incidents struct {
+	Client     *pd.Client
+	ServiceIDs []string
+	content    []pd.Incident
+}
+
+// NewIncidents will create an instance of Incidents and prefill it with the
+// PagerDuty client, fetching the incidents of the services with the IDs.
+func NewIncidents(token string, serviceIDs []string) *Incidents {
+	return &Incidents{
+		Client:     pd.NewClient(token),
+		ServiceIDs: serviceIDs,
+	}
+}
+
+// FetchIncidents will make a call to the PagerDuty API obtaining the triggered
+// and acknowledged incidents and storing them for future use. Same as with
+// FetchSupport, the context is only checked in between the pages.
+func (p *Incidents) FetchIncidents(ctx context.Context) error {
+	opts := pd.ListIncidentsOptions{
+		APIListObject: pd.APIListObject{
+			Limit:  100,
+			Offset: 0,
+		},
+		Statuses:   []string{"triggered", "acknowledged"},
+		ServiceIDs: p.ServiceIDs,
+	}
+
+	var content []pd.Incident
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := p.Client.ListIncidents(opts)
+		if err != nil {
+			return err
+		}
+
+		content = append(content, res.Incidents...)
+		if !res.More {
+			break
+		}
+		opts.Offset = opts.Offset + opts.Limit
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.content = content
+
+	return nil
+}
+
+// FlattenIncidents should convert the stored response from PagerDuty into
+// rubbernecker compatible Incidents.
+func (p *Incidents) FlattenIncidents() (rubbernecker.Incidents, error) {
+	incidents := rubbernecker.Incidents{}
+
+	for _, i := range p.content {
+		assignees := []string{}
+		for _, a := range i.Assignments {
+			assignees = append(assignees, a.Assignee.Summary)
+		}
+
+		incidents = append(incidents, &rubbernecker.Incident{
+			ID:        i.ID,
+			Number:    int(i.IncidentNumber),
+			Title:     i.Summary,
+			Service:   i.Service.Summary,
+			Status:    i.Status,
+			Urgency:   i.Urgency,
+			Assignees: assignees,
+			URL:       i.HTMLURL,
+			CreatedAt: parseTime(i.CreatedAt),
+		})
+	}
+
+	return incidents, nil
+}
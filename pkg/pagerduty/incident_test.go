@@ -0,0 +1,123 @@
+package pagerduty_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/helpers"
+	"github.com/alphagov/paas-rubbernecker/pkg/pagerduty"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Incidents", func() {
+	Context("Incidents not setup", func() {
+		It("should create NewIncidents()", func() {
+			pd := pagerduty.NewIncidents("test", []string{"PSVC123"})
+
+			Expect(pd).NotTo(BeNil())
+			Expect(pd.ServiceIDs).To(Equal([]string{"PSVC123"}))
+		})
+	})
+
+	Context("Incidents setup", func() {
+		var (
+			pd rubbernecker.IncidentService
+
+			apiURL   = `https://api.pagerduty.com/incidents`
+			response = `{"incidents":[{
+				"id":"PINC123",
+				"summary":"[#42] Router is down",
+				"html_url":"https://example.pagerduty.com/incidents/PINC123",
+				"incident_number":42,
+				"created_at":"2018-03-07T09:30:00Z",
+				"status":"acknowledged",
+				"urgency":"high",
+				"service":{"id":"PSVC123","summary":"Router"},
+				"assignments":[{"at":"2018-03-07T09:31:00Z","assignee":{"id":"PUSR123","summary":"Alice"}}]
+			}]}`
+		)
+
+		BeforeEach(func() {
+			pd = pagerduty.NewIncidents("test", []string{"PSVC123", "PSVC456"})
+			httpmock.Activate()
+
+			Expect(pd).NotTo(BeNil())
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to FetchIncidents() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(404, ``))
+
+			err := pd.FetchIncidents(context.Background())
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should FetchIncidents() of the services which are not resolved", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				func(req *http.Request) (*http.Response, error) {
+					query := req.URL.Query()
+					Expect(query["statuses[]"]).To(Equal([]string{"triggered", "acknowledged"}))
+					Expect(query["service_ids[]"]).To(Equal([]string{"PSVC123", "PSVC456"}))
+
+					return httpmock.NewStringResponse(200, response), nil
+				})
+
+			err := pd.FetchIncidents(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should FetchIncidents() next page", func() {
+			response1 := `{"incidents":[{"id":"PINC1"}],"limit":1,"offset":0,"more":true}`
+			response2 := `{"incidents":[{"id":"PINC2"}],"limit":1,"offset":1,"more":false}`
+
+			httpmock.RegisterResponder("GET", apiURL,
+				helpers.NewCycleResponder(
+					httpmock.NewStringResponder(200, response1),
+					httpmock.NewStringResponder(200, response2),
+				),
+			)
+
+			Expect(pd.FetchIncidents(context.Background())).To(Succeed())
+			Expect(httpmock.GetCallCountInfo()["GET "+apiURL]).To(BeNumerically("==", 2))
+
+			incidents, err := pd.FlattenIncidents()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(incidents).To(HaveLen(2))
+		})
+
+		It("should FlattenIncidents() correctly", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			Expect(pd.FetchIncidents(context.Background())).To(Succeed())
+
+			incidents, err := pd.FlattenIncidents()
+			Expect(err).NotTo(HaveOccurred())
+
+			created := time.Date(2018, 3, 7, 9, 30, 0, 0, time.UTC)
+			Expect(incidents).To(Equal(rubbernecker.Incidents{{
+				ID:        "PINC123",
+				Number:    42,
+				Title:     "[#42] Router is down",
+				Service:   "Router",
+				Status:    "acknowledged",
+				Urgency:   "high",
+				Assignees: []string{"Alice"},
+				URL:       "https://example.pagerduty.com/incidents/PINC123",
+				CreatedAt: &created,
+			}}))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package pagerduty
 
 import (
+	"context"
 	"time"
 
 	pd "github.com/PagerDuty/go-pagerduty"
@@ -8,34 +9,47 @@ import (
 )
 
 // Schedule will hold some internal and external information, such as client and
-// contents of the call.
+// contents of the call. The on-calls are fetched for the Horizon ahead.
 type Schedule struct {
 	Client  *pd.Client
+	Horizon time.Duration
 	content []pd.OnCall
 }
 
 // New will create an instance of a Schedule and prefill it with the PagerDuty
-// client.
+// client, fetching the on-calls for the next 14 days.
 func New(token string) *Schedule {
 	return &Schedule{
-		Client: pd.NewClient(token),
+		Client:  pd.NewClient(token),
+		Horizon: 14 * 24 * time.Hour,
 	}
 }
 
 // FetchSupport will make a call to the PagerDuty API obtaining the response and
-// storing it in the Schedule for future use.
-func (p *Schedule) FetchSupport() error {
+// storing it in the Schedule for future use. The PagerDuty client does not take
+// the context, so it is only checked in between the pages.
+func (p *Schedule) FetchSupport(ctx context.Context) error {
+	horizon := p.Horizon
+	if horizon <= 0 {
+		horizon = 24 * time.Hour
+	}
+
+	now := time.Now()
 	opts := pd.ListOnCallOptions{
 		APIListObject: pd.APIListObject{
 			Limit:  100,
 			Offset: 0,
 		},
-		Since: time.Now().String(),
-		Until: time.Now().Add(24 * time.Hour).String(),
+		Since: now.Format(time.RFC3339),
+		Until: now.Add(horizon).Format(time.RFC3339),
 	}
 
 	var content []pd.OnCall
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		res, err := p.Client.ListOnCalls(opts)
 		if err != nil {
 			return err
@@ -48,26 +62,85 @@ func (p *Schedule) FetchSupport() error {
 		opts.Offset = opts.Offset + opts.Limit
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	p.content = content
 
 	return nil
 }
 
+// shiftKey identifies the on-call, which is listed once for each of the
+// escalation policies the schedule is part of.
+type shiftKey struct {
+	schedule string
+	userID   string
+	user     string
+	start    string
+	end      string
+}
+
 // FlattenSupport should convert the stored response from PagerDuty and convert
-// it into rubbernecker compatible SupportRota.
+// it into rubbernecker compatible SupportRota. All of the on-calls of each of
+// the schedules are kept as the shifts, only once each, at the lowest of the
+// escalation levels they are listed at.
 func (p *Schedule) FlattenSupport() (rubbernecker.SupportRota, error) {
 	support := rubbernecker.SupportRota{}
+	schedules := []string{}
+	ids := map[string]string{}
+	shifts := map[string][]rubbernecker.Shift{}
+	seen := map[shiftKey]int{}
 
 	for _, oncall := range p.content {
-		if _, ok := support[oncall.Schedule.Summary]; oncall.Schedule.Summary == "" || ok {
+		summary := oncall.Schedule.Summary
+		if summary == "" {
 			continue
 		}
 
-		support[oncall.Schedule.Summary] = &rubbernecker.Support{
-			Type:   oncall.Schedule.Summary,
-			Member: oncall.User.Summary,
+		if _, ok := shifts[summary]; !ok {
+			schedules = append(schedules, summary)
+			ids[summary] = oncall.Schedule.ID
+		}
+
+		key := shiftKey{
+			schedule: summary,
+			userID:   oncall.User.ID,
+			user:     oncall.User.Summary,
+			start:    oncall.Start,
+			end:      oncall.End,
+		}
+		if i, ok := seen[key]; ok {
+			if level := int(oncall.EscalationLevel); level < shifts[summary][i].EscalationLevel {
+				shifts[summary][i].EscalationLevel = level
+			}
+			continue
 		}
+		seen[key] = len(shifts[summary])
+
+		shifts[summary] = append(shifts[summary], rubbernecker.Shift{
+			Member:          oncall.User.Summary,
+			Start:           parseTime(oncall.Start),
+			End:             parseTime(oncall.End),
+			EscalationLevel: int(oncall.EscalationLevel),
+		})
+	}
+
+	now := time.Now()
+	for _, summary := range schedules {
+		support[summary] = rubbernecker.NewSupport(summary, ids[summary], shifts[summary], now)
 	}
 
 	return support, nil
 }
+
+// parseTime reads the time of the on-call, which is not set for the ones on
+// call indefinitely.
+func parseTime(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+
+	return &t
+}
@@ -1,6 +1,10 @@
 package pagerduty_test
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	httpmock "gopkg.in/jarcoal/httpmock.v1"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -43,7 +47,7 @@ var _ = Describe("PagerDuty", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(404, ``))
 
-			err := pd.FetchSupport()
+			err := pd.FetchSupport(context.Background())
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -52,7 +56,7 @@ var _ = Describe("PagerDuty", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pd.FetchSupport()
+			err := pd.FetchSupport(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -78,7 +82,7 @@ var _ = Describe("PagerDuty", func() {
 				),
 			)
 
-			err := pd.FetchSupport()
+			err := pd.FetchSupport(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(httpmock.GetCallCountInfo()["GET "+apiURL]).To(BeNumerically("==", 2))
@@ -88,7 +92,7 @@ var _ = Describe("PagerDuty", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pd.FetchSupport()
+			err := pd.FetchSupport(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -99,5 +103,69 @@ var _ = Describe("PagerDuty", func() {
 			Expect(len(s)).To(Equal(1))
 			Expect(s["test"].Member).To(Equal("tester"))
 		})
+
+		It("should FlattenSupport() into the shifts of the schedule", func() {
+			now := time.Now().UTC()
+			times := func(hours int) string {
+				return now.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)
+			}
+			resp := fmt.Sprintf(`{"oncalls":[
+				{"user":{"summary":"tester2"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":1},
+				{"user":{"summary":"tester1"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":1},
+				{"user":{"summary":"manager"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":2}
+			]}`, times(6), times(30), times(-18), times(6), times(-18), times(300))
+			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, resp))
+
+			Expect(pd.FetchSupport(context.Background())).To(Succeed())
+
+			support, err := pd.FlattenSupport()
+			Expect(err).NotTo(HaveOccurred())
+
+			s := support["test"]
+			Expect(s.ScheduleID).To(Equal("PABC123"))
+			Expect(s.Member).To(Equal("tester1"))
+			Expect(s.Until.Format(time.RFC3339)).To(Equal(times(6)))
+			Expect(s.Next.Member).To(Equal("tester2"))
+			Expect(s.Shifts).To(HaveLen(3))
+			Expect(s.Shifts[2].EscalationLevel).To(Equal(2))
+		})
+
+		It("should FlattenSupport() the shifts listed for several escalation policies only once", func() {
+			now := time.Now().UTC()
+			times := func(hours int) string {
+				return now.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)
+			}
+			resp := fmt.Sprintf(`{"oncalls":[
+				{"user":{"id":"PUSER1","summary":"tester1"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":2},
+				{"user":{"id":"PUSER1","summary":"tester1"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":1},
+				{"user":{"id":"PUSER2","summary":"tester2"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":1},
+				{"user":{"id":"PUSER1","summary":"tester1"},"schedule":{"id":"PABC123","summary":"test"},"start":%q,"end":%q,"escalation_level":1}
+			]}`, times(-18), times(6), times(-18), times(6), times(6), times(30), times(30), times(54))
+			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, resp))
+
+			Expect(pd.FetchSupport(context.Background())).To(Succeed())
+
+			support, err := pd.FlattenSupport()
+			Expect(err).NotTo(HaveOccurred())
+
+			s := support["test"]
+			Expect(s.Member).To(Equal("tester1"))
+			Expect(s.Shifts).To(HaveLen(3))
+			Expect(s.Shifts[0].Member).To(Equal("tester1"))
+			Expect(s.Shifts[0].EscalationLevel).To(Equal(1))
+			Expect(s.Shifts[1].Member).To(Equal("tester2"))
+			Expect(s.Shifts[2].Member).To(Equal("tester1"))
+		})
+
+		It("should FetchSupport() for the horizon of the schedule", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			Expect(pd.FetchSupport(context.Background())).To(Succeed())
+
+			req := httpmock.GetCallCountInfo()
+			Expect(req["GET "+apiURL]).To(Equal(1))
+			Expect(pd.(*pagerduty.Schedule).Horizon).To(Equal(14 * 24 * time.Hour))
+		})
 	})
 })
@@ -1,12 +1,15 @@
 package pivotal
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
 	pt "github.com/salsita/go-pivotaltracker/v5/pivotal"
 )
@@ -49,6 +52,10 @@ type transition struct {
 	Occurred time.Time `json:"occurred_at,omitempty"`
 }
 
+type iteration struct {
+	Start *time.Time `json:"start,omitempty"`
+}
+
 type member struct {
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
@@ -82,6 +89,84 @@ func calculateInState(transitions []transition, state string) int {
 	return calculateWorkingDays(m.Occurred, time.Now())
 }
 
+// calculateFlow will work out how the story went through the states. The time
+// in the current state is not taken into account, as it would keep changing
+// with every fetch. Stories which have never moved have no flow.
+func calculateFlow(workflow rubbernecker.Workflow, s *story) *rubbernecker.Flow {
+	if len(s.Transitions) == 0 {
+		return nil
+	}
+
+	transitions := append([]transition{}, s.Transitions...)
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].Occurred.Before(transitions[j].Occurred)
+	})
+
+	inState := map[string]time.Duration{}
+	var started, accepted time.Time
+
+	for i, t := range transitions {
+		switch t.State {
+		case pt.StoryStateStarted:
+			if started.IsZero() {
+				started = t.Occurred
+			}
+		case pt.StoryStateAccepted:
+			accepted = t.Occurred
+		}
+
+		if i+1 < len(transitions) && isInPlay(workflow, t.State) {
+			inState[convertState(workflow, t.State)] += transitions[i+1].Occurred.Sub(t.Occurred)
+		}
+	}
+
+	flow := &rubbernecker.Flow{
+		InState: map[string]float64{},
+	}
+
+	for state, d := range inState {
+		flow.InState[state] = rubbernecker.Hours(d)
+	}
+
+	if s.State != pt.StoryStateAccepted || accepted.IsZero() {
+		return flow
+	}
+
+	if s.CreatedAt != nil {
+		lead := rubbernecker.Hours(accepted.Sub(*s.CreatedAt))
+		flow.LeadTime = &lead
+	}
+
+	if !started.IsZero() {
+		cycle := rubbernecker.Hours(accepted.Sub(started))
+		flow.CycleTime = &cycle
+	}
+
+	return flow
+}
+
+// calculateAcceptedAt will find when an accepted story has last been accepted.
+func calculateAcceptedAt(s *story) *time.Time {
+	if s.State != pt.StoryStateAccepted {
+		return nil
+	}
+
+	var accepted *time.Time
+	for i, t := range s.Transitions {
+		if t.State == pt.StoryStateAccepted && (accepted == nil || t.Occurred.After(*accepted)) {
+			accepted = &s.Transitions[i].Occurred
+		}
+	}
+
+	return accepted
+}
+
+func isInPlay(workflow rubbernecker.Workflow, state string) bool {
+	column, ok := workflow.Convert(state)
+
+	return ok && column.InPlay
+}
+
 func calculateWorkingDays(since, until time.Time) int {
 	days := 0
 
@@ -100,58 +185,44 @@ func calculateWorkingDays(since, until time.Time) int {
 	return days
 }
 
-func composeState(status rubbernecker.Status) string {
-	var state string
-
-	switch status {
-	case rubbernecker.StatusScheduled:
-		state = strings.Join([]string{
-			pt.StoryStateUnstarted,
-			pt.StoryStatePlanned,
-		}, ",")
-	case rubbernecker.StatusDoing:
-		state = pt.StoryStateStarted
-	case rubbernecker.StatusReviewal:
-		state = pt.StoryStateFinished
-	case rubbernecker.StatusApproval:
-		state = pt.StoryStateDelivered
-	case rubbernecker.StatusRejected:
-		state = pt.StoryStateRejected
-	case rubbernecker.StatusDone:
-		state = pt.StoryStateAccepted
-	default:
-		state = strings.Join([]string{
-			pt.StoryStateUnstarted,
-			pt.StoryStatePlanned,
-			pt.StoryStateStarted,
-			pt.StoryStateFinished,
-			pt.StoryStateDelivered,
-			pt.StoryStateRejected,
-		}, ",")
-	}
-
-	return state
-}
-
-func convertState(state string) string {
-	switch state {
-	case pt.StoryStateStarted:
-		return rubbernecker.StatusDoing.String()
-	case pt.StoryStateFinished:
-		return rubbernecker.StatusReviewal.String()
-	case pt.StoryStateDelivered:
-		return rubbernecker.StatusApproval.String()
-	case pt.StoryStateAccepted:
-		return rubbernecker.StatusDone.String()
-	case pt.StoryStateRejected:
-		return rubbernecker.StatusRejected.String()
-	case pt.StoryStateUnstarted:
-		return rubbernecker.StatusScheduled.String()
-	case pt.StoryStatePlanned:
-		return rubbernecker.StatusScheduled.String()
-	default:
-		return "unknown"
+// pivotalStates are the states PivotalTracker can be asked for.
+var pivotalStates = []string{
+	pt.StoryStateUnstarted,
+	pt.StoryStatePlanned,
+	pt.StoryStateStarted,
+	pt.StoryStateFinished,
+	pt.StoryStateDelivered,
+	pt.StoryStateRejected,
+	pt.StoryStateAccepted,
+}
+
+// composeState lists the PivotalTracker states which should be requested for
+// the given rubbernecker status. The states of the workflow PivotalTracker is
+// not aware of are skipped.
+func composeState(workflow rubbernecker.Workflow, status rubbernecker.Status) string {
+	states := []string{}
+
+	for _, state := range workflow.States(status) {
+		state = strings.ToLower(strings.TrimSpace(state))
+		for _, known := range pivotalStates {
+			if state == known {
+				states = append(states, state)
+				break
+			}
+		}
+	}
+
+	return strings.Join(states, ",")
+}
+
+// convertState finds the column of the workflow the PivotalTracker state is
+// mapped into.
+func convertState(workflow rubbernecker.Workflow, state string) string {
+	if column, ok := workflow.Convert(state); ok {
+		return column.Name
 	}
+
+	return "unknown"
 }
 
 func convertBlockersToStickers(blockers []blocker, availableStickers rubbernecker.Stickers) rubbernecker.Stickers {
@@ -252,3 +323,19 @@ func parseScheduledDate(preposition string, date string, minDate time.Time) (tim
 
 	return result, nil
 }
+
+// get will request the path of the PivotalTracker API and decode the response
+// into v, giving up once the context is done.
+func (t *Tracker) get(ctx context.Context, path string, v interface{}) error {
+	req, err := t.client.NewRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req.WithContext(ctx), v)
+	if err != nil {
+		return refresher.NewResponseError(resp, err)
+	}
+
+	return nil
+}
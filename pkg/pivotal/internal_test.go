@@ -12,6 +12,8 @@ import (
 )
 
 var _ = Describe("Pivotal internal functionality", func() {
+	workflow := rubbernecker.DefaultWorkflow()
+
 	It("should calculateWorkingDays() correctly", func() {
 		days := calculateWorkingDays(time.Date(2017, 10, 30, 12, 0, 0, 0, time.Local), time.Date(2017, 11, 1, 12, 0, 0, 0, time.Local))
 
@@ -68,14 +70,74 @@ var _ = Describe("Pivotal internal functionality", func() {
 		Expect(calculateInState(t, "started")).To(Equal(calculateWorkingDays(t[3].Occurred, time.Now())))
 	})
 
+	It("should not calculateFlow() without transitions", func() {
+		Expect(calculateFlow(workflow, &story{State: "unstarted"})).To(BeNil())
+	})
+
+	It("should calculateFlow() of a story in play", func() {
+		now := time.Now()
+		flow := calculateFlow(workflow, &story{
+			State: "finished",
+			Transitions: []transition{
+				{State: "finished", Occurred: now.Add(-2 * time.Hour)},
+				{State: "started", Occurred: now.Add(-5 * time.Hour)},
+			},
+		})
+
+		Expect(flow.InState).To(Equal(map[string]float64{"doing": 3}))
+		Expect(flow.LeadTime).To(BeNil())
+		Expect(flow.CycleTime).To(BeNil())
+	})
+
+	It("should calculateFlow() of an accepted story which has been rejected", func() {
+		created := time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)
+		flow := calculateFlow(workflow, &story{
+			State:     "accepted",
+			CreatedAt: &created,
+			Transitions: []transition{
+				{State: "started", Occurred: created.Add(24 * time.Hour)},
+				{State: "finished", Occurred: created.Add(30 * time.Hour)},
+				{State: "delivered", Occurred: created.Add(31 * time.Hour)},
+				{State: "rejected", Occurred: created.Add(32 * time.Hour)},
+				{State: "started", Occurred: created.Add(48 * time.Hour)},
+				{State: "finished", Occurred: created.Add(50 * time.Hour)},
+				{State: "delivered", Occurred: created.Add(50*time.Hour + 30*time.Minute)},
+				{State: "accepted", Occurred: created.Add(52 * time.Hour)},
+			},
+		})
+
+		Expect(flow.InState).To(Equal(map[string]float64{
+			"doing":     8,
+			"reviewing": 1.5,
+			"approving": 2.5,
+			"rejected":  16,
+		}))
+		Expect(*flow.LeadTime).To(Equal(52.0))
+		Expect(*flow.CycleTime).To(Equal(28.0))
+	})
+
+	It("should calculateAcceptedAt() of an accepted story only", func() {
+		first := time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)
+		last := first.Add(48 * time.Hour)
+		transitions := []transition{
+			{State: "accepted", Occurred: first},
+			{State: "started", Occurred: first.Add(time.Hour)},
+			{State: "accepted", Occurred: last},
+		}
+
+		Expect(*calculateAcceptedAt(&story{State: "accepted", Transitions: transitions})).To(Equal(last))
+		Expect(calculateAcceptedAt(&story{State: "accepted"})).To(BeNil())
+		Expect(calculateAcceptedAt(&story{State: "started", Transitions: transitions})).To(BeNil())
+	})
+
 	It("should composeState() correctly", func() {
-		all := composeState(rubbernecker.StatusAll)
-		todo := composeState(rubbernecker.StatusScheduled)
-		play := composeState(rubbernecker.StatusDoing)
-		revi := composeState(rubbernecker.StatusReviewal)
-		appr := composeState(rubbernecker.StatusApproval)
-		reje := composeState(rubbernecker.StatusRejected)
-		done := composeState(rubbernecker.StatusDone)
+		all := composeState(workflow, rubbernecker.StatusAll)
+		todo := composeState(workflow, rubbernecker.StatusScheduled)
+		play := composeState(workflow, rubbernecker.StatusDoing)
+		revi := composeState(workflow, rubbernecker.StatusReviewal)
+		appr := composeState(workflow, rubbernecker.StatusApproval)
+		reje := composeState(workflow, rubbernecker.StatusRejected)
+		done := composeState(workflow, rubbernecker.StatusDone)
 
 		Expect(all).To(Equal("unstarted,planned,started,finished,delivered,rejected"))
 		Expect(todo).To(Equal(fmt.Sprintf("%s,%s", pivotal.StoryStateUnstarted, pivotal.StoryStatePlanned)))
@@ -86,13 +148,39 @@ var _ = Describe("Pivotal internal functionality", func() {
 		Expect(done).To(Equal(pivotal.StoryStateAccepted))
 	})
 
-	It("should convertState() correctly", func() {
-		Expect(convertState(pivotal.StoryStatePlanned)).To(Equal("next"))
-		Expect(convertState(pivotal.StoryStateStarted)).To(Equal("doing"))
-		Expect(convertState(pivotal.StoryStateFinished)).To(Equal("reviewing"))
-		Expect(convertState(pivotal.StoryStateDelivered)).To(Equal("approving"))
-		Expect(convertState(pivotal.StoryStateRejected)).To(Equal("rejected"))
-		Expect(convertState(pivotal.StoryStateAccepted)).To(Equal("done"))
-		Expect(convertState("testing")).To(Equal("unknown"))
+	It("should convertState(workflow, ) correctly", func() {
+		Expect(convertState(workflow, pivotal.StoryStatePlanned)).To(Equal("next"))
+		Expect(convertState(workflow, pivotal.StoryStateStarted)).To(Equal("doing"))
+		Expect(convertState(workflow, pivotal.StoryStateFinished)).To(Equal("reviewing"))
+		Expect(convertState(workflow, pivotal.StoryStateDelivered)).To(Equal("approving"))
+		Expect(convertState(workflow, pivotal.StoryStateRejected)).To(Equal("rejected"))
+		Expect(convertState(workflow, pivotal.StoryStateAccepted)).To(Equal("done"))
+		Expect(convertState(workflow, "testing")).To(Equal("unknown"))
+	})
+
+	It("should composeState() and convertState() with a custom workflow", func() {
+		custom := rubbernecker.Workflow{
+			{Name: "doing", States: []string{"Started", "finished"}, InPlay: true},
+			{Name: "in-qa", States: []string{"delivered", "In QA"}, InPlay: true},
+			{Name: "done", States: []string{"accepted"}},
+		}
+
+		Expect(composeState(custom, rubbernecker.StatusAll)).To(Equal("started,finished,delivered"))
+		Expect(composeState(custom, rubbernecker.StatusDone)).To(Equal("accepted"))
+		Expect(convertState(custom, pivotal.StoryStateFinished)).To(Equal("doing"))
+		Expect(convertState(custom, pivotal.StoryStateDelivered)).To(Equal("in-qa"))
+		Expect(convertState(custom, pivotal.StoryStateRejected)).To(Equal("unknown"))
+
+		flow := calculateFlow(custom, &story{
+			State: "accepted",
+			Transitions: []transition{
+				{State: "started", Occurred: time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)},
+				{State: "finished", Occurred: time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)},
+				{State: "delivered", Occurred: time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)},
+				{State: "accepted", Occurred: time.Date(2018, 10, 1, 15, 0, 0, 0, time.UTC)},
+			},
+		})
+
+		Expect(flow.InState).To(Equal(map[string]float64{"doing": 3, "in-qa": 3}))
 	})
 })
@@ -0,0 +1,25 @@
+package pivotal
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CurrentIterationStart will contact the PivotalTracker API to find out when
+// the current iteration of the project has started.
+func (t *Tracker) CurrentIterationStart(ctx context.Context) (time.Time, error) {
+	path := fmt.Sprintf("projects/%d/iterations?scope=current&fields=start", t.projectID)
+
+	iterations := []*iteration{}
+	err := t.get(ctx, path, &iterations)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(iterations) == 0 || iterations[0].Start == nil {
+		return time.Time{}, fmt.Errorf("pivotal extension: no current iteration")
+	}
+
+	return *iterations[0].Start, nil
+}
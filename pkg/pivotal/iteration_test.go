@@ -0,0 +1,65 @@
+package pivotal_test
+
+import (
+	"context"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/pivotal"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Pivotal Iterations", func() {
+	Context("Tracker setup", func() {
+		var (
+			pt rubbernecker.IterationService
+
+			apiURL = `https://www.pivotaltracker.com/services/v5/projects/123/iterations?scope=current&fields=start`
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			pt, err = pivotal.New(123, "test")
+			httpmock.Activate()
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			httpmock.DeactivateAndReset()
+		})
+
+		It("should fail to get the CurrentIterationStart() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(404, ``))
+
+			_, err := pt.CurrentIterationStart(context.Background())
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to get the CurrentIterationStart() when there is no iteration", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `[]`))
+
+			_, err := pt.CurrentIterationStart(context.Background())
+
+			Expect(err).To(MatchError(ContainSubstring("no current iteration")))
+		})
+
+		It("should get the CurrentIterationStart() from an API", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, `[{"start":"2018-10-15T07:00:00Z"}]`))
+
+			start, err := pt.CurrentIterationStart(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(start).To(Equal(time.Date(2018, 10, 15, 7, 0, 0, 0, time.UTC)))
+		})
+	})
+})
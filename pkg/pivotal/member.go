@@ -1,25 +1,23 @@
 package pivotal
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
 )
 
 // FetchMembers will contact the PivotalTracker API to get the list of members.
-func (t *Tracker) FetchMembers() error {
+func (t *Tracker) FetchMembers(ctx context.Context) error {
 	path := fmt.Sprintf("projects/%d/memberships", t.projectID)
 
-	req, err := t.client.NewRequest("GET", path, nil)
+	members := []*membership{}
+	err := t.get(ctx, path, &members)
 	if err != nil {
 		return err
 	}
 
-	t.members = []*membership{}
-	_, err = t.client.Do(req, &t.members)
-	if err != nil {
-		return err
-	}
+	t.members = members
 
 	return nil
 }
@@ -1,6 +1,8 @@
 package pivotal_test
 
 import (
+	"context"
+
 	httpmock "gopkg.in/jarcoal/httpmock.v1"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -36,7 +38,7 @@ var _ = Describe("Pivotal Members", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(404, ``))
 
-			err := pt.FetchMembers()
+			err := pt.FetchMembers(context.Background())
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -45,7 +47,7 @@ var _ = Describe("Pivotal Members", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchMembers()
+			err := pt.FetchMembers(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -54,7 +56,7 @@ var _ = Describe("Pivotal Members", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, `[]`))
 
-			err := pt.FetchMembers()
+			err := pt.FetchMembers(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -68,7 +70,7 @@ var _ = Describe("Pivotal Members", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchMembers()
+			err := pt.FetchMembers(context.Background())
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -1,6 +1,7 @@
 package pivotal
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,6 +10,8 @@ import (
 	pt "github.com/salsita/go-pivotaltracker/v5/pivotal"
 )
 
+const storyFields = "fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate"
+
 // Tracker will be responsible for acting as the story resource returned
 // by the API.
 type Tracker struct {
@@ -16,6 +19,7 @@ type Tracker struct {
 	projectID int64
 	stories   []*story
 	stickers  rubbernecker.Stickers
+	workflow  rubbernecker.Workflow
 	members   []*membership
 }
 
@@ -25,6 +29,7 @@ func New(projectID int64, token string) (*Tracker, error) {
 		client:    pt.NewClient(token),
 		projectID: projectID,
 		stickers:  rubbernecker.Stickers{},
+		workflow:  rubbernecker.DefaultWorkflow(),
 	}, nil
 }
 
@@ -34,11 +39,15 @@ func (t *Tracker) AcceptStickers(stickers rubbernecker.Stickers) {
 	t.stickers = stickers
 }
 
+// AcceptWorkflow will make a note of the columns of the board, for the states
+// of the stories to be converted into.
+func (t *Tracker) AcceptWorkflow(workflow rubbernecker.Workflow) {
+	t.workflow = workflow
+}
+
 // FetchCards will fetch the stories from PivotalTracker.
-func (t *Tracker) FetchCards(status rubbernecker.Status, params map[string]string) error {
-	p := []string{
-		"fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate",
-	}
+func (t *Tracker) FetchCards(ctx context.Context, status rubbernecker.Status, params map[string]string) error {
+	p := []string{storyFields}
 
 	for key, value := range params {
 		p = append(p, key+"="+value)
@@ -47,21 +56,18 @@ func (t *Tracker) FetchCards(status rubbernecker.Status, params map[string]strin
 	// Trying to prevent errors:
 	// filter: Cannot be used together with any other parameters.
 	if len(params) == 0 {
-		p = append(p, "filter="+fmt.Sprintf("state:%s", composeState(status)))
+		p = append(p, "filter="+fmt.Sprintf("state:%s", composeState(t.workflow, status)))
 	}
 
 	path := fmt.Sprintf("projects/%d/stories?%s", t.projectID, strings.Join(p, "&"))
 
-	req, err := t.client.NewRequest("GET", path, nil)
+	stories := []*story{}
+	err := t.get(ctx, path, &stories)
 	if err != nil {
 		return err
 	}
 
-	t.stories = []*story{}
-	_, err = t.client.Do(req, &t.stories)
-	if err != nil {
-		return err
-	}
+	t.stories = stories
 
 	return nil
 }
@@ -70,55 +76,80 @@ func (t *Tracker) FetchCards(status rubbernecker.Status, params map[string]strin
 // rubbernecker standard.
 func (t *Tracker) FlattenStories() (rubbernecker.Cards, error) {
 	if len(t.stories) == 0 {
-		return nil, fmt.Errorf("pivotal extension: no stories to be flattened")
+		return nil, fmt.Errorf("pivotal extension: %w", rubbernecker.ErrNoCards)
 	}
 
 	stories := rubbernecker.Cards{}
 
 	for _, s := range t.stories {
-		stickers := rubbernecker.Stickers{}
-
-		if s.Estimate != nil {
-			estimate := *s.Estimate
-			if estimate == 0 {
-				if zeroPointsSticker, ok := t.stickers.Get("zero-points"); ok {
-					stickers = append(stickers, zeroPointsSticker)
-				}
-			}
-		}
+		stories = append(stories, t.convert(s))
+	}
+
+	return stories, nil
+}
+
+// FetchCard will fetch a single story from PivotalTracker and convert it into
+// the rubbernecker standard. Only the IDs of the assignees are known.
+func (t *Tracker) FetchCard(ctx context.Context, id int) (*rubbernecker.Card, error) {
+	path := fmt.Sprintf("projects/%d/stories/%d?%s", t.projectID, id, storyFields)
+
+	s := &story{}
+	err := t.get(ctx, path, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.convert(s), nil
+}
 
-		for _, l := range s.Labels {
-			if sticker, ok := t.stickers.Get(l.Name); ok {
-				stickers = append(stickers, sticker)
+// ProjectID is the ID of the PivotalTracker project the Tracker is using.
+func (t *Tracker) ProjectID() int64 {
+	return t.projectID
+}
+
+func (t *Tracker) convert(s *story) *rubbernecker.Card {
+	stickers := rubbernecker.Stickers{}
+
+	if s.Estimate != nil {
+		estimate := *s.Estimate
+		if estimate == 0 {
+			if zeroPointsSticker, ok := t.stickers.Get("zero-points"); ok {
+				stickers = append(stickers, zeroPointsSticker)
 			}
 		}
+	}
 
-		for _, sticker := range convertBlockersToStickers(s.Blockers, t.stickers) {
-			if !stickers.Has(sticker.Name) {
-				stickers = append(stickers, sticker)
-			}
+	for _, l := range s.Labels {
+		if sticker, ok := t.stickers.Get(l.Name); ok {
+			stickers = append(stickers, sticker)
 		}
+	}
 
-		sort.Sort(stickers)
+	for _, sticker := range convertBlockersToStickers(s.Blockers, t.stickers) {
+		if !stickers.Has(sticker.Name) {
+			stickers = append(stickers, sticker)
+		}
+	}
 
-		assignees := rubbernecker.Members{}
+	sort.Sort(stickers)
 
-		for _, id := range s.OwnerIds {
-			assignees[id] = &rubbernecker.Member{ID: id}
-		}
+	assignees := rubbernecker.Members{}
 
-		stories = append(stories, &rubbernecker.Card{
-			ID:        s.ID,
-			Assignees: assignees,
-			Elapsed:   calculateInState(s.Transitions, s.State),
-			Status:    convertState(s.State),
-			Stickers:  stickers,
-			Title:     s.Name,
-			URL:       s.URL,
-			StoryType: s.StoryType,
-			Estimate:  s.Estimate,
-		})
+	for _, id := range s.OwnerIds {
+		assignees[id] = &rubbernecker.Member{ID: id}
 	}
 
-	return stories, nil
+	return &rubbernecker.Card{
+		ID:         s.ID,
+		Assignees:  assignees,
+		Elapsed:    calculateInState(s.Transitions, s.State),
+		Status:     convertState(t.workflow, s.State),
+		Stickers:   stickers,
+		Title:      s.Name,
+		URL:        s.URL,
+		StoryType:  s.StoryType,
+		Estimate:   s.Estimate,
+		Flow:       calculateFlow(t.workflow, s),
+		AcceptedAt: calculateAcceptedAt(s),
+	}
 }
@@ -1,7 +1,9 @@
 package pivotal_test
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	httpmock "gopkg.in/jarcoal/httpmock.v1"
@@ -11,6 +13,7 @@ import (
 	. "github.com/onsi/gomega/gstruct"
 
 	"github.com/alphagov/paas-rubbernecker/pkg/pivotal"
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
 	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
 )
 
@@ -67,7 +70,59 @@ var _ = Describe("Pivotal Stories", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(404, ``))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail to FetchCards() stories while being throttled", func() {
+			throttled := httpmock.NewStringResponse(429, `{"code":"api_rate_limit_exceeded"}`)
+			throttled.Header.Set("Retry-After", "30")
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.ResponderFromResponse(throttled))
+
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
+
+			Expect(err).To(HaveOccurred())
+			Expect(refresher.RetryAfter(err)).To(Equal(30 * time.Second))
+		})
+
+		It("should FetchCards() stories of the accepted workflow", func() {
+			httpmock.RegisterResponder("GET", `https://www.pivotaltracker.com/services/v5/projects/123/stories?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate&filter=state:started,delivered`,
+				httpmock.NewStringResponder(200, `[{"id": 561, "transitions": [], "name": "Test Rubbernecker", "current_state": "delivered"}]`))
+
+			pt.(*pivotal.Tracker).AcceptWorkflow(rubbernecker.Workflow{
+				{Name: "doing", States: []string{"started"}},
+				{Name: "in-qa", States: []string{"delivered"}},
+				{Name: "done", States: []string{"accepted"}},
+			})
+
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cards, err := pt.FlattenStories()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cards[0].Status).To(Equal("in-qa"))
+		})
+
+		It("should FetchCard() a single story from an API", func() {
+			httpmock.RegisterResponder("GET", `https://www.pivotaltracker.com/services/v5/projects/123/stories/561?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate`,
+				httpmock.NewStringResponder(200, `{"id": 561, "transitions": [], "name": "Test Rubbernecker", "current_state": "finished", "url": "http://localhost/story/show/561", "owner_ids": [1234], "labels": [{"name": "test"}]}`))
+
+			card, err := pt.(*pivotal.Tracker).FetchCard(context.Background(), 561)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(card.ID).To(Equal(561))
+			Expect(card.Status).To(Equal("reviewing"))
+			Expect(card.Assignees).To(HaveKey(1234))
+			Expect(card.Stickers).To(HaveLen(1))
+		})
+
+		It("should fail to FetchCard() which does not exist", func() {
+			httpmock.RegisterResponder("GET", `https://www.pivotaltracker.com/services/v5/projects/123/stories/561?fields=owner_ids,blockers,transitions,current_state,labels,name,url,created_at,story_type,estimate`,
+				httpmock.NewStringResponder(404, `{"code": "unfound_resource"}`))
+
+			_, err := pt.(*pivotal.Tracker).FetchCard(context.Background(), 561)
 
 			Expect(err).To(HaveOccurred())
 		})
@@ -76,16 +131,41 @@ var _ = Describe("Pivotal Stories", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should keep the stories if the context is done before FetchCards() returns", func() {
+			httpmock.RegisterResponder("GET", apiURL,
+				httpmock.NewStringResponder(200, response))
+
+			Expect(pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			httpmock.RegisterResponder("GET", apiURL, func(req *http.Request) (*http.Response, error) {
+				if err := req.Context().Err(); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+			Expect(pt.FetchCards(ctx, rubbernecker.StatusDoing, map[string]string{})).To(MatchError(context.Canceled))
+
+			cards, err := pt.FlattenStories()
 
 			Expect(err).NotTo(HaveOccurred())
+			Expect(cards).NotTo(BeEmpty())
 		})
 
 		It("should fail to FlattenStories() due to faulty API", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, `[]`))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -99,7 +179,7 @@ var _ = Describe("Pivotal Stories", func() {
 			httpmock.RegisterResponder("GET", apiURL,
 				httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -121,7 +201,7 @@ var _ = Describe("Pivotal Stories", func() {
 				)
 				httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-				err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+				err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 				Expect(err).NotTo(HaveOccurred())
 
 				cards, err := pt.FlattenStories()
@@ -171,7 +251,7 @@ var _ = Describe("Pivotal Stories", func() {
 				)
 				httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-				err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+				err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 				Expect(err).NotTo(HaveOccurred())
 
 				cards, err := pt.FlattenStories()
@@ -208,7 +288,7 @@ var _ = Describe("Pivotal Stories", func() {
 			)
 			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 			Expect(err).NotTo(HaveOccurred())
 
 			cards, err := pt.FlattenStories()
@@ -225,7 +305,7 @@ var _ = Describe("Pivotal Stories", func() {
 			)
 			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 			Expect(err).NotTo(HaveOccurred())
 
 			cards, err := pt.FlattenStories()
@@ -245,7 +325,7 @@ var _ = Describe("Pivotal Stories", func() {
 			)
 			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 			Expect(err).NotTo(HaveOccurred())
 
 			cards, err := pt.FlattenStories()
@@ -262,7 +342,7 @@ var _ = Describe("Pivotal Stories", func() {
 			response = `[{"estimate": 0, "blockers": [],"transitions": [],"name": "Test Rubbernecker","current_state": "started","url": "http://localhost/story/show/561","owner_ids":[1234],"labels":[]}]`
 			httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, response))
 
-			err := pt.FetchCards(rubbernecker.StatusDoing, map[string]string{})
+			err := pt.FetchCards(context.Background(), rubbernecker.StatusDoing, map[string]string{})
 			Expect(err).NotTo(HaveOccurred())
 
 			cards, err := pt.FlattenStories()
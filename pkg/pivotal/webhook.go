@@ -0,0 +1,89 @@
+package pivotal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	activityStoryCreate = "story_create_activity"
+	activityStoryUpdate = "story_update_activity"
+	activityStoryDelete = "story_delete_activity"
+)
+
+// Activity will be a rubbernecker representation of the activity webhook sent
+// by PivotalTracker, listing the stories which have been created or updated,
+// and the ones which have been deleted.
+type Activity struct {
+	Kind      string
+	ProjectID int64
+	Updated   []int
+	Deleted   []int
+}
+
+type activity struct {
+	Kind    string           `json:"kind"`
+	Project *activityProject `json:"project"`
+	Changes []activityChange `json:"changes"`
+}
+
+type activityProject struct {
+	ID int64 `json:"id"`
+}
+
+type activityChange struct {
+	Kind       string `json:"kind"`
+	ChangeType string `json:"change_type"`
+	ID         int    `json:"id"`
+}
+
+// ParseActivity will read the activity webhook, making sure it comes from a
+// project and lists what has changed.
+func ParseActivity(r io.Reader) (*Activity, error) {
+	a := activity{}
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("pivotal extension: invalid activity: %s", err)
+	}
+
+	if a.Kind == "" {
+		return nil, fmt.Errorf("pivotal extension: invalid activity: missing kind")
+	}
+
+	if a.Project == nil || a.Project.ID == 0 {
+		return nil, fmt.Errorf("pivotal extension: invalid activity: missing project")
+	}
+
+	activity := &Activity{
+		Kind:      a.Kind,
+		ProjectID: a.Project.ID,
+	}
+
+	seen := map[int]bool{}
+	for _, c := range a.Changes {
+		if c.Kind != "story" || c.ID == 0 || seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+
+		if c.ChangeType == "delete" {
+			activity.Deleted = append(activity.Deleted, c.ID)
+		} else {
+			activity.Updated = append(activity.Updated, c.ID)
+		}
+	}
+
+	return activity, nil
+}
+
+// Supported reports whether the activity is about creating, updating or
+// deleting stories, which the board can be updated with straight away. Any
+// other activity is left to the periodic fetch.
+func (a *Activity) Supported() bool {
+	switch a.Kind {
+	case activityStoryCreate, activityStoryUpdate, activityStoryDelete:
+		return true
+	default:
+		return false
+	}
+}
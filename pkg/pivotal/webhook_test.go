@@ -0,0 +1,51 @@
+package pivotal_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/pivotal"
+)
+
+var _ = Describe("Pivotal Webhooks", func() {
+	It("should ParseActivity() of the stories", func() {
+		activity, err := pivotal.ParseActivity(strings.NewReader(`{
+			"kind": "story_update_activity",
+			"project": {"kind": "project", "id": 123, "name": "Platform"},
+			"changes": [
+				{"kind": "story", "change_type": "update", "id": 561, "new_values": {"current_state": "finished"}},
+				{"kind": "label", "change_type": "create", "id": 7},
+				{"kind": "story", "change_type": "update", "id": 561},
+				{"kind": "story", "change_type": "delete", "id": 562}
+			]
+		}`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(activity.Kind).To(Equal("story_update_activity"))
+		Expect(activity.ProjectID).To(Equal(int64(123)))
+		Expect(activity.Updated).To(Equal([]int{561}))
+		Expect(activity.Deleted).To(Equal([]int{562}))
+		Expect(activity.Supported()).To(BeTrue())
+	})
+
+	It("should tell the unsupported activity", func() {
+		activity, err := pivotal.ParseActivity(strings.NewReader(`{"kind": "comment_create_activity", "project": {"id": 123}}`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(activity.Supported()).To(BeFalse())
+	})
+
+	It("should fail to ParseActivity() which is invalid", func() {
+		for _, body := range []string{
+			`not json`,
+			`{"project": {"id": 123}}`,
+			`{"kind": "story_update_activity"}`,
+		} {
+			_, err := pivotal.ParseActivity(strings.NewReader(body))
+
+			Expect(err).To(MatchError(ContainSubstring("invalid activity")), body)
+		}
+	})
+})
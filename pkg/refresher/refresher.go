@@ -0,0 +1,235 @@
+package refresher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of attempting the fetch while the upstream
+// service is being given a rest.
+var ErrCircuitOpen = errors.New("refresher: circuit is open")
+
+const (
+	// StateClosed is when the fetches are attempted as usual.
+	StateClosed = "closed"
+	// StateOpen is when the fetches are not attempted at all.
+	StateOpen = "open"
+	// StateHalfOpen is when a single fetch is attempted to find out whether the
+	// upstream service has recovered.
+	StateHalfOpen = "half-open"
+)
+
+// Fetch is a single attempt at fetching from the upstream service. It should
+// give up once the context is done.
+type Fetch func(ctx context.Context) error
+
+// Recorder interface will establish a standard for anything keeping track of
+// the outcome of the fetches, such as the rubbernecker.Upstreams.
+type Recorder interface {
+	Record(name string, duration time.Duration, at time.Time, err error)
+}
+
+// Refresher will keep fetching from the upstream service at the interval. Each
+// of the fetches is given the Timeout. On failure, the next one is delayed
+// exponentially from the MinBackoff up to the MaxBackoff, with some Jitter on
+// top, or for as long as the upstream service has asked to with Retry-After.
+// After the FailureThreshold consecutive failures, the circuit opens and
+// nothing is fetched for the Cooldown, until a single fetch is attempted again.
+// A fetch which has been given up on after the Timeout is still waited for
+// before the next one is started, so that they never overlap.
+type Refresher struct {
+	Name             string
+	Interval         time.Duration
+	Timeout          time.Duration
+	MinBackoff       time.Duration
+	MaxBackoff       time.Duration
+	Jitter           float64
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	// Recorder, if set, is told about the outcome of each of the fetches.
+	Recorder Recorder
+	// OnError, if set, is called with the error of each of the failed fetches.
+	OnError func(error)
+
+	fetch     Fetch
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	running   chan struct{}
+	now       func() time.Time
+	random    func() float64
+}
+
+// New will compose a Refresher fetching at the interval, with the defaults
+// backing off from the interval up to 5 minutes and opening the circuit for 5
+// minutes after 5 consecutive failures.
+func New(name string, interval time.Duration, fetch Fetch) *Refresher {
+	return &Refresher{
+		Name:             name,
+		Interval:         interval,
+		Timeout:          30 * time.Second,
+		MinBackoff:       interval,
+		MaxBackoff:       5 * time.Minute,
+		Jitter:           0.5,
+		FailureThreshold: 5,
+		Cooldown:         5 * time.Minute,
+		fetch:            fetch,
+		now:              time.Now,
+		random:           rand.Float64,
+	}
+}
+
+// State tells whether the circuit is closed, open or half-open.
+func (r *Refresher) State() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.now().Before(r.openUntil):
+		return StateOpen
+	case r.FailureThreshold > 0 && r.failures >= r.FailureThreshold:
+		return StateHalfOpen
+	default:
+		return StateClosed
+	}
+}
+
+// Refresh will attempt a single fetch, unless the circuit is open, and tell
+// how long to wait for before the next one.
+func (r *Refresher) Refresh(ctx context.Context) (time.Duration, error) {
+	r.mu.Lock()
+	now := r.now()
+	if now.Before(r.openUntil) {
+		wait := r.openUntil.Sub(now)
+		r.mu.Unlock()
+
+		return wait, ErrCircuitOpen
+	}
+	r.mu.Unlock()
+
+	err := r.attempt(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		r.failures = 0
+		r.openUntil = time.Time{}
+
+		return r.Interval, nil
+	}
+
+	r.failures++
+	wait := r.backoff(r.failures)
+	if retryAfter := RetryAfter(err); retryAfter > wait {
+		wait = retryAfter
+	}
+
+	if r.FailureThreshold > 0 && r.failures >= r.FailureThreshold {
+		if r.Cooldown > wait {
+			wait = r.Cooldown
+		}
+
+		r.openUntil = r.now().Add(wait)
+
+		return wait, fmt.Errorf("refresher: %s: circuit open for %s after %d failures: %w", r.Name, wait, r.failures, err)
+	}
+
+	return wait, err
+}
+
+// Run will keep refreshing after the initial delay, until the context is done.
+func (r *Refresher) Run(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		wait, err := r.Refresh(ctx)
+		if err != nil && !errors.Is(err, ErrCircuitOpen) && r.OnError != nil {
+			r.OnError(err)
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// attempt runs the fetch with the timeout. The fetch is given up on once the
+// timeout has passed, even if it does not honour the context itself. It is
+// not started until the previous one has returned though, which counts
+// towards the timeout.
+func (r *Refresher) attempt(ctx context.Context) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	r.mu.Lock()
+	previous := r.running
+	running := make(chan struct{})
+	r.running = running
+	r.mu.Unlock()
+
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer close(running)
+
+		if previous != nil {
+			select {
+			case <-previous:
+			case <-ctx.Done():
+				done <- ctx.Err()
+				<-previous
+				return
+			}
+		}
+
+		done <- r.fetch(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("refresher: %s: %w", r.Name, ctx.Err())
+	}
+
+	if r.Recorder != nil {
+		finished := time.Now()
+		r.Recorder.Record(r.Name, finished.Sub(started), finished, err)
+	}
+
+	return err
+}
+
+// backoff works out the delay after the given number of consecutive failures.
+// The jitter only ever adds to it, so that it is never shorter than the
+// MinBackoff. It should be called with the lock held.
+func (r *Refresher) backoff(failures int) time.Duration {
+	wait := r.MinBackoff
+	for i := 1; i < failures && wait < r.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if r.MaxBackoff > 0 && wait > r.MaxBackoff {
+		wait = r.MaxBackoff
+	}
+
+	if r.Jitter > 0 {
+		wait += time.Duration(r.Jitter * r.random() * float64(wait))
+	}
+
+	return wait
+}
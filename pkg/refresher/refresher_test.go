@@ -0,0 +1,345 @@
+package refresher_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	httpmock "gopkg.in/jarcoal/httpmock.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/helpers"
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+)
+
+type record struct {
+	name string
+	err  error
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (r *recorder) Record(name string, duration time.Duration, at time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, record{name: name, err: err})
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.records)
+}
+
+var _ = Describe("Refresher", func() {
+	var (
+		apiURL = "https://example.com/api/stories"
+		ctx    = context.Background()
+	)
+
+	fetch := func(ctx context.Context) error {
+		resp, err := http.Get(apiURL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode > 299 {
+			return refresher.NewResponseError(resp, fmt.Errorf("test: unexpected response code %d: %s", resp.StatusCode, body))
+		}
+
+		return nil
+	}
+
+	newRefresher := func(fetch refresher.Fetch) *refresher.Refresher {
+		r := refresher.New("stories", time.Second, fetch)
+		r.Jitter = 0
+		r.MinBackoff = 10 * time.Second
+		r.MaxBackoff = 40 * time.Second
+		r.FailureThreshold = 4
+		r.Cooldown = time.Minute
+
+		return r
+	}
+
+	BeforeEach(func() {
+		httpmock.Activate()
+	})
+
+	AfterEach(func() {
+		httpmock.DeactivateAndReset()
+	})
+
+	It("should compose a New() refresher with the defaults", func() {
+		r := refresher.New("stories", 20*time.Second, fetch)
+
+		Expect(r.Name).To(Equal("stories"))
+		Expect(r.Interval).To(Equal(20 * time.Second))
+		Expect(r.MinBackoff).To(Equal(20 * time.Second))
+		Expect(r.State()).To(Equal(refresher.StateClosed))
+	})
+
+	It("should wait for the interval after a successful Refresh()", func() {
+		httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(200, `[]`))
+
+		wait, err := newRefresher(fetch).Refresh(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(time.Second))
+	})
+
+	It("should back off exponentially on failures and reset on success", func() {
+		httpmock.RegisterResponder("GET", apiURL, helpers.NewCycleResponder(
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(200, `[]`),
+			httpmock.NewStringResponder(500, `oops`),
+		))
+		r := newRefresher(fetch)
+
+		waits := []time.Duration{}
+		for i := 0; i < 5; i++ {
+			wait, _ := r.Refresh(ctx)
+			waits = append(waits, wait)
+		}
+
+		Expect(waits).To(Equal([]time.Duration{
+			10 * time.Second,
+			20 * time.Second,
+			40 * time.Second,
+			time.Second,
+			10 * time.Second,
+		}))
+		Expect(r.State()).To(Equal(refresher.StateClosed))
+	})
+
+	It("should cap the backoff at the MaxBackoff", func() {
+		httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(500, `oops`))
+		r := newRefresher(fetch)
+		r.FailureThreshold = 0
+
+		var wait time.Duration
+		for i := 0; i < 10; i++ {
+			wait, _ = r.Refresh(ctx)
+		}
+
+		Expect(wait).To(Equal(40 * time.Second))
+		Expect(r.State()).To(Equal(refresher.StateClosed))
+	})
+
+	It("should apply the jitter to the backoff", func() {
+		httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(500, `oops`))
+		for i := 0; i < 20; i++ {
+			r := newRefresher(fetch)
+			r.Jitter = 0.5
+
+			wait, _ := r.Refresh(ctx)
+			Expect(wait).To(BeNumerically(">=", 10*time.Second))
+			Expect(wait).To(BeNumerically("<", 15*time.Second))
+		}
+	})
+
+	It("should honour the Retry-After of the upstream service", func() {
+		throttled := httpmock.NewStringResponse(429, `slow down`)
+		throttled.Header.Set("Retry-After", "90")
+
+		httpmock.RegisterResponder("GET", apiURL, helpers.NewCycleResponder(
+			httpmock.ResponderFromResponse(throttled),
+			httpmock.NewStringResponder(503, `unavailable`),
+		))
+		r := newRefresher(fetch)
+
+		wait, err := r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("429")))
+		Expect(wait).To(Equal(90 * time.Second))
+
+		wait, err = r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("503")))
+		Expect(wait).To(Equal(20 * time.Second))
+	})
+
+	It("should open the circuit after the FailureThreshold", func() {
+		calls := 0
+		httpmock.RegisterResponder("GET", apiURL, func(req *http.Request) (*http.Response, error) {
+			calls++
+			return httpmock.NewStringResponse(500, `oops`), nil
+		})
+		r := newRefresher(fetch)
+
+		for i := 0; i < 3; i++ {
+			_, err := r.Refresh(ctx)
+			Expect(err).NotTo(MatchError(ContainSubstring("circuit")))
+		}
+
+		wait, err := r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("circuit open for 1m0s after 4 failures")))
+		Expect(wait).To(Equal(time.Minute))
+		Expect(r.State()).To(Equal(refresher.StateOpen))
+
+		wait, err = r.Refresh(ctx)
+		Expect(err).To(Equal(refresher.ErrCircuitOpen))
+		Expect(wait).To(BeNumerically("<=", time.Minute))
+		Expect(calls).To(Equal(4))
+	})
+
+	It("should close the circuit once the upstream service has recovered", func() {
+		httpmock.RegisterResponder("GET", apiURL, helpers.NewCycleResponder(
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(200, `[]`),
+		))
+		r := newRefresher(fetch)
+		r.FailureThreshold = 2
+		r.Cooldown = 10 * time.Millisecond
+		r.MinBackoff = time.Millisecond
+		r.MaxBackoff = time.Millisecond
+
+		r.Refresh(ctx)
+		_, err := r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("circuit open")))
+
+		Eventually(r.State).Should(Equal(refresher.StateHalfOpen))
+
+		wait, err := r.Refresh(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(time.Second))
+		Expect(r.State()).To(Equal(refresher.StateClosed))
+	})
+
+	It("should reopen the circuit if the upstream service has not recovered", func() {
+		httpmock.RegisterResponder("GET", apiURL, httpmock.NewStringResponder(500, `oops`))
+		r := newRefresher(fetch)
+		r.FailureThreshold = 1
+		r.Cooldown = 10 * time.Millisecond
+		r.MinBackoff = time.Millisecond
+		r.MaxBackoff = time.Millisecond
+
+		_, err := r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("circuit open")))
+
+		Eventually(r.State).Should(Equal(refresher.StateHalfOpen))
+
+		_, err = r.Refresh(ctx)
+		Expect(err).To(MatchError(ContainSubstring("after 2 failures")))
+		Expect(r.State()).To(Equal(refresher.StateOpen))
+	})
+
+	It("should give up on the fetch after the Timeout", func() {
+		release := make(chan struct{})
+		defer close(release)
+
+		r := newRefresher(func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		r.Timeout = 10 * time.Millisecond
+
+		_, err := r.Refresh(ctx)
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err).To(MatchError(ContainSubstring("refresher: stories")))
+	})
+
+	It("should not start the fetch until the previous one has returned", func() {
+		var mu sync.Mutex
+		calls := 0
+		release := make(chan struct{})
+
+		r := newRefresher(func(ctx context.Context) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+
+			<-release
+			return nil
+		})
+		r.Timeout = 10 * time.Millisecond
+
+		_, err := r.Refresh(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		_, err = r.Refresh(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		mu.Lock()
+		Expect(calls).To(Equal(1))
+		mu.Unlock()
+
+		close(release)
+		r.Timeout = time.Second
+
+		_, err = r.Refresh(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		Expect(calls).To(Equal(2))
+		mu.Unlock()
+	})
+
+	It("should tell the Recorder about each of the fetches", func() {
+		httpmock.RegisterResponder("GET", apiURL, helpers.NewCycleResponder(
+			httpmock.NewStringResponder(500, `oops`),
+			httpmock.NewStringResponder(200, `[]`),
+		))
+		rec := &recorder{}
+		r := newRefresher(fetch)
+		r.Recorder = rec
+		r.FailureThreshold = 1
+
+		r.Refresh(ctx)
+		r.Refresh(ctx)
+
+		Expect(rec.records).To(HaveLen(1))
+		Expect(rec.records[0].name).To(Equal("stories"))
+		Expect(rec.records[0].err).To(MatchError(ContainSubstring("500")))
+	})
+
+	It("should Run() until the context is done", func() {
+		var mu sync.Mutex
+		calls := 0
+		rec := &recorder{}
+		errs := make(chan error, 10)
+		r := newRefresher(func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls++
+			if calls == 1 {
+				return fmt.Errorf("test: unexpected response code 500")
+			}
+
+			return nil
+		})
+		r.Interval = time.Millisecond
+		r.MinBackoff = time.Millisecond
+		r.Recorder = rec
+		r.OnError = func(err error) {
+			errs <- err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			r.Run(ctx, 0)
+			close(done)
+		}()
+
+		Eventually(rec.count).Should(BeNumerically(">=", 3))
+		Expect(errs).To(Receive(MatchError(ContainSubstring("500"))))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
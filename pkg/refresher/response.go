@@ -0,0 +1,80 @@
+package refresher
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResponseError will be an error returned by an upstream service, alongside
+// the details of the response the refresher backs off with.
+type ResponseError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ResponseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// NewResponseError will wrap the error caused by the response, picking up the
+// Retry-After header. With no response, the error is returned as it is.
+func NewResponseError(resp *http.Response, err error) error {
+	if resp == nil || err == nil {
+		return err
+	}
+
+	retryAfter, _ := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter,
+		Err:        err,
+	}
+}
+
+// ParseRetryAfter will read the Retry-After header, either in seconds or as an
+// HTTP date, into how long to wait for as of the given time.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if !at.After(now) {
+		return 0, true
+	}
+
+	return at.Sub(now), true
+}
+
+// RetryAfter finds out how long the upstream service has asked to wait for
+// before the next attempt, if at all.
+func RetryAfter(err error) time.Duration {
+	var re *ResponseError
+	if errors.As(err, &re) {
+		return re.RetryAfter
+	}
+
+	return 0
+}
@@ -0,0 +1,62 @@
+package refresher_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/refresher"
+)
+
+var _ = Describe("Response Error", func() {
+	now := time.Date(2018, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	It("should ParseRetryAfter() in seconds", func() {
+		wait, ok := refresher.ParseRetryAfter("120", now)
+
+		Expect(ok).To(BeTrue())
+		Expect(wait).To(Equal(2 * time.Minute))
+	})
+
+	It("should ParseRetryAfter() as an HTTP date", func() {
+		wait, ok := refresher.ParseRetryAfter("Thu, 18 Oct 2018 10:00:30 GMT", now)
+		Expect(ok).To(BeTrue())
+		Expect(wait).To(Equal(30 * time.Second))
+
+		wait, ok = refresher.ParseRetryAfter("Thu, 18 Oct 2018 09:00:00 GMT", now)
+		Expect(ok).To(BeTrue())
+		Expect(wait).To(BeZero())
+	})
+
+	It("should fail to ParseRetryAfter() invalid values", func() {
+		for _, value := range []string{"", "-1", "soon"} {
+			_, ok := refresher.ParseRetryAfter(value, now)
+			Expect(ok).To(BeFalse(), value)
+		}
+	})
+
+	It("should compose NewResponseError() out of the response", func() {
+		resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+		resp.Header.Set("Retry-After", "60")
+
+		err := refresher.NewResponseError(resp, fmt.Errorf("test: too many requests"))
+
+		Expect(err).To(MatchError("test: too many requests"))
+		Expect(refresher.RetryAfter(fmt.Errorf("wrapped: %w", err))).To(Equal(time.Minute))
+
+		var re *refresher.ResponseError
+		Expect(err).To(BeAssignableToTypeOf(re))
+		Expect(err.(*refresher.ResponseError).StatusCode).To(Equal(429))
+	})
+
+	It("should leave the error as it is with no response", func() {
+		err := fmt.Errorf("test: connection refused")
+
+		Expect(refresher.NewResponseError(nil, err)).To(Equal(err))
+		Expect(refresher.NewResponseError(&http.Response{}, nil)).To(BeNil())
+		Expect(refresher.RetryAfter(err)).To(BeZero())
+	})
+})
@@ -0,0 +1,183 @@
+package rubbernecker
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AvailabilityStatus is what a team member is up to.
+type AvailabilityStatus string
+
+// The statuses of the team members, by precedence. Someone on leave is not on
+// support, and someone on support is not pairing, even if on the rota or
+// assigned to the cards.
+const (
+	AvailabilityLeave   AvailabilityStatus = "leave"
+	AvailabilitySupport AvailabilityStatus = "support"
+	AvailabilityPairing AvailabilityStatus = "pairing"
+	AvailabilityWorking AvailabilityStatus = "working"
+	AvailabilityFree    AvailabilityStatus = "free"
+)
+
+// Absence will be a rubbernecker representation of a team member being away,
+// such as on leave. The member is named by their name or email.
+type Absence struct {
+	Member string    `json:"member"`
+	Reason string    `json:"reason,omitempty"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Covers reports whether the member is away at the given time.
+func (a Absence) Covers(t time.Time) bool {
+	return !a.Start.After(t) && a.End.After(t)
+}
+
+// Absences will be a rubbernecker representation of all the absences of the
+// team.
+type Absences []Absence
+
+// LeaveService interface will establish a standard for any extension handling
+// the absences of the team.
+type LeaveService interface {
+	FetchLeave(context.Context) error
+	FlattenLeave() (Absences, error)
+}
+
+// Availability will be a rubbernecker representation of what a team member is
+// up to, along with the support roles they are on call for, the absence they
+// are away for, or who they are pairing with.
+type Availability struct {
+	Member  *Member            `json:"member"`
+	Status  AvailabilityStatus `json:"status"`
+	Support []string           `json:"support,omitempty"`
+	Leave   *Absence           `json:"leave,omitempty"`
+	Pairing []string           `json:"pairing_with,omitempty"`
+}
+
+// Availabilities will be a rubbernecker representation of what the whole team
+// is up to, ordered by their names.
+type Availabilities []*Availability
+
+// NewAvailability will work out what each of the team members is up to at the
+// given time. The members are on support when on call for any of the busy
+// roles of the rota, and pairing when assigned to the cards along with someone
+// else.
+func NewAvailability(members Members, cards Cards, support SupportRota, rota Rota, leave Absences, now time.Time) Availabilities {
+	availability := Availabilities{}
+	index := map[int]*Availability{}
+
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+
+		a := &Availability{Member: m, Status: AvailabilityFree}
+		index[m.ID] = a
+		availability = append(availability, a)
+	}
+
+	for _, card := range cards {
+		for _, assignee := range card.Assignees {
+			if assignee == nil {
+				continue
+			}
+
+			a, ok := index[assignee.ID]
+			if !ok {
+				continue
+			}
+
+			a.Status = AvailabilityWorking
+			for _, other := range card.Assignees {
+				if other != nil && other.ID != assignee.ID {
+					a.Status = AvailabilityPairing
+					a.Pairing = appendUnique(a.Pairing, other.Name)
+				}
+			}
+		}
+	}
+
+	for _, e := range rota {
+		if !e.Busy {
+			continue
+		}
+
+		if m := members.Find(support.Get(e.Key).Member); m != nil {
+			a := index[m.ID]
+			a.Status = AvailabilitySupport
+			a.Support = append(a.Support, e.Label)
+		}
+	}
+
+	for i := range leave {
+		if !leave[i].Covers(now) {
+			continue
+		}
+
+		if m := members.Find(leave[i].Member); m != nil {
+			a := index[m.ID]
+			a.Status = AvailabilityLeave
+			a.Leave = &leave[i]
+		}
+	}
+
+	sort.SliceStable(availability, func(i, j int) bool {
+		a, b := availability[i].Member, availability[j].Member
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	return availability
+}
+
+// Members lists the team members of the given status.
+func (as Availabilities) Members(status AvailabilityStatus) Members {
+	members := Members{}
+
+	for _, a := range as {
+		if a.Status == status {
+			members[a.Member.ID] = a.Member
+		}
+	}
+
+	return members
+}
+
+// Find will look up the team member by their name or email, ignoring the case,
+// as used by the support and leave services.
+func (ms Members) Find(identity string) *Member {
+	identity = strings.TrimSpace(identity)
+	if identity == "" || identity == "-" {
+		return nil
+	}
+
+	ids := make([]int, 0, len(ms))
+	for id := range ms {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		m := ms[id]
+		if m != nil && (strings.EqualFold(m.Email, identity) || strings.EqualFold(m.Name, identity)) {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
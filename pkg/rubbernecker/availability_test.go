@@ -0,0 +1,89 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Availability", func() {
+	var (
+		now     = time.Date(2018, 3, 7, 12, 0, 0, 0, time.UTC)
+		members rubbernecker.Members
+		cards   rubbernecker.Cards
+		support rubbernecker.SupportRota
+		rota    rubbernecker.Rota
+	)
+
+	BeforeEach(func() {
+		members = rubbernecker.Members{
+			1: {ID: 1, Name: "Alice", Email: "alice@example.com"},
+			2: {ID: 2, Name: "Bob", Email: "bob@example.com"},
+			3: {ID: 3, Name: "Carol", Email: "carol@example.com"},
+			4: {ID: 4, Name: "Dave", Email: "dave@example.com"},
+			5: {ID: 5, Name: "Eve", Email: "eve@example.com"},
+			6: {ID: 6, Name: "Frank", Email: "frank@example.com"},
+		}
+
+		cards = rubbernecker.Cards{
+			{Title: "Pairing", Assignees: rubbernecker.Members{1: members[1], 2: members[2]}},
+			{Title: "Working", Assignees: rubbernecker.Members{3: members[3]}},
+			{Title: "On leave", Assignees: rubbernecker.Members{5: members[5]}},
+		}
+
+		support = rubbernecker.SupportRota{
+			"in-hours":     {Member: "ALICE@example.com"},
+			"out-of-hours": {Member: "Dave"},
+			"comms":        {Member: "Frank"},
+		}
+
+		rota = rubbernecker.Rota{
+			{Key: "in-hours", Label: "In hours", Schedule: "In hours", Busy: true},
+			{Key: "out-of-hours", Label: "Out of hours", Schedule: "Out of hours", Busy: true},
+			{Key: "comms", Label: "Comms", Schedule: "Comms"},
+		}
+	})
+
+	It("should work out NewAvailability() of the team", func() {
+		leave := rubbernecker.Absences{
+			{Member: "eve@example.com", Reason: "Annual leave", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+			{Member: "Frank", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+			{Member: "Somebody else", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+		}
+
+		availability := rubbernecker.NewAvailability(members, cards, support, rota, leave, now)
+
+		statuses := map[string]rubbernecker.AvailabilityStatus{}
+		names := []string{}
+		for _, a := range availability {
+			statuses[a.Member.Name] = a.Status
+			names = append(names, a.Member.Name)
+		}
+
+		Expect(names).To(Equal([]string{"Alice", "Bob", "Carol", "Dave", "Eve", "Frank"}))
+		Expect(statuses).To(Equal(map[string]rubbernecker.AvailabilityStatus{
+			"Alice": rubbernecker.AvailabilitySupport,
+			"Bob":   rubbernecker.AvailabilityPairing,
+			"Carol": rubbernecker.AvailabilityWorking,
+			"Dave":  rubbernecker.AvailabilitySupport,
+			"Eve":   rubbernecker.AvailabilityLeave,
+			"Frank": rubbernecker.AvailabilityFree,
+		}))
+
+		Expect(availability[0].Support).To(Equal([]string{"In hours"}))
+		Expect(availability[1].Pairing).To(Equal([]string{"Alice"}))
+		Expect(availability[4].Leave.Reason).To(Equal("Annual leave"))
+		Expect(availability.Members(rubbernecker.AvailabilityFree)).To(Equal(rubbernecker.Members{6: members[6]}))
+	})
+
+	It("should Find() the members by their name or email", func() {
+		Expect(members.Find("bob")).To(Equal(members[2]))
+		Expect(members.Find(" carol@EXAMPLE.com ")).To(Equal(members[3]))
+		Expect(members.Find("-")).To(BeNil())
+		Expect(members.Find("")).To(BeNil())
+		Expect(members.Find("Mallory")).To(BeNil())
+	})
+})
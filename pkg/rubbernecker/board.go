@@ -0,0 +1,429 @@
+package rubbernecker
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is the state of the board at a given point in time. Snapshots
+// are shared between the refreshers and the handlers, and therefore should
+// never be modified once published.
+type Snapshot struct {
+	ETag      time.Time   `json:"etag"`
+	Cards     Cards       `json:"cards"`
+	DoneCards Cards       `json:"done_cards"`
+	Members   Members     `json:"members"`
+	Support   SupportRota `json:"support"`
+	Incidents Incidents   `json:"incidents,omitempty"`
+	Leave     Absences    `json:"leave,omitempty"`
+
+	IterationStart time.Time `json:"iteration_start"`
+}
+
+// ChangesRetention is how long the changes to the cards are being kept for.
+const ChangesRetention = 7 * 24 * time.Hour
+
+// Board owns the current snapshot of the wall. The refreshers publish new
+// versions of its parts, while the readers obtain the latest complete
+// snapshot without blocking them.
+type Board struct {
+	mu          sync.Mutex
+	snapshot    atomic.Pointer[Snapshot]
+	subscribers map[chan *Snapshot]struct{}
+	feeds       map[*feed]struct{}
+	changes     Changes
+	timeline    SupportRota
+	fetched     time.Time
+	edits       []cardEdit
+}
+
+// cardEdit is a card published, or taken off the board when missing, on its
+// own rather than along with all the others.
+type cardEdit struct {
+	at      time.Time
+	project string
+	id      int
+	card    *Card
+}
+
+// NewBoard will compose an empty Board.
+func NewBoard() *Board {
+	b := &Board{
+		subscribers: map[chan *Snapshot]struct{}{},
+		feeds:       map[*feed]struct{}{},
+		changes:     Changes{},
+	}
+	b.snapshot.Store(&Snapshot{})
+
+	return b
+}
+
+// Subscribe returns a channel receiving every new version of the board once
+// published, and a function to stop receiving them. Slow subscribers only
+// ever receive the latest version, the ones in between are skipped.
+func (b *Board) Subscribe() (<-chan *Snapshot, func()) {
+	ch := make(chan *Snapshot, 1)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// Feed returns a channel receiving every new version of the board in order,
+// and a function to stop receiving them, which closes the channel. Unlike with
+// Subscribe, none of the versions are skipped, as they are queued until
+// received. The versions not yet received when stopping are dropped.
+func (b *Board) Feed() (<-chan *Snapshot, func()) {
+	f := &feed{wake: make(chan struct{}, 1), done: make(chan struct{})}
+	out := make(chan *Snapshot)
+
+	b.mu.Lock()
+	b.feeds[f] = struct{}{}
+	b.mu.Unlock()
+
+	go f.pump(out)
+
+	once := sync.Once{}
+	return out, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.feeds, f)
+			b.mu.Unlock()
+
+			close(f.done)
+		})
+	}
+}
+
+// feed queues the versions of the board for one of the Feed subscribers.
+type feed struct {
+	mu    sync.Mutex
+	queue []*Snapshot
+	wake  chan struct{}
+	done  chan struct{}
+}
+
+func (f *feed) push(snapshot *Snapshot) {
+	f.mu.Lock()
+	f.queue = append(f.queue, snapshot)
+	f.mu.Unlock()
+
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump hands the queued versions over to the subscriber one by one, until the
+// feed is stopped.
+func (f *feed) pump(out chan<- *Snapshot) {
+	defer close(out)
+
+	for {
+		f.mu.Lock()
+		if len(f.queue) == 0 {
+			f.mu.Unlock()
+
+			select {
+			case <-f.wake:
+				continue
+			case <-f.done:
+				return
+			}
+		}
+
+		next := f.queue[0]
+		f.queue = f.queue[1:]
+		f.mu.Unlock()
+
+		select {
+		case out <- next:
+		case <-f.done:
+			return
+		}
+	}
+}
+
+func (b *Board) notify(snapshot *Snapshot) {
+	for f := range b.feeds {
+		f.push(snapshot)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+
+		select {
+		case ch <- snapshot:
+		default:
+		}
+	}
+}
+
+// Changes returns what has happened to the cards after the given version of
+// the board, oldest first.
+func (b *Board) Changes(since time.Time) Changes {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.changes.Since(since)
+}
+
+// record keeps track of the changes to the cards between the two versions.
+// Nothing is recorded for the very first cards published, as that would
+// report the whole board as just created.
+func (b *Board) record(current, next *Snapshot) {
+	if current.Cards == nil && current.DoneCards == nil {
+		return
+	}
+
+	b.changes = append(b.changes, changesBetween(current, next)...)
+	b.changes = b.changes.Since(next.ETag.Add(-ChangesRetention))
+}
+
+// changesBetween finds what has happened to the cards, including the done
+// ones, between the two versions of the board.
+func changesBetween(current, next *Snapshot) Changes {
+	changes := Diff(combine(current.Cards, current.DoneCards), combine(next.Cards, next.DoneCards))
+	for i := range changes {
+		changes[i].At = next.ETag
+	}
+
+	return changes
+}
+
+func combine(cards, doneCards Cards) Cards {
+	all := make(Cards, 0, len(cards)+len(doneCards))
+	all = append(all, cards...)
+
+	return append(all, doneCards...)
+}
+
+// Restore will bring the board back to a previously published snapshot, for
+// instance one persisted before a restart. The subscribers are not notified
+// and no changes are recorded.
+func (b *Board) Restore(snapshot *Snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.snapshot.Store(snapshot)
+}
+
+// RestoreChanges will bring back the changes to the cards recorded before a
+// restart, see LoadChanges. The ones older than the ChangesRetention are
+// dropped.
+func (b *Board) RestoreChanges(changes Changes) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.changes = changes.Since(time.Now().Add(-ChangesRetention))
+}
+
+// Snapshot returns the latest published version of the board.
+func (b *Board) Snapshot() *Snapshot {
+	return b.snapshot.Load()
+}
+
+// update applies the change to a copy of the current snapshot and publishes
+// it, unless it has not changed anything. It reports whether a new version has
+// been published.
+func (b *Board) update(change func(s *Snapshot)) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.snapshot.Load()
+	next := *current
+	change(&next)
+
+	if reflect.DeepEqual(current, &next) {
+		return false
+	}
+
+	next.ETag = time.Now()
+	b.record(current, &next)
+	b.snapshot.Store(&next)
+	b.notify(&next)
+
+	return true
+}
+
+// PublishCards will replace the cards in play and the done cards.
+func (b *Board) PublishCards(cards, doneCards Cards) bool {
+	return b.PublishFetchedCards(time.Now(), cards, doneCards)
+}
+
+// PublishFetchedCards will replace the cards in play and the done cards with
+// the ones fetched from the given time on. The cards published or removed on
+// their own since are applied on top, as the fetch may have missed them, and
+// the cards of a fetch older than the ones already published are dropped.
+func (b *Board) PublishFetchedCards(started time.Time, cards, doneCards Cards) bool {
+	return b.update(func(s *Snapshot) {
+		if started.Before(b.fetched) {
+			return
+		}
+
+		s.Cards = cards
+		s.DoneCards = doneCards
+
+		edits := []cardEdit{}
+		for _, e := range b.edits {
+			if e.at.Before(started) {
+				continue
+			}
+
+			if e.card == nil {
+				removeCard(s, e.project, e.id)
+			} else {
+				placeCard(s, e.card)
+			}
+
+			edits = append(edits, e)
+		}
+
+		b.fetched = started
+		b.edits = edits
+	})
+}
+
+// PublishIterationStart will replace the start of the current iteration.
+func (b *Board) PublishIterationStart(start time.Time) bool {
+	return b.update(func(s *Snapshot) {
+		s.IterationStart = start
+	})
+}
+
+// PublishMembers will replace the team members.
+func (b *Board) PublishMembers(members Members) bool {
+	return b.update(func(s *Snapshot) {
+		s.Members = members
+	})
+}
+
+// PublishSupport will replace the support rota. Only the shifts on call now
+// and the next ones make it into the snapshot, the whole of them are kept
+// aside for the Timeline.
+func (b *Board) PublishSupport(support SupportRota) bool {
+	b.mu.Lock()
+	b.timeline = support
+	b.mu.Unlock()
+
+	return b.update(func(s *Snapshot) {
+		s.Support = support.Trim(time.Now())
+	})
+}
+
+// Timeline returns the support rota with all the shifts fetched. Until the
+// rota is first published, such as after a restart, only the shifts of the
+// snapshot are known.
+func (b *Board) Timeline() SupportRota {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.timeline == nil {
+		return b.snapshot.Load().Support
+	}
+
+	return b.timeline
+}
+
+// PublishIncidents will replace the open incidents.
+func (b *Board) PublishIncidents(incidents Incidents) bool {
+	return b.update(func(s *Snapshot) {
+		s.Incidents = incidents
+	})
+}
+
+// PublishLeave will replace the absences of the team.
+func (b *Board) PublishLeave(leave Absences) bool {
+	return b.update(func(s *Snapshot) {
+		s.Leave = leave
+	})
+}
+
+// PublishCard will add the card, or replace the one of the same project with
+// the same ID, either in play or done depending on its status. Cards already
+// on the board keep their place, new done cards are shown first and the new
+// cards in play last.
+func (b *Board) PublishCard(card *Card) bool {
+	return b.update(func(s *Snapshot) {
+		b.edits = append(b.edits, cardEdit{at: time.Now(), project: card.Project, id: card.ID, card: card})
+		placeCard(s, card)
+	})
+}
+
+// RemoveCard will take the card of the project with the ID off the board.
+func (b *Board) RemoveCard(project string, id int) bool {
+	return b.update(func(s *Snapshot) {
+		b.edits = append(b.edits, cardEdit{at: time.Now(), project: project, id: id})
+		removeCard(s, project, id)
+	})
+}
+
+// placeCard adds the card to the snapshot, or replaces the one of the same
+// project with the same ID, as described for PublishCard.
+func placeCard(s *Snapshot, card *Card) {
+	cards, i := withoutCard(s.Cards, card.Project, card.ID)
+	doneCards, j := withoutCard(s.DoneCards, card.Project, card.ID)
+
+	if card.Status == StatusDone.String() {
+		if j < 0 {
+			j = 0
+		}
+		s.Cards = cards
+		s.DoneCards = insertCard(doneCards, j, card)
+		return
+	}
+
+	if i < 0 {
+		i = len(cards)
+	}
+	s.Cards = insertCard(cards, i, card)
+	s.DoneCards = doneCards
+}
+
+// removeCard takes the card of the project with the ID off the snapshot.
+func removeCard(s *Snapshot, project string, id int) {
+	s.Cards, _ = withoutCard(s.Cards, project, id)
+	s.DoneCards, _ = withoutCard(s.DoneCards, project, id)
+}
+
+// withoutCard returns a copy of the cards without the one of the project with
+// the ID, and where it used to be, if at all.
+func withoutCard(cards Cards, project string, id int) (Cards, int) {
+	tmp := make(Cards, 0, len(cards))
+	index := -1
+
+	for i, c := range cards {
+		if c.Project == project && c.ID == id {
+			index = i
+			continue
+		}
+
+		tmp = append(tmp, c)
+	}
+
+	if index < 0 {
+		return cards, index
+	}
+
+	return tmp, index
+}
+
+// insertCard returns a copy of the cards with the card at the index.
+func insertCard(cards Cards, index int, card *Card) Cards {
+	tmp := make(Cards, 0, len(cards)+1)
+	tmp = append(tmp, cards[:index]...)
+	tmp = append(tmp, card)
+
+	return append(tmp, cards[index:]...)
+}
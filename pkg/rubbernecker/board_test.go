@@ -0,0 +1,291 @@
+package rubbernecker_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Board", func() {
+	var (
+		board *rubbernecker.Board
+	)
+
+	BeforeEach(func() {
+		board = rubbernecker.NewBoard()
+	})
+
+	It("should start with an empty Snapshot()", func() {
+		snapshot := board.Snapshot()
+
+		Expect(snapshot).NotTo(BeNil())
+		Expect(snapshot.Cards).To(BeNil())
+		Expect(snapshot.ETag.IsZero()).To(BeTrue())
+	})
+
+	It("should publish a new version with PublishCards()", func() {
+		before := board.Snapshot()
+
+		changed := board.PublishCards(rubbernecker.Cards{{Title: "Test"}}, rubbernecker.Cards{})
+		after := board.Snapshot()
+
+		Expect(changed).To(BeTrue())
+		Expect(after).NotTo(BeIdenticalTo(before))
+		Expect(after.Cards).To(HaveLen(1))
+		Expect(after.ETag.IsZero()).To(BeFalse())
+		Expect(before.Cards).To(BeNil())
+	})
+
+	It("should keep the other parts of the snapshot when publishing", func() {
+		board.PublishMembers(rubbernecker.Members{1: &rubbernecker.Member{Name: "Tester"}})
+		board.PublishSupport(rubbernecker.SupportRota{"in-hours": &rubbernecker.Support{Member: "Tester"}})
+		board.PublishCards(rubbernecker.Cards{{Title: "Test"}}, nil)
+
+		snapshot := board.Snapshot()
+
+		Expect(snapshot.Members).To(HaveLen(1))
+		Expect(snapshot.Support).To(HaveKey("in-hours"))
+		Expect(snapshot.Cards).To(HaveLen(1))
+	})
+
+	It("should keep the shifts out of the snapshot and in the Timeline()", func() {
+		past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		later := future.Add(time.Hour)
+
+		Expect(board.Timeline()).To(BeNil())
+
+		board.PublishSupport(rubbernecker.SupportRota{"in-hours": rubbernecker.NewSupport("in-hours", "", []rubbernecker.Shift{
+			{Member: "Alice", Start: &past, End: &future},
+			{Member: "Bob", Start: &future, End: &later},
+			{Member: "Carol", Start: &later},
+		}, time.Now())})
+
+		Expect(board.Snapshot().Support["in-hours"].Shifts).To(HaveLen(2))
+		Expect(board.Timeline()["in-hours"].Shifts).To(HaveLen(3))
+	})
+
+	It("should not publish a new version if nothing has changed", func() {
+		board.PublishMembers(rubbernecker.Members{1: &rubbernecker.Member{Name: "Tester"}})
+		before := board.Snapshot()
+
+		changed := board.PublishMembers(rubbernecker.Members{1: &rubbernecker.Member{Name: "Tester"}})
+
+		Expect(changed).To(BeFalse())
+		Expect(board.Snapshot()).To(BeIdenticalTo(before))
+	})
+
+	It("should notify subscribers about new versions", func() {
+		updates, unsubscribe := board.Subscribe()
+		defer unsubscribe()
+
+		board.PublishCards(rubbernecker.Cards{{Title: "Test"}}, nil)
+
+		Eventually(updates).Should(Receive(BeIdenticalTo(board.Snapshot())))
+	})
+
+	It("should only keep the latest version for slow subscribers", func() {
+		updates, unsubscribe := board.Subscribe()
+		defer unsubscribe()
+
+		board.PublishCards(rubbernecker.Cards{{ID: 1}}, nil)
+		board.PublishCards(rubbernecker.Cards{{ID: 2}}, nil)
+
+		var snapshot *rubbernecker.Snapshot
+		Expect(updates).To(Receive(&snapshot))
+		Expect(snapshot.Cards[0].ID).To(Equal(2))
+		Expect(updates).NotTo(Receive())
+	})
+
+	It("should Feed() every version to slow subscribers in order", func() {
+		updates, stop := board.Feed()
+
+		board.PublishCards(rubbernecker.Cards{{ID: 1}}, nil)
+		board.PublishCards(rubbernecker.Cards{{ID: 2}}, nil)
+		board.PublishCards(rubbernecker.Cards{{ID: 3}}, nil)
+
+		for id := 1; id <= 3; id++ {
+			var snapshot *rubbernecker.Snapshot
+			Eventually(updates).Should(Receive(&snapshot))
+			Expect(snapshot.Cards[0].ID).To(Equal(id))
+		}
+
+		stop()
+		stop()
+
+		Eventually(updates).Should(BeClosed())
+	})
+
+	It("should not notify subscribers once unsubscribed", func() {
+		updates, unsubscribe := board.Subscribe()
+		unsubscribe()
+
+		board.PublishCards(rubbernecker.Cards{{Title: "Test"}}, nil)
+
+		Consistently(updates).ShouldNot(Receive())
+	})
+
+	It("should not record the very first cards as Changes()", func() {
+		board.PublishCards(rubbernecker.Cards{{ID: 1, Status: "doing"}}, rubbernecker.Cards{})
+
+		Expect(board.Changes(time.Time{})).To(BeEmpty())
+	})
+
+	It("should record the Changes() between the versions", func() {
+		board.PublishCards(rubbernecker.Cards{{ID: 1, Status: "doing"}}, rubbernecker.Cards{})
+		first := board.Snapshot().ETag
+
+		board.PublishCards(rubbernecker.Cards{}, rubbernecker.Cards{{ID: 1, Status: "done"}})
+		second := board.Snapshot().ETag
+
+		changes := board.Changes(time.Time{})
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeMoved))
+		Expect(changes[0].At).To(Equal(second))
+		Expect(board.Changes(first.Add(-time.Second))).To(HaveLen(1))
+		Expect(board.Changes(second)).To(BeEmpty())
+	})
+
+	It("should add and replace a card with PublishCard()", func() {
+		board.PublishCards(rubbernecker.Cards{
+			{ID: 1, Project: "platform", Status: "doing"},
+			{ID: 2, Project: "platform", Status: "doing"},
+			{ID: 1, Project: "tenant", Status: "doing"},
+		}, rubbernecker.Cards{})
+
+		Expect(board.PublishCard(&rubbernecker.Card{ID: 1, Project: "platform", Status: "reviewing"})).To(BeTrue())
+		Expect(board.PublishCard(&rubbernecker.Card{ID: 3, Project: "platform", Status: "next"})).To(BeTrue())
+		Expect(board.PublishCard(&rubbernecker.Card{ID: 3, Project: "platform", Status: "next"})).To(BeFalse())
+
+		cards := board.Snapshot().Cards
+		Expect(cards).To(HaveLen(4))
+		Expect(cards[0].Status).To(Equal("reviewing"))
+		Expect(cards[2].Project).To(Equal("tenant"))
+		Expect(cards[2].Status).To(Equal("doing"))
+		Expect(cards[3].ID).To(Equal(3))
+	})
+
+	It("should move the card once done with PublishCard()", func() {
+		board.PublishCards(rubbernecker.Cards{
+			{ID: 1, Project: "platform", Status: "approving"},
+			{ID: 2, Project: "platform", Status: "doing"},
+		}, rubbernecker.Cards{
+			{ID: 3, Project: "platform", Status: "done"},
+		})
+
+		board.PublishCard(&rubbernecker.Card{ID: 1, Project: "platform", Status: "done"})
+
+		snapshot := board.Snapshot()
+		Expect(snapshot.Cards).To(HaveLen(1))
+		Expect(snapshot.DoneCards).To(HaveLen(2))
+		Expect(snapshot.DoneCards[0].ID).To(Equal(1))
+		Expect(board.Changes(time.Time{})).To(ContainElement(HaveField("Type", rubbernecker.ChangeMoved)))
+
+		board.PublishCard(&rubbernecker.Card{ID: 1, Project: "platform", Status: "rejected"})
+
+		snapshot = board.Snapshot()
+		Expect(snapshot.Cards).To(HaveLen(2))
+		Expect(snapshot.DoneCards).To(HaveLen(1))
+	})
+
+	It("should take the card off the board with RemoveCard()", func() {
+		board.PublishCards(rubbernecker.Cards{
+			{ID: 1, Project: "platform", Status: "doing"},
+			{ID: 1, Project: "tenant", Status: "doing"},
+		}, rubbernecker.Cards{
+			{ID: 2, Project: "platform", Status: "done"},
+		})
+
+		Expect(board.RemoveCard("platform", 1)).To(BeTrue())
+		Expect(board.RemoveCard("platform", 2)).To(BeTrue())
+		Expect(board.RemoveCard("platform", 3)).To(BeFalse())
+
+		snapshot := board.Snapshot()
+		Expect(snapshot.Cards).To(HaveLen(1))
+		Expect(snapshot.Cards[0].Project).To(Equal("tenant"))
+		Expect(snapshot.DoneCards).To(BeEmpty())
+	})
+
+	It("should keep the cards published since the fetch started with PublishFetchedCards()", func() {
+		started := time.Now()
+
+		board.PublishCard(&rubbernecker.Card{ID: 1, Project: "platform", Status: "done"})
+		board.RemoveCard("platform", 2)
+
+		board.PublishFetchedCards(started, rubbernecker.Cards{
+			{ID: 1, Project: "platform", Status: "approving"},
+			{ID: 2, Project: "platform", Status: "doing"},
+			{ID: 3, Project: "platform", Status: "doing"},
+		}, rubbernecker.Cards{})
+
+		snapshot := board.Snapshot()
+		Expect(snapshot.Cards).To(HaveLen(1))
+		Expect(snapshot.Cards[0].ID).To(Equal(3))
+		Expect(snapshot.DoneCards).To(HaveLen(1))
+		Expect(snapshot.DoneCards[0].ID).To(Equal(1))
+	})
+
+	It("should replace the cards published before the fetch started with PublishFetchedCards()", func() {
+		board.PublishCard(&rubbernecker.Card{ID: 1, Project: "platform", Status: "done"})
+
+		board.PublishFetchedCards(time.Now(), rubbernecker.Cards{
+			{ID: 1, Project: "platform", Status: "approving"},
+		}, rubbernecker.Cards{})
+
+		snapshot := board.Snapshot()
+		Expect(snapshot.Cards).To(HaveLen(1))
+		Expect(snapshot.Cards[0].Status).To(Equal("approving"))
+		Expect(snapshot.DoneCards).To(BeEmpty())
+	})
+
+	It("should drop the cards of a fetch older than the published ones with PublishFetchedCards()", func() {
+		earlier := time.Now()
+		board.PublishFetchedCards(earlier.Add(time.Second), rubbernecker.Cards{{ID: 1, Project: "platform", Status: "doing"}}, rubbernecker.Cards{})
+
+		Expect(board.PublishFetchedCards(earlier, rubbernecker.Cards{{ID: 2, Project: "platform", Status: "doing"}}, rubbernecker.Cards{})).To(BeFalse())
+		Expect(board.Snapshot().Cards[0].ID).To(Equal(1))
+	})
+
+	It("should Restore() a snapshot without notifying the subscribers", func() {
+		updates, unsubscribe := board.Subscribe()
+		defer unsubscribe()
+
+		snapshot := &rubbernecker.Snapshot{ETag: time.Now(), Cards: rubbernecker.Cards{{ID: 1}}}
+		board.Restore(snapshot)
+
+		Expect(board.Snapshot()).To(BeIdenticalTo(snapshot))
+		Expect(updates).NotTo(Receive())
+	})
+
+	It("should be safe to publish and read concurrently", func() {
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				board.PublishCards(rubbernecker.Cards{{ID: i}}, nil)
+				board.PublishMembers(rubbernecker.Members{i: &rubbernecker.Member{ID: i}})
+			}(i)
+
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				snapshot := board.Snapshot()
+				Expect(len(snapshot.Cards)).To(BeNumerically("<=", 1))
+			}()
+		}
+
+		wg.Wait()
+
+		Expect(board.Snapshot().Cards).To(HaveLen(1))
+		Expect(board.Snapshot().Members).To(HaveLen(1))
+	})
+})
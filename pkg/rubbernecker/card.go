@@ -1,9 +1,15 @@
 package rubbernecker
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"time"
 )
 
+// ErrNoCards is returned by the FlattenStories of the extensions when there
+// were no cards fetched to be flattened.
+var ErrNoCards = errors.New("no cards to be flattened")
+
 // Status is treated as an enum for the story status codes.
 type Status int
 
@@ -31,15 +37,18 @@ const (
 
 // Card will be a rubbernecker entity composed of the extension.
 type Card struct {
-	ID        int      `json:"id"`
-	Assignees Members  `json:"assignees"`
-	Elapsed   int      `json:"in_play"`
-	Status    string   `json:"status"`
-	Stickers  Stickers `json:"stickers"`
-	Title     string   `json:"title"`
-	URL       string   `json:"url"`
-	StoryType string   `json:"story_type"`
-	Estimate  *float64 `json:"estimate"`
+	ID         int        `json:"id"`
+	Assignees  Members    `json:"assignees"`
+	Elapsed    int        `json:"in_play"`
+	Status     string     `json:"status"`
+	Stickers   Stickers   `json:"stickers"`
+	Title      string     `json:"title"`
+	URL        string     `json:"url"`
+	StoryType  string     `json:"story_type"`
+	Estimate   *float64   `json:"estimate"`
+	Project    string     `json:"project,omitempty"`
+	Flow       *Flow      `json:"flow,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
 }
 
 // Cards will be a rubbernecker representative of all cards.
@@ -57,7 +66,7 @@ func (c Cards) Reverse() {
 // flatten their story into rubbernecker format.
 type ProjectManagementService interface {
 	AcceptStickers(Stickers)
-	FetchCards(Status, map[string]string) error
+	FetchCards(context.Context, Status, map[string]string) error
 	FlattenStories() (Cards, error)
 }
 
@@ -97,55 +106,15 @@ func (c Cards) Filter(s string) Cards {
 	return tmp
 }
 
-func (c Cards) FilterBy(filters []string) Cards {
-	if len(filters) == 0 {
-		return c
-	}
-
-	filter := strings.ToLower(filters[0])
-
-	filteredCards := make(Cards, 0)
+// Matching will filter the cards matching the query.
+func (c Cards) Matching(query Query) Cards {
+	tmp := make(Cards, 0)
 
 	for _, card := range c {
-		shouldAdd := false
-
-		if strings.HasPrefix(filter, "person:") {
-			memberName := strings.ToLower(strings.Replace(filter, "person:", "", -1))
-			for _, member := range card.Assignees {
-				if strings.Contains(strings.ToLower(member.Name), memberName) {
-					shouldAdd = true
-				}
-			}
-		} else if strings.HasPrefix(filter, "title:") {
-			title := strings.ToLower(strings.Replace(filter, "title:", "", -1))
-			if strings.Contains(strings.ToLower(card.Title), title) {
-				shouldAdd = true
-			}
-		} else if strings.HasPrefix(filter, "sticker:") {
-			sname := strings.ToLower(strings.Replace(filter, "sticker:", "", -1))
-
-			for _, sticker := range card.Stickers {
-				if strings.HasPrefix(sticker.Name, sname) {
-					shouldAdd = true
-				}
-			}
-		} else if strings.HasPrefix(filter, "not-sticker:") {
-			sname := strings.ToLower(strings.Replace(filter, "not-sticker:", "", -1))
-			shouldAdd = true
-
-			for _, sticker := range card.Stickers {
-				if strings.HasPrefix(sticker.Name, sname) {
-					shouldAdd = false
-				}
-			}
-		} else {
-			shouldAdd = true
-		}
-
-		if shouldAdd {
-			filteredCards = append(filteredCards, card)
+		if query.Matches(card) {
+			tmp = append(tmp, card)
 		}
 	}
 
-	return filteredCards.FilterBy(filters[1:])
+	return tmp
 }
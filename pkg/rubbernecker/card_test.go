@@ -34,7 +34,14 @@ var _ = Describe("Response", func() {
 })
 
 var _ = Describe("Card Filtering", func() {
-	Context("FilterBy", func() {
+	filterBy := func(cards rubbernecker.Cards, filters ...string) rubbernecker.Cards {
+		query, err := rubbernecker.ParseQueries(filters)
+		Expect(err).NotTo(HaveOccurred())
+
+		return cards.Matching(query)
+	}
+
+	Context("Matching", func() {
 		It("should not do anything if there are no filters", func() {
 			cards := make(rubbernecker.Cards, 0)
 			cards = append(
@@ -44,12 +51,12 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{},
 			)
 
-			filteredCards := cards.FilterBy([]string{})
+			filteredCards := filterBy(cards)
 
 			Expect(filteredCards).To(HaveLen(3))
 		})
 
-		It("should not do anything for unknown filters", func() {
+		It("should refuse unknown filters", func() {
 			cards := make(rubbernecker.Cards, 0)
 			cards = append(
 				cards,
@@ -58,9 +65,9 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{},
 			)
 
-			filteredCards := cards.FilterBy([]string{"foo"})
+			_, err := rubbernecker.ParseQueries([]string{"foo"})
 
-			Expect(filteredCards).To(HaveLen(3))
+			Expect(err).To(MatchError(ContainSubstring("expected field:value")))
 		})
 
 		It("should implement person filters", func() {
@@ -75,9 +82,7 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"person:rubber",
-			})
+			filteredCards := filterBy(cards, "person:rubber")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("a-card"))
@@ -98,9 +103,7 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"person:necker", "person:rubber",
-			})
+			filteredCards := filterBy(cards, "person:necker", "person:rubber")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("a-card"))
@@ -114,7 +117,7 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{Title: "b-card"},
 			)
 
-			filteredCards := cards.FilterBy([]string{"title:b"})
+			filteredCards := filterBy(cards, "title:b")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("b-card"))
@@ -128,9 +131,22 @@ var _ = Describe("Card Filtering", func() {
 				&rubbernecker.Card{Title: "b-card"},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"title:card", "title:b",
-			})
+			filteredCards := filterBy(cards, "title:card", "title:b")
+
+			Expect(filteredCards).To(HaveLen(1))
+			Expect(filteredCards[0].Title).To(Equal("b-card"))
+		})
+
+		It("should implement project filters", func() {
+			cards := make(rubbernecker.Cards, 0)
+			cards = append(
+				cards,
+				&rubbernecker.Card{Title: "a-card", Project: "platform"},
+				&rubbernecker.Card{Title: "b-card", Project: "tenant"},
+				&rubbernecker.Card{Title: "c-card"},
+			)
+
+			filteredCards := filterBy(cards, "project:Tenant")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("b-card"))
@@ -154,9 +170,7 @@ var _ = Describe("Card Filtering", func() {
 				},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"sticker:non-tech",
-			})
+			filteredCards := filterBy(cards, "sticker:non-tech")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("a non-tech card"))
@@ -180,9 +194,7 @@ var _ = Describe("Card Filtering", func() {
 				},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"not-sticker:bug",
-			})
+			filteredCards := filterBy(cards, "not-sticker:bug")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("a non-tech card"))
@@ -206,9 +218,7 @@ var _ = Describe("Card Filtering", func() {
 				},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"sticker:tech", "not-sticker:tech",
-			})
+			filteredCards := filterBy(cards, "sticker:tech", "not-sticker:tech")
 
 			Expect(filteredCards).To(HaveLen(0))
 		})
@@ -231,9 +241,7 @@ var _ = Describe("Card Filtering", func() {
 				},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"sticker:'small' task",
-			})
+			filteredCards := filterBy(cards, "sticker:'small' task")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).To(Equal("a small task"))
@@ -257,9 +265,7 @@ var _ = Describe("Card Filtering", func() {
 				},
 			)
 
-			filteredCards := cards.FilterBy([]string{
-				"not-sticker:'small' task",
-			})
+			filteredCards := filterBy(cards, "not-sticker:'small' task")
 
 			Expect(filteredCards).To(HaveLen(1))
 			Expect(filteredCards[0].Title).NotTo(Equal("a small task"))
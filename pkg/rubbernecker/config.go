@@ -1,7 +1,29 @@
 package rubbernecker
 
+import "encoding/json"
+
 // Config will hold some basic settings for the rubbernecker frontend.
 type Config struct {
-	ReviewalLimit int
-	ApprovalLimit int
+	Workflow Workflow
+	Rota     Rota
+}
+
+// MarshalJSON will still provide the ReviewalLimit and ApprovalLimit, which
+// the JSON consumers have relied on before the workflow was configurable. These
+// are the limits of the reviewing and approving columns, if any.
+func (c Config) MarshalJSON() ([]byte, error) {
+	type config Config
+
+	reviewing, _ := c.Workflow.Column(StatusReviewal.String())
+	approving, _ := c.Workflow.Column(StatusApproval.String())
+
+	return json.Marshal(struct {
+		config
+		ReviewalLimit int
+		ApprovalLimit int
+	}{
+		config:        config(c),
+		ReviewalLimit: reviewing.Limit,
+		ApprovalLimit: approving.Limit,
+	})
 }
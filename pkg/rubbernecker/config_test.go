@@ -1 +1,31 @@
 package rubbernecker_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Config", func() {
+	It("should keep the limits of the reviewing and approving columns in the JSON", func() {
+		data, err := json.Marshal(&rubbernecker.Config{Workflow: rubbernecker.DefaultWorkflow()})
+		Expect(err).NotTo(HaveOccurred())
+
+		var config map[string]interface{}
+		Expect(json.Unmarshal(data, &config)).To(Succeed())
+
+		Expect(config).To(HaveKey("Workflow"))
+		Expect(config).To(HaveKeyWithValue("ReviewalLimit", BeNumerically("==", 4)))
+		Expect(config).To(HaveKeyWithValue("ApprovalLimit", BeNumerically("==", 5)))
+	})
+
+	It("should have no limits in the JSON for the workflow without the columns", func() {
+		data, err := json.Marshal(&rubbernecker.Config{Workflow: rubbernecker.Workflow{{Name: "done"}}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(data)).To(ContainSubstring(`"ReviewalLimit":0,"ApprovalLimit":0`))
+	})
+})
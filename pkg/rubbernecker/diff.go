@@ -0,0 +1,149 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeType is treated as an enum for the kinds of changes a card can go
+// through between two snapshots.
+type ChangeType string
+
+const (
+	// ChangeCreated is reported for the cards which have appeared on the board.
+	ChangeCreated ChangeType = "created"
+	// ChangeMoved is reported for the cards which have changed their status.
+	ChangeMoved ChangeType = "moved"
+	// ChangeReassigned is reported for the cards which have changed assignees.
+	ChangeReassigned ChangeType = "reassigned"
+	// ChangeStickerAdded is reported for every sticker put on a card.
+	ChangeStickerAdded ChangeType = "sticker_added"
+	// ChangeStickerRemoved is reported for every sticker taken off a card.
+	ChangeStickerRemoved ChangeType = "sticker_removed"
+	// ChangeRemoved is reported for the cards which have left the board.
+	ChangeRemoved ChangeType = "removed"
+)
+
+// Change will be a rubbernecker representation of a single thing which
+// happened to a card.
+type Change struct {
+	At      time.Time  `json:"at"`
+	Type    ChangeType `json:"type"`
+	CardID  int        `json:"card_id"`
+	Project string     `json:"project,omitempty"`
+	Title   string     `json:"title"`
+	URL     string     `json:"url"`
+	From    string     `json:"from,omitempty"`
+	To      string     `json:"to,omitempty"`
+}
+
+// Changes will be a rubbernecker representative of all changes.
+type Changes []Change
+
+// Since will filter the changes which have happened after the given version
+// of the board. Versions are only precise to a second, same as the ETag.
+func (cs Changes) Since(since time.Time) Changes {
+	tmp := Changes{}
+
+	for _, c := range cs {
+		if c.At.Unix() > since.Unix() {
+			tmp = append(tmp, c)
+		}
+	}
+
+	return tmp
+}
+
+// Diff compares two snapshots of the cards and reports what has happened to
+// them in between. Cards are identified by their project and ID. The changes
+// follow the order of the new cards, with the removed ones reported last. The
+// done cards which are gone have only aged out of the fetched window, so they
+// are not reported as removed.
+func Diff(old, new Cards) Changes {
+	changes := Changes{}
+	previous := map[string]*Card{}
+	current := map[string]bool{}
+
+	for _, card := range old {
+		previous[cardKey(card)] = card
+	}
+
+	for _, card := range new {
+		key := cardKey(card)
+		current[key] = true
+
+		before, ok := previous[key]
+		if !ok {
+			changes = append(changes, newChange(ChangeCreated, card, "", card.Status))
+			continue
+		}
+
+		if before.Status != card.Status {
+			changes = append(changes, newChange(ChangeMoved, card, before.Status, card.Status))
+		}
+
+		from, to := assigneeNames(before.Assignees), assigneeNames(card.Assignees)
+		if from != to {
+			changes = append(changes, newChange(ChangeReassigned, card, from, to))
+		}
+
+		for _, s := range card.Stickers {
+			if !before.Stickers.Contains(s.Name) {
+				changes = append(changes, newChange(ChangeStickerAdded, card, "", s.Name))
+			}
+		}
+
+		for _, s := range before.Stickers {
+			if !card.Stickers.Contains(s.Name) {
+				changes = append(changes, newChange(ChangeStickerRemoved, card, s.Name, ""))
+			}
+		}
+	}
+
+	for _, card := range old {
+		if !current[cardKey(card)] && card.Status != StatusDone.String() {
+			changes = append(changes, newChange(ChangeRemoved, card, card.Status, ""))
+		}
+	}
+
+	return changes
+}
+
+func newChange(t ChangeType, card *Card, from, to string) Change {
+	return Change{
+		Type:    t,
+		CardID:  card.ID,
+		Project: card.Project,
+		Title:   card.Title,
+		URL:     card.URL,
+		From:    from,
+		To:      to,
+	}
+}
+
+func cardKey(card *Card) string {
+	return fmt.Sprintf("%s/%d", card.Project, card.ID)
+}
+
+func assigneeNames(members Members) string {
+	names := []string{}
+
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+
+		name := m.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", m.ID)
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
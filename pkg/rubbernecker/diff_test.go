@@ -0,0 +1,116 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Diff", func() {
+	var (
+		alice = &rubbernecker.Member{ID: 1, Name: "Alice"}
+		bob   = &rubbernecker.Member{ID: 2, Name: "Bob"}
+	)
+
+	It("should not report anything for the same cards", func() {
+		cards := rubbernecker.Cards{
+			&rubbernecker.Card{ID: 1, Status: "doing", Assignees: rubbernecker.Members{1: alice}},
+		}
+
+		Expect(rubbernecker.Diff(cards, cards)).To(BeEmpty())
+	})
+
+	It("should report created and removed cards", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Title: "Old", Status: "doing"}},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 2, Title: "New", Status: "next"}},
+		)
+
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeCreated))
+		Expect(changes[0].Title).To(Equal("New"))
+		Expect(changes[0].To).To(Equal("next"))
+		Expect(changes[1].Type).To(Equal(rubbernecker.ChangeRemoved))
+		Expect(changes[1].Title).To(Equal("Old"))
+		Expect(changes[1].From).To(Equal("doing"))
+	})
+
+	It("should not report the done cards which have aged out as removed", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{
+				&rubbernecker.Card{ID: 1, Title: "Old", Status: rubbernecker.StatusDone.String()},
+				&rubbernecker.Card{ID: 2, Title: "Doing", Status: "doing"},
+			},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 2, Title: "Doing", Status: "doing"}},
+		)
+
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("should tell the cards of different projects apart", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Project: "platform"}},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Project: "tenant"}},
+		)
+
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeCreated))
+		Expect(changes[0].Project).To(Equal("tenant"))
+		Expect(changes[1].Type).To(Equal(rubbernecker.ChangeRemoved))
+		Expect(changes[1].Project).To(Equal("platform"))
+	})
+
+	It("should report moved cards", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Status: "doing"}},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Status: "reviewing"}},
+		)
+
+		Expect(changes).To(ConsistOf(rubbernecker.Change{
+			Type:   rubbernecker.ChangeMoved,
+			CardID: 1,
+			From:   "doing",
+			To:     "reviewing",
+		}))
+	})
+
+	It("should report reassigned cards", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Assignees: rubbernecker.Members{1: alice}}},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Assignees: rubbernecker.Members{2: bob, 1: alice}}},
+		)
+
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeReassigned))
+		Expect(changes[0].From).To(Equal("Alice"))
+		Expect(changes[0].To).To(Equal("Alice, Bob"))
+	})
+
+	It("should report stickers added and removed", func() {
+		changes := rubbernecker.Diff(
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Stickers: rubbernecker.Stickers{{Name: "blocked"}, {Name: "pairing"}}}},
+			rubbernecker.Cards{&rubbernecker.Card{ID: 1, Stickers: rubbernecker.Stickers{{Name: "pairing"}, {Name: "small"}}}},
+		)
+
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeStickerAdded))
+		Expect(changes[0].To).To(Equal("small"))
+		Expect(changes[1].Type).To(Equal(rubbernecker.ChangeStickerRemoved))
+		Expect(changes[1].From).To(Equal("blocked"))
+	})
+
+	It("should filter the changes Since() a version", func() {
+		now := time.Now()
+		changes := rubbernecker.Changes{
+			{At: now.Add(-time.Hour), Title: "Old"},
+			{At: now, Title: "New"},
+		}
+
+		Expect(changes.Since(now.Add(-time.Minute))).To(HaveLen(1))
+		Expect(changes.Since(now)).To(BeEmpty())
+		Expect(changes.Since(time.Time{})).To(HaveLen(2))
+	})
+})
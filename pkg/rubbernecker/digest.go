@@ -0,0 +1,236 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// blockerStickers are the stickers which keep the cards from being worked on.
+var blockerStickers = []string{"blocked", "scheduled"}
+
+// Blocker will be a rubbernecker representation of a card which cannot be
+// worked on, along with the titles of the stickers telling why.
+type Blocker struct {
+	Card    *Card
+	Reasons []string
+}
+
+// Digest will be a rubbernecker representation of the summary of the board for
+// the standup.
+type Digest struct {
+	Date       time.Time
+	Since      time.Time
+	StuckAfter int
+	Moved      Changes
+	Stuck      Cards
+	Blocked    []Blocker
+	Free       Members
+	Support    SupportRota
+
+	workflow Workflow
+	rota     Rota
+}
+
+// NewDigest will summarise the board as of the given date. The cards moved
+// since the given time are listed, as well as the cards in play for more than
+// the working days and the ones which cannot be worked on. The support roles
+// are named after the labels of the rota.
+func NewDigest(snapshot *Snapshot, changes Changes, workflow Workflow, rota Rota, free Members, date, since time.Time, stuckAfter int) *Digest {
+	d := &Digest{
+		Date:       date,
+		Since:      since,
+		StuckAfter: stuckAfter,
+		Moved:      Changes{},
+		Stuck:      Cards{},
+		Blocked:    []Blocker{},
+		Free:       free,
+		Support:    snapshot.Support,
+		workflow:   workflow,
+		rota:       rota,
+	}
+
+	for _, c := range changes.Since(since) {
+		if c.Type == ChangeMoved {
+			d.Moved = append(d.Moved, c)
+		}
+	}
+
+	for _, column := range workflow.InPlay() {
+		for _, card := range snapshot.Cards.Filter(column.Name) {
+			if card.Elapsed > stuckAfter {
+				d.Stuck = append(d.Stuck, card)
+			}
+		}
+	}
+	sort.SliceStable(d.Stuck, func(i, j int) bool {
+		return d.Stuck[i].Elapsed > d.Stuck[j].Elapsed
+	})
+
+	for _, card := range snapshot.Cards {
+		reasons := []string{}
+		for _, s := range card.Stickers {
+			for _, name := range blockerStickers {
+				if s.Name == name {
+					reasons = append(reasons, s.Title)
+				}
+			}
+		}
+
+		if len(reasons) > 0 {
+			d.Blocked = append(d.Blocked, Blocker{Card: card, Reasons: reasons})
+		}
+	}
+
+	return d
+}
+
+// digestFormat describes how each part of the digest is written out.
+type digestFormat struct {
+	title   func(string) string
+	heading func(string) string
+	item    func(string) string
+	card    func(*Card) string
+}
+
+var markdownFormat = digestFormat{
+	title:   func(s string) string { return "# " + s + "\n" },
+	heading: func(s string) string { return "\n## " + s + "\n\n" },
+	item:    func(s string) string { return "- " + s + "\n" },
+	card: func(c *Card) string {
+		title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(c.Title)
+		if c.URL == "" {
+			return title
+		}
+		return fmt.Sprintf("[%s](%s)", title, c.URL)
+	},
+}
+
+var textFormat = digestFormat{
+	title:   func(s string) string { return s + "\n" },
+	heading: func(s string) string { return "\n" + s + ":\n" },
+	item:    func(s string) string { return "  * " + s + "\n" },
+	card: func(c *Card) string {
+		if c.URL == "" {
+			return c.Title
+		}
+		return fmt.Sprintf("%s (%s)", c.Title, c.URL)
+	},
+}
+
+// Markdown will write out the digest to be pasted into the chat.
+func (d *Digest) Markdown() string {
+	return d.render(markdownFormat)
+}
+
+// Text will write out the digest in plain text, to be emailed.
+func (d *Digest) Text() string {
+	return d.render(textFormat)
+}
+
+func (d *Digest) render(f digestFormat) string {
+	b := &strings.Builder{}
+	day := "Mon 2 Jan"
+
+	b.WriteString(f.title("Standup digest for " + d.Date.Format(day)))
+
+	b.WriteString(f.heading("Moved since " + d.Since.Format(day)))
+	for _, c := range d.Moved {
+		card := &Card{Title: c.Title, URL: c.URL}
+		b.WriteString(f.item(fmt.Sprintf("%s: %s to %s", f.card(card), d.columnTitle(c.From), d.columnTitle(c.To))))
+	}
+	if len(d.Moved) == 0 {
+		b.WriteString(f.item("Nothing has moved"))
+	}
+
+	b.WriteString(f.heading(fmt.Sprintf("In play for more than %d working days", d.StuckAfter)))
+	for _, card := range d.Stuck {
+		b.WriteString(f.item(fmt.Sprintf("%s: %s for %d working days", f.card(card), d.columnTitle(card.Status), card.Elapsed)))
+	}
+	if len(d.Stuck) == 0 {
+		b.WriteString(f.item("Nothing is stuck"))
+	}
+
+	b.WriteString(f.heading("Blocked or scheduled"))
+	for _, blocker := range d.Blocked {
+		b.WriteString(f.item(fmt.Sprintf("%s: %s", f.card(blocker.Card), strings.Join(blocker.Reasons, "; "))))
+	}
+	if len(d.Blocked) == 0 {
+		b.WriteString(f.item("Nothing is blocked"))
+	}
+
+	b.WriteString(f.heading("Free to pick up new work"))
+	names := []string{}
+	for _, m := range d.Free {
+		if m != nil {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(f.item(name))
+	}
+	if len(names) == 0 {
+		b.WriteString(f.item("Nobody"))
+	}
+
+	b.WriteString(f.heading("On support"))
+	roles := d.supportRoles()
+	for _, role := range roles {
+		b.WriteString(f.item(fmt.Sprintf("%s: %s", role.label, d.Support.Get(role.key).Member)))
+	}
+	if len(roles) == 0 {
+		b.WriteString(f.item("Unknown"))
+	}
+
+	return b.String()
+}
+
+type supportRole struct {
+	key   string
+	label string
+}
+
+// supportRoles lists the support roles in the order of the rota, named after
+// their labels as given by Rota.Label. The roles missing from the rota come
+// last, named after their keys.
+func (d *Digest) supportRoles() []supportRole {
+	roles := []supportRole{}
+	seen := map[string]bool{}
+
+	for _, group := range d.rota.Groups() {
+		for _, e := range group.Entries {
+			if _, ok := d.Support[e.Key]; !ok {
+				continue
+			}
+			seen[e.Key] = true
+
+			roles = append(roles, supportRole{key: e.Key, label: d.rota.Label(e.Key)})
+		}
+	}
+
+	rest := []string{}
+	for key := range d.Support {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	for _, key := range rest {
+		roles = append(roles, supportRole{key: key, label: key})
+	}
+
+	return roles
+}
+
+// columnTitle finds the title of the column the cards of the status are in,
+// falling back to the status itself.
+func (d *Digest) columnTitle(status string) string {
+	if column, ok := d.workflow.Column(status); ok && column.Title != "" {
+		return column.Title
+	}
+
+	return status
+}
@@ -0,0 +1,135 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Digest", func() {
+	var (
+		date     time.Time
+		since    time.Time
+		snapshot *rubbernecker.Snapshot
+		changes  rubbernecker.Changes
+		free     rubbernecker.Members
+	)
+
+	BeforeEach(func() {
+		date = time.Date(2018, 10, 22, 9, 30, 0, 0, time.UTC)
+		since = time.Date(2018, 10, 19, 0, 0, 0, 0, time.UTC)
+
+		snapshot = &rubbernecker.Snapshot{
+			Cards: rubbernecker.Cards{
+				{ID: 1, Title: "Fix [the] build", URL: "https://example.com/1", Status: "doing", Elapsed: 5},
+				{ID: 2, Title: "Upgrade the database", Status: "reviewing", Elapsed: 1, Stickers: rubbernecker.Stickers{
+					{Name: "blocked", Title: "Blocked! See story comments for more details"},
+					{Name: "pairing", Title: "Pairing: some"},
+				}},
+				{ID: 3, Title: "Rotate the certificates", Status: "next", Elapsed: 9, Stickers: rubbernecker.Stickers{
+					{Name: "scheduled", Title: "Scheduled on or after 1st November"},
+				}},
+			},
+			Support: rubbernecker.SupportRota{
+				"out-of-hours":   {Type: "out-of-hours", Member: "Bob"},
+				"in-hours":       {Type: "in-hours", Member: "Alice"},
+				"in-hours-comms": {Type: "in-hours-comms", Member: "Carol"},
+			},
+		}
+
+		changes = rubbernecker.Changes{
+			{At: since.Add(-time.Hour), Type: rubbernecker.ChangeMoved, Title: "Too long ago", From: "next", To: "doing"},
+			{At: since.Add(time.Hour), Type: rubbernecker.ChangeMoved, Title: "Upgrade the database", From: "doing", To: "reviewing"},
+			{At: since.Add(time.Hour), Type: rubbernecker.ChangeStickerAdded, Title: "Upgrade the database", To: "blocked"},
+		}
+
+		free = rubbernecker.Members{
+			2: {ID: 2, Name: "Carol"},
+			1: {ID: 1, Name: "Alice"},
+		}
+	})
+
+	It("should summarise the board with NewDigest()", func() {
+		digest := rubbernecker.NewDigest(snapshot, changes, rubbernecker.DefaultWorkflow(), rubbernecker.DefaultRota(), free, date, since, 3)
+
+		Expect(digest.Moved).To(Equal(rubbernecker.Changes{changes[1]}))
+		Expect(digest.Stuck).To(Equal(rubbernecker.Cards{snapshot.Cards[0]}))
+		Expect(digest.Blocked).To(Equal([]rubbernecker.Blocker{
+			{Card: snapshot.Cards[1], Reasons: []string{"Blocked! See story comments for more details"}},
+			{Card: snapshot.Cards[2], Reasons: []string{"Scheduled on or after 1st November"}},
+		}))
+	})
+
+	It("should write out the digest in Markdown()", func() {
+		digest := rubbernecker.NewDigest(snapshot, changes, rubbernecker.DefaultWorkflow(), rubbernecker.DefaultRota(), free, date, since, 3)
+
+		Expect(digest.Markdown()).To(Equal(`# Standup digest for Mon 22 Oct
+
+## Moved since Fri 19 Oct
+
+- Upgrade the database: Doing to Reviewing
+
+## In play for more than 3 working days
+
+- [Fix \[the\] build](https://example.com/1): Doing for 5 working days
+
+## Blocked or scheduled
+
+- Upgrade the database: Blocked! See story comments for more details
+- Rotate the certificates: Scheduled on or after 1st November
+
+## Free to pick up new work
+
+- Alice
+- Carol
+
+## On support
+
+- In hours: Alice
+- Comms (In hours): Carol
+- Out of hours: Bob
+`))
+	})
+
+	It("should write out the empty digest in Text()", func() {
+		digest := rubbernecker.NewDigest(&rubbernecker.Snapshot{}, rubbernecker.Changes{}, rubbernecker.DefaultWorkflow(), rubbernecker.DefaultRota(), nil, date, since, 3)
+
+		Expect(digest.Text()).To(Equal(`Standup digest for Mon 22 Oct
+
+Moved since Fri 19 Oct:
+  * Nothing has moved
+
+In play for more than 3 working days:
+  * Nothing is stuck
+
+Blocked or scheduled:
+  * Nothing is blocked
+
+Free to pick up new work:
+  * Nobody
+
+On support:
+  * Unknown
+`))
+	})
+
+	It("should write out the digest in Text()", func() {
+		digest := rubbernecker.NewDigest(snapshot, changes, rubbernecker.DefaultWorkflow(), rubbernecker.DefaultRota(), free, date, since, 3)
+
+		Expect(digest.Text()).To(ContainSubstring("  * Fix [the] build (https://example.com/1): Doing for 5 working days\n"))
+	})
+	It("should name the support roles missing from the rota after their keys", func() {
+		digest := rubbernecker.NewDigest(snapshot, changes, rubbernecker.DefaultWorkflow(), rubbernecker.Rota{
+			{Key: "out-of-hours", Label: "Out of hours", Group: "out-of-hours"},
+		}, free, date, since, 3)
+
+		Expect(digest.Text()).To(HaveSuffix(`On support:
+  * Out of hours: Bob
+  * in-hours: Alice
+  * in-hours-comms: Carol
+`))
+	})
+})
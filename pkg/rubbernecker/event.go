@@ -0,0 +1,62 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// EventLimitBreached is when a column, or a person within it, goes over
+	// the limit.
+	EventLimitBreached = "limit-breached"
+	// EventLimitRecovered is when a column, or a person within it, is back
+	// within the limit.
+	EventLimitRecovered = "limit-recovered"
+	// EventCardRejected is when a card is moved into the rejected column.
+	EventCardRejected = "card-rejected"
+	// EventCardStuck is when a card has been in a column for too long.
+	EventCardStuck = "card-stuck"
+	// EventSupportChanged is when someone else is on support.
+	EventSupportChanged = "support-changed"
+)
+
+// Event will be a rubbernecker representation of something happening on the
+// board the team should be told about.
+type Event struct {
+	Kind   string    `json:"kind"`
+	At     time.Time `json:"at"`
+	Text   string    `json:"text"`
+	Breach *Breach   `json:"breach,omitempty"`
+	Card   *Card     `json:"card,omitempty"`
+}
+
+// LimitEvents will compose the events of the limits breached and recovered.
+func LimitEvents(breached, recovered Breaches, at time.Time) []Event {
+	events := []Event{}
+
+	for i := range breached {
+		events = append(events, Event{
+			Kind:   EventLimitBreached,
+			At:     at,
+			Text:   breached[i].String(),
+			Breach: &breached[i],
+		})
+	}
+
+	for i := range recovered {
+		b := recovered[i]
+		text := fmt.Sprintf("%s is back within the limit of %d", b.Column, b.Limit)
+		if b.MemberID != 0 {
+			text = fmt.Sprintf("%s is back within the limit of %d in %s", b.Member, b.Limit, b.Column)
+		}
+
+		events = append(events, Event{
+			Kind:   EventLimitRecovered,
+			At:     at,
+			Text:   text,
+			Breach: &recovered[i],
+		})
+	}
+
+	return events
+}
@@ -1,168 +1,109 @@
 package rubbernecker
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 )
 
+// Filter is a quick filter of the cards shown on the board.
 type Filter interface {
 	QueryText() string
 	DisplayText() string
+	GroupName() string
 	IsApplied(queries []string) bool
 }
 
-func DefaultFilterSet() []Filter {
-	return []Filter{
-		&DecommissionFilter{},
-		&CoreWorkFilter{},
-		&BlockedFilter{},
-		&ScheduledFilter{},
-		&CommentsToResolveFilter{},
-		&SmallTaskFilter{},
-		&DocumentationFilter{},
-		&PairingFilter{},
-		&NonTechFilter{},
-		&TechFilter{},
-	}
-}
-
-type BlockedFilter struct{}
-
-func (*BlockedFilter) QueryText() string {
-	return "filter=sticker:blocked"
-}
-
-func (*BlockedFilter) DisplayText() string {
-	return "Blocked"
-}
-
-func (*BlockedFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:blocked")
-}
-
-type ScheduledFilter struct{}
-
-func (*ScheduledFilter) QueryText() string {
-	return "filter=sticker:scheduled"
+// SavedFilter is a quick filter of the cards, as defined in the configuration.
+// The filters are shown by their order, and then as defined, optionally
+// grouped.
+type SavedFilter struct {
+	Display string `yaml:"display" json:"display"`
+	Query   string `yaml:"query" json:"query"`
+	Group   string `yaml:"group" json:"group,omitempty"`
+	Order   int    `yaml:"order" json:"order,omitempty"`
 }
 
-func (*ScheduledFilter) DisplayText() string {
-	return "Scheduled"
+// QueryText is the query string applying the filter.
+func (f SavedFilter) QueryText() string {
+	return url.Values{"filter": {f.Query}}.Encode()
 }
 
-func (*ScheduledFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:scheduled")
-}
-
-type CommentsToResolveFilter struct{}
-
-func (*CommentsToResolveFilter) QueryText() string {
-	return "filter=sticker:comments-to-resolve"
+// DisplayText is what the filter is shown as.
+func (f SavedFilter) DisplayText() string {
+	return f.Display
 }
 
-func (*CommentsToResolveFilter) DisplayText() string {
-	return "Comments to resolve"
+// GroupName is the name of the group the filter is shown in, if any.
+func (f SavedFilter) GroupName() string {
+	return f.Group
 }
 
-func (*CommentsToResolveFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:comments-to-resolve")
+// IsApplied reports whether the filter is one of the queries.
+func (f SavedFilter) IsApplied(queries []string) bool {
+	return isApplied(queries, f.Query)
 }
 
-type SmallTaskFilter struct{}
-
-func (SmallTaskFilter) QueryText() string {
-	return "filter=sticker:'small' task"
-}
-
-func (SmallTaskFilter) DisplayText() string {
-	return "Small tasks"
-}
-
-func (SmallTaskFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:'small' task")
-}
-
-type DocumentationFilter struct{}
-
-func (*DocumentationFilter) QueryText() string {
-	return "filter=sticker:documentation"
-}
-
-func (*DocumentationFilter) DisplayText() string {
-	return "Documentation"
-}
-
-func (*DocumentationFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:documentation")
-}
+// SavedFilters will be a rubbernecker representation of all the quick filters.
+type SavedFilters []SavedFilter
 
-type PairingFilter struct{}
-
-func (*PairingFilter) QueryText() string {
-	return "filter=sticker:pairing"
-}
-
-func (*PairingFilter) DisplayText() string {
-	return "Pairing"
-}
-
-func (*PairingFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:pairing")
-}
-
-type NonTechFilter struct{}
-
-func (*NonTechFilter) QueryText() string {
-	return "filter=sticker:non-tech"
-}
-
-func (*NonTechFilter) DisplayText() string {
-	return "Non-tech"
-}
-
-func (*NonTechFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:non-tech")
-}
-
-type TechFilter struct{}
-
-func (*TechFilter) QueryText() string {
-	return "filter=not-sticker:non-tech"
-}
+// Validate will make sure each of the filters is shown as something and its
+// query can be parsed.
+func (fs SavedFilters) Validate() error {
+	for i, f := range fs {
+		if f.Display == "" {
+			return fmt.Errorf("rubbernecker: filter %d has no display text", i+1)
+		}
 
-func (*TechFilter) DisplayText() string {
-	return "Tech"
-}
+		if _, err := ParseQuery(f.Query); err != nil {
+			return fmt.Errorf("rubbernecker: filter %q: %s", f.Display, err)
+		}
+	}
 
-func (*TechFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "not-sticker:non-tech")
+	return nil
 }
 
-type DecommissionFilter struct{}
+// Filters will sort the filters by their order, leaving the ones of the same
+// order as defined.
+func (fs SavedFilters) Filters() []Filter {
+	sorted := append(SavedFilters{}, fs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
 
-func (*DecommissionFilter) QueryText() string {
-	return "filter=sticker:decommission"
-}
+	filters := make([]Filter, 0, len(sorted))
+	for _, f := range sorted {
+		filters = append(filters, f)
+	}
 
-func (*DecommissionFilter) DisplayText() string {
-	return "Decommission"
+	return filters
 }
 
-func (*DecommissionFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:decommission")
+// FilterGroup is a named group of the quick filters.
+type FilterGroup struct {
+	Name    string
+	Filters []Filter
 }
 
-type CoreWorkFilter struct{}
+// GroupFilters will group the filters by their group name, in the order the
+// groups first appear in.
+func GroupFilters(filters []Filter) []FilterGroup {
+	groups := []FilterGroup{}
+	index := map[string]int{}
 
-func (*CoreWorkFilter) QueryText() string {
-	return "filter=sticker:core-work"
-}
+	for _, f := range filters {
+		i, ok := index[f.GroupName()]
+		if !ok {
+			i = len(groups)
+			index[f.GroupName()] = i
+			groups = append(groups, FilterGroup{Name: f.GroupName()})
+		}
 
-func (*CoreWorkFilter) DisplayText() string {
-	return "Core work"
-}
+		groups[i].Filters = append(groups[i].Filters, f)
+	}
 
-func (*CoreWorkFilter) IsApplied(queries []string) bool {
-	return isApplied(queries, "sticker:core-work")
+	return groups
 }
 
 func isApplied(queries []string, term string) bool {
@@ -7,113 +7,76 @@ import (
 )
 
 var _ = Describe("Filter", func() {
-	Describe("blocked filter", func() {
-		It("is applied when the queries contain 'sticker:blocked'", func() {
-			queries := []string{"bar", "sticker:blocked", "foo"}
-
-			filter := rubbernecker.BlockedFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
-		})
-	})
-
-	Describe("scheduled filter", func() {
-		It("is applied when the queries contain 'sticker:scheduled'", func() {
-			queries := []string{"bar", "sticker:scheduled", "foo"}
-
-			filter := rubbernecker.ScheduledFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
+	Describe("saved filter", func() {
+		It("is applied when the queries contain its query", func() {
+			for _, query := range []string{
+				"sticker:blocked",
+				"sticker:scheduled",
+				"sticker:comments-to-resolve",
+				"sticker:'small' task",
+				"sticker:documentation",
+				"not-sticker:non-tech",
+			} {
+				queries := []string{"bar", query, "foo"}
+
+				filter := rubbernecker.SavedFilter{Display: "Test", Query: query}
+				actual := filter.IsApplied(queries)
+
+				Expect(actual).To(BeTrue(), query)
+			}
 		})
-	})
 
-	Describe("comments to resolve filter", func() {
-		It("is applied when the queries contain 'sticker:comments-to-resolve'", func() {
-			queries := []string{"bar", "sticker:comments-to-resolve", "foo"}
+		It("is not applied when the queries do not contain its query", func() {
+			filter := rubbernecker.SavedFilter{Display: "Tech", Query: "not-sticker:non-tech"}
 
-			filter := rubbernecker.CommentsToResolveFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
+			Expect(filter.IsApplied([]string{"sticker:non-tech"})).To(BeFalse())
 		})
-	})
 
-	Describe("small task filter", func() {
-		It("is applied when the queries contain 'sticker:'small' task'", func() {
-			queries := []string{"bar", "sticker:'small' task", "foo"}
+		It("is applied with the query text", func() {
+			filter := rubbernecker.SavedFilter{Display: "Small tasks", Query: "sticker:'small' task"}
 
-			filter := rubbernecker.SmallTaskFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
+			Expect(filter.QueryText()).To(Equal("filter=sticker%3A%27small%27+task"))
+			Expect(filter.DisplayText()).To(Equal("Small tasks"))
 		})
 	})
 
-	Describe("documentation filter", func() {
-		It("is applied when the queries contain 'sticker:documentationm", func() {
-			queries := []string{"bar", "sticker:documentation", "foo"}
-
-			filter := rubbernecker.DocumentationFilter{}
-			actual := filter.IsApplied(queries)
+	Describe("saved filters", func() {
+		var filters rubbernecker.SavedFilters
 
-			Expect(actual).To(BeTrue())
+		BeforeEach(func() {
+			filters = rubbernecker.SavedFilters{
+				{Display: "Blocked", Query: "sticker:blocked"},
+				{Display: "Security", Query: "sticker:security OR title:cve", Group: "Incidents", Order: -1},
+				{Display: "Scheduled", Query: "sticker:scheduled"},
+				{Display: "Incidents", Query: "type:bug", Group: "Incidents", Order: 1},
+			}
 		})
-	})
 
-	Describe("pairing filter", func() {
-		It("is applied when the queries contain 'sticker:pairing'", func() {
-			queries := []string{"bar", "sticker:pairing", "foo"}
+		It("should Validate() the filters", func() {
+			Expect(filters.Validate()).To(Succeed())
 
-			filter := rubbernecker.PairingFilter{}
-			actual := filter.IsApplied(queries)
+			invalid := rubbernecker.SavedFilters{{Query: "sticker:blocked"}}
+			Expect(invalid.Validate()).To(MatchError(ContainSubstring("filter 1 has no display text")))
 
-			Expect(actual).To(BeTrue())
+			invalid = rubbernecker.SavedFilters{{Display: "Unknown", Query: "colour:red"}}
+			Expect(invalid.Validate()).To(MatchError(ContainSubstring(`filter "Unknown"`)))
 		})
-	})
-
-	Describe("non-tech filter", func() {
-		It("is applied when the queries contain 'sticker:non-tech'", func() {
-			queries := []string{"bar", "sticker:non-tech", "foo"}
-
-			filter := rubbernecker.NonTechFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
-		})
-	})
-
-	Describe("tech filter", func() {
-		It("is applied when the queries contain 'not-sticker:non-tech'", func() {
-			queries := []string{"bar", "not-sticker:non-tech", "foo"}
-
-			filter := rubbernecker.TechFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
-		})
-	})
-
-	Describe("core-work filter", func() {
-		It("is applied when the queries contain 'sticker:core-work'", func() {
-			queries := []string{"bar", "sticker:core-work", "foo"}
-
-			filter := rubbernecker.CoreWorkFilter{}
-			actual := filter.IsApplied(queries)
-
-			Expect(actual).To(BeTrue())
-		})
-	})
-
-	Describe("decommission filter", func() {
-		It("is applied when the queries contain 'sticker:decommission'", func() {
-			queries := []string{"bar", "sticker:decommission", "foo"}
-
-			filter := rubbernecker.DecommissionFilter{}
-			actual := filter.IsApplied(queries)
 
-			Expect(actual).To(BeTrue())
+		It("should sort the Filters() by their order and group them", func() {
+			sorted := filters.Filters()
+
+			display := []string{}
+			for _, f := range sorted {
+				display = append(display, f.DisplayText())
+			}
+			Expect(display).To(Equal([]string{"Security", "Blocked", "Scheduled", "Incidents"}))
+
+			groups := rubbernecker.GroupFilters(sorted)
+			Expect(groups).To(HaveLen(2))
+			Expect(groups[0].Name).To(Equal("Incidents"))
+			Expect(groups[0].Filters).To(HaveLen(2))
+			Expect(groups[1].Name).To(Equal(""))
+			Expect(groups[1].Filters).To(HaveLen(2))
 		})
 	})
 })
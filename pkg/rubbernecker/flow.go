@@ -0,0 +1,105 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FlowPercentiles are the percentiles the flow metrics are summarised with.
+var FlowPercentiles = []float64{50, 75, 85, 95}
+
+// Flow will be a rubbernecker representation of how a card went through the
+// statuses. All times are in hours. Only the time spent in the statuses the
+// card has already left is taken into account, the time in the current one
+// is represented by the Card.Elapsed.
+type Flow struct {
+	InState   map[string]float64 `json:"in_state_hours"`
+	LeadTime  *float64           `json:"lead_time_hours,omitempty"`
+	CycleTime *float64           `json:"cycle_time_hours,omitempty"`
+}
+
+// Percentiles will be a rubbernecker representation of a distribution, keyed
+// by the percentile, e.g. "p50".
+type Percentiles map[string]float64
+
+// FlowMetrics will summarise the flow of the cards.
+type FlowMetrics struct {
+	Count     int                    `json:"count"`
+	LeadTime  Percentiles            `json:"lead_time_hours"`
+	CycleTime Percentiles            `json:"cycle_time_hours"`
+	InState   map[string]Percentiles `json:"in_state_hours"`
+}
+
+// Hours converts the duration into the hours the flow is measured in, rounded
+// to a minute.
+func Hours(d time.Duration) float64 {
+	return math.Round(d.Minutes()) / 60
+}
+
+// FlowMetrics will compute the percentiles of the lead, cycle and in state
+// times of the cards. Cards with no flow are skipped.
+func (c Cards) FlowMetrics() *FlowMetrics {
+	leadTimes := []float64{}
+	cycleTimes := []float64{}
+	inState := map[string][]float64{}
+	count := 0
+
+	for _, card := range c {
+		if card.Flow == nil {
+			continue
+		}
+
+		count++
+
+		if card.Flow.LeadTime != nil {
+			leadTimes = append(leadTimes, *card.Flow.LeadTime)
+		}
+
+		if card.Flow.CycleTime != nil {
+			cycleTimes = append(cycleTimes, *card.Flow.CycleTime)
+		}
+
+		for state, hours := range card.Flow.InState {
+			inState[state] = append(inState[state], hours)
+		}
+	}
+
+	metrics := &FlowMetrics{
+		Count:     count,
+		LeadTime:  percentiles(leadTimes),
+		CycleTime: percentiles(cycleTimes),
+		InState:   map[string]Percentiles{},
+	}
+
+	for state, values := range inState {
+		metrics.InState[state] = percentiles(values)
+	}
+
+	return metrics
+}
+
+// percentiles uses the nearest-rank method, so that each of them is one of
+// the actual values.
+func percentiles(values []float64) Percentiles {
+	result := Percentiles{}
+
+	if len(values) == 0 {
+		return result
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	for _, p := range FlowPercentiles {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+
+		result[fmt.Sprintf("p%g", p)] = sorted[rank-1]
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Flow", func() {
+	hours := func(h float64) *float64 {
+		return &h
+	}
+
+	It("should convert durations to Hours()", func() {
+		Expect(rubbernecker.Hours(90 * time.Minute)).To(Equal(1.5))
+		Expect(rubbernecker.Hours(20 * time.Second)).To(Equal(0.0))
+	})
+
+	It("should summarise no cards with FlowMetrics()", func() {
+		metrics := rubbernecker.Cards{}.FlowMetrics()
+
+		Expect(metrics.Count).To(Equal(0))
+		Expect(metrics.LeadTime).To(BeEmpty())
+		Expect(metrics.CycleTime).To(BeEmpty())
+		Expect(metrics.InState).To(BeEmpty())
+	})
+
+	It("should compute the percentiles with FlowMetrics()", func() {
+		cards := rubbernecker.Cards{
+			&rubbernecker.Card{},
+		}
+
+		for i := 1; i <= 20; i++ {
+			cards = append(cards, &rubbernecker.Card{Flow: &rubbernecker.Flow{
+				InState:   map[string]float64{"doing": float64(i), "reviewing": 1},
+				LeadTime:  hours(float64(i * 10)),
+				CycleTime: hours(float64(i)),
+			}})
+		}
+
+		cards = append(cards, &rubbernecker.Card{Flow: &rubbernecker.Flow{
+			InState: map[string]float64{"rejected": 3},
+		}})
+
+		metrics := cards.FlowMetrics()
+
+		Expect(metrics.Count).To(Equal(21))
+		Expect(metrics.LeadTime).To(Equal(rubbernecker.Percentiles{"p50": 100, "p75": 150, "p85": 170, "p95": 190}))
+		Expect(metrics.CycleTime).To(Equal(rubbernecker.Percentiles{"p50": 10, "p75": 15, "p85": 17, "p95": 19}))
+		Expect(metrics.InState).To(HaveKeyWithValue("reviewing", rubbernecker.Percentiles{"p50": 1, "p75": 1, "p85": 1, "p95": 1}))
+		Expect(metrics.InState).To(HaveKeyWithValue("rejected", rubbernecker.Percentiles{"p50": 3, "p75": 3, "p85": 3, "p95": 3}))
+	})
+})
@@ -0,0 +1,57 @@
+package rubbernecker
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Incident will be a rubbernecker representation of something on fire, which
+// has not been resolved yet.
+type Incident struct {
+	ID        string     `json:"id"`
+	Number    int        `json:"number,omitempty"`
+	Title     string     `json:"title"`
+	Service   string     `json:"service,omitempty"`
+	Status    string     `json:"status"`
+	Urgency   string     `json:"urgency,omitempty"`
+	Assignees []string   `json:"assignees,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Acknowledged reports whether someone is already dealing with the incident.
+func (i *Incident) Acknowledged() bool {
+	return i.Status == "acknowledged"
+}
+
+// Incidents will be a rubbernecker representation of all the open incidents.
+type Incidents []*Incident
+
+// Sort will order the incidents by their urgency, the ones nobody has
+// acknowledged yet first, and then the oldest first.
+func (is Incidents) Sort() Incidents {
+	sorted := append(Incidents{}, is...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a.Urgency == "high") != (b.Urgency == "high") {
+			return a.Urgency == "high"
+		}
+		if a.Acknowledged() != b.Acknowledged() {
+			return !a.Acknowledged()
+		}
+		if a.CreatedAt == nil || b.CreatedAt == nil {
+			return a.CreatedAt != nil && b.CreatedAt == nil
+		}
+		return a.CreatedAt.Before(*b.CreatedAt)
+	})
+
+	return sorted
+}
+
+// IncidentService interface will establish a standard for any extension
+// handling the incidents.
+type IncidentService interface {
+	FetchIncidents(context.Context) error
+	FlattenIncidents() (Incidents, error)
+}
@@ -0,0 +1,41 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Incident", func() {
+	It("should Sort() the most pressing incidents first", func() {
+		earlier := time.Date(2018, 3, 7, 9, 0, 0, 0, time.UTC)
+		later := earlier.Add(time.Hour)
+
+		incidents := rubbernecker.Incidents{
+			{ID: "low", Urgency: "low", Status: "triggered", CreatedAt: &earlier},
+			{ID: "high-acknowledged", Urgency: "high", Status: "acknowledged", CreatedAt: &earlier},
+			{ID: "high-later", Urgency: "high", Status: "triggered", CreatedAt: &later},
+			{ID: "high-earlier", Urgency: "high", Status: "triggered", CreatedAt: &earlier},
+		}
+
+		ids := []string{}
+		for _, i := range incidents.Sort() {
+			ids = append(ids, i.ID)
+		}
+
+		Expect(ids).To(Equal([]string{"high-earlier", "high-later", "high-acknowledged", "low"}))
+		Expect(incidents[0].ID).To(Equal("low"))
+	})
+
+	It("should PublishIncidents() on the board", func() {
+		board := rubbernecker.NewBoard()
+		incidents := rubbernecker.Incidents{{ID: "PINC123", Status: "triggered"}}
+
+		Expect(board.PublishIncidents(incidents)).To(BeTrue())
+		Expect(board.PublishIncidents(incidents)).To(BeFalse())
+		Expect(board.Snapshot().Incidents).To(Equal(incidents))
+	})
+})
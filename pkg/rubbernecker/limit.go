@@ -0,0 +1,177 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OverLimitSticker is the name of the sticker the cards over the WIP limit are
+// given.
+const OverLimitSticker = "overlimit"
+
+// Breach will be a rubbernecker representation of a column, or a person within
+// the column, holding more cards than the limit allows.
+type Breach struct {
+	Column   string `json:"column"`
+	MemberID int    `json:"member_id,omitempty"`
+	Member   string `json:"member,omitempty"`
+	Count    int    `json:"count"`
+	Limit    int    `json:"limit"`
+}
+
+// Breaches will be a rubbernecker representation of all the limits breached.
+type Breaches []Breach
+
+func (b Breach) key() string {
+	return fmt.Sprintf("%s/%d", b.Column, b.MemberID)
+}
+
+func (b Breach) String() string {
+	if b.MemberID != 0 {
+		return fmt.Sprintf("%s has %d cards in %s, over the limit of %d", b.Member, b.Count, b.Column, b.Limit)
+	}
+
+	return fmt.Sprintf("%s has %d cards, over the limit of %d", b.Column, b.Count, b.Limit)
+}
+
+// Breaches will find the columns holding more cards than their limit, as well
+// as the people holding more cards than the person limit of the column. The
+// cards with several assignees count towards each of them.
+func (w Workflow) Breaches(cards Cards) Breaches {
+	breaches := Breaches{}
+
+	for _, column := range w {
+		inColumn := cards.Filter(column.Name)
+
+		if column.Limit > 0 && len(inColumn) > column.Limit {
+			breaches = append(breaches, Breach{
+				Column: column.Name,
+				Count:  len(inColumn),
+				Limit:  column.Limit,
+			})
+		}
+
+		if column.PersonLimit <= 0 {
+			continue
+		}
+
+		counts := map[int]int{}
+		names := map[int]string{}
+		for _, card := range inColumn {
+			for id, member := range card.Assignees {
+				counts[id]++
+				if member != nil && member.Name != "" {
+					names[id] = member.Name
+				}
+			}
+		}
+
+		ids := make([]int, 0, len(counts))
+		for id, count := range counts {
+			if count > column.PersonLimit {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+
+		for _, id := range ids {
+			name, ok := names[id]
+			if !ok {
+				name = fmt.Sprintf("#%d", id)
+			}
+
+			breaches = append(breaches, Breach{
+				Column:   column.Name,
+				MemberID: id,
+				Member:   name,
+				Count:    counts[id],
+				Limit:    column.PersonLimit,
+			})
+		}
+	}
+
+	return breaches
+}
+
+// OverLimit reports whether the column itself is over its limit.
+func (b Breaches) OverLimit(column string) bool {
+	for _, breach := range b {
+		if breach.Column == column && breach.MemberID == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Compare will tell which of the limits have been breached, and which have
+// recovered, since the previous breaches. A breach getting worse or better is
+// neither.
+func (b Breaches) Compare(previous Breaches) (breached, recovered Breaches) {
+	before := map[string]bool{}
+	for _, breach := range previous {
+		before[breach.key()] = true
+	}
+
+	now := map[string]bool{}
+	for _, breach := range b {
+		now[breach.key()] = true
+
+		if !before[breach.key()] {
+			breached = append(breached, breach)
+		}
+	}
+
+	for _, breach := range previous {
+		if !now[breach.key()] {
+			recovered = append(recovered, breach)
+		}
+	}
+
+	return breached, recovered
+}
+
+// Apply will give the sticker to the cards over the limits breached. The cards
+// which have been in the column for the shortest are the ones over the limit.
+// The cards are copied rather than modified, so that the published snapshots
+// are left intact.
+func (b Breaches) Apply(cards Cards, sticker Sticker) Cards {
+	over := map[*Card]bool{}
+
+	for _, breach := range b {
+		inBreach := Cards{}
+		for _, card := range cards.Filter(breach.Column) {
+			if _, ok := card.Assignees[breach.MemberID]; breach.MemberID == 0 || ok {
+				inBreach = append(inBreach, card)
+			}
+		}
+
+		sort.SliceStable(inBreach, func(i, j int) bool {
+			return inBreach[i].Elapsed > inBreach[j].Elapsed
+		})
+
+		for i := breach.Limit; i < len(inBreach); i++ {
+			over[inBreach[i]] = true
+		}
+	}
+
+	if len(over) == 0 {
+		return cards
+	}
+
+	tmp := make(Cards, 0, len(cards))
+	for _, card := range cards {
+		if !over[card] || card.Stickers.Contains(sticker.Name) {
+			tmp = append(tmp, card)
+			continue
+		}
+
+		c := *card
+		c.Stickers = append(append(Stickers{}, card.Stickers...), sticker)
+		sort.Sort(c.Stickers)
+
+		tmp = append(tmp, &c)
+	}
+
+	return tmp
+}
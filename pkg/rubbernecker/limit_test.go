@@ -0,0 +1,104 @@
+package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Limit", func() {
+	var (
+		alice    *rubbernecker.Member
+		bob      *rubbernecker.Member
+		workflow rubbernecker.Workflow
+		cards    rubbernecker.Cards
+	)
+
+	BeforeEach(func() {
+		alice = &rubbernecker.Member{ID: 1, Name: "Alice"}
+		bob = &rubbernecker.Member{ID: 2, Name: "Bob"}
+
+		workflow = rubbernecker.Workflow{
+			{Name: "doing", PersonLimit: 1},
+			{Name: "reviewing", Limit: 2},
+			{Name: "done"},
+		}
+
+		cards = rubbernecker.Cards{
+			{ID: 1, Status: "doing", Elapsed: 3, Assignees: rubbernecker.Members{1: alice, 2: bob}},
+			{ID: 2, Status: "doing", Elapsed: 1, Assignees: rubbernecker.Members{1: alice}},
+			{ID: 3, Status: "reviewing", Elapsed: 4},
+			{ID: 4, Status: "reviewing", Elapsed: 1, Stickers: rubbernecker.Stickers{{Name: "blocked"}}},
+			{ID: 5, Status: "reviewing", Elapsed: 2},
+		}
+	})
+
+	It("should find the Breaches() of the column and person limits", func() {
+		breaches := workflow.Breaches(cards)
+
+		Expect(breaches).To(Equal(rubbernecker.Breaches{
+			{Column: "doing", MemberID: 1, Member: "Alice", Count: 2, Limit: 1},
+			{Column: "reviewing", Count: 3, Limit: 2},
+		}))
+		Expect(breaches.OverLimit("reviewing")).To(BeTrue())
+		Expect(breaches.OverLimit("doing")).To(BeFalse())
+		Expect(breaches[0].String()).To(Equal("Alice has 2 cards in doing, over the limit of 1"))
+		Expect(breaches[1].String()).To(Equal("reviewing has 3 cards, over the limit of 2"))
+	})
+
+	It("should find no Breaches() within the limits", func() {
+		Expect(workflow.Breaches(cards[2:4])).To(BeEmpty())
+	})
+
+	It("should Apply() the sticker to the cards which went over the limits last", func() {
+		sticker := rubbernecker.Sticker{Name: rubbernecker.OverLimitSticker}
+
+		applied := workflow.Breaches(cards).Apply(cards, sticker)
+
+		Expect(applied).To(HaveLen(5))
+		Expect(applied[0].Stickers).To(BeEmpty())
+		Expect(applied[1].Stickers).To(Equal(rubbernecker.Stickers{sticker}))
+		Expect(applied[2].Stickers).To(BeEmpty())
+		Expect(applied[3].Stickers.Contains("blocked")).To(BeTrue())
+		Expect(applied[3].Stickers.Contains(rubbernecker.OverLimitSticker)).To(BeTrue())
+		Expect(applied[4].Stickers).To(BeEmpty())
+
+		Expect(cards[1].Stickers).To(BeEmpty())
+		Expect(cards[3].Stickers).To(HaveLen(1))
+	})
+
+	It("should Compare() the breaches with the previous ones", func() {
+		previous := rubbernecker.Breaches{
+			{Column: "reviewing", Count: 3, Limit: 2},
+			{Column: "doing", MemberID: 2, Member: "Bob", Count: 2, Limit: 1},
+		}
+		current := rubbernecker.Breaches{
+			{Column: "reviewing", Count: 4, Limit: 2},
+			{Column: "doing", MemberID: 1, Member: "Alice", Count: 2, Limit: 1},
+		}
+
+		breached, recovered := current.Compare(previous)
+
+		Expect(breached).To(Equal(rubbernecker.Breaches{current[1]}))
+		Expect(recovered).To(Equal(rubbernecker.Breaches{previous[1]}))
+	})
+
+	It("should compose the LimitEvents()", func() {
+		at := time.Unix(1539856800, 0)
+		breached := rubbernecker.Breaches{{Column: "reviewing", Count: 3, Limit: 2}}
+		recovered := rubbernecker.Breaches{{Column: "doing", MemberID: 2, Member: "Bob", Count: 2, Limit: 1}}
+
+		events := rubbernecker.LimitEvents(breached, recovered, at)
+
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Kind).To(Equal(rubbernecker.EventLimitBreached))
+		Expect(events[0].At).To(Equal(at))
+		Expect(events[0].Text).To(Equal("reviewing has 3 cards, over the limit of 2"))
+		Expect(events[0].Breach).To(Equal(&breached[0]))
+		Expect(events[1].Kind).To(Equal(rubbernecker.EventLimitRecovered))
+		Expect(events[1].Text).To(Equal("Bob is back within the limit of 1 in doing"))
+	})
+})
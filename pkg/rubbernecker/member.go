@@ -1,5 +1,7 @@
 package rubbernecker
 
+import "context"
+
 // Member will be a rubbernecker entity composed of the extension.
 type Member struct {
 	ID    int    `json:"id"`
@@ -13,6 +15,6 @@ type Members map[int]*Member
 // MemberService interface will establish a standard for any extension handling
 // support data.
 type MemberService interface {
-	FetchMembers() error
+	FetchMembers(context.Context) error
 	FlattenMembers() (Members, error)
 }
@@ -1,8 +1,192 @@
 package rubbernecker
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LatestSnapshotKey is the key the most recent snapshot of the board is
+// persisted under. The snapshots are also kept under their own keys for a
+// while, see SnapshotKey and SaveSnapshot.
+const LatestSnapshotKey = "snapshot.latest"
+
+const snapshotPrefix = "snapshot."
+
 // PersistanceEngine interface should ensure any backing service will follow the
 // same set of rules.
 type PersistanceEngine interface {
 	Get(key string) (interface{}, error)
 	Put(key string, value interface{}) error
+	Delete(key string) error
+	Keys() ([]string, error)
+	List(prefix string) (map[string]interface{}, error)
+}
+
+// ExpiringPersistanceEngine is implemented by the engines which can expire
+// the values by themselves.
+type ExpiringPersistanceEngine interface {
+	PersistanceEngine
+	PutWithTTL(key string, value interface{}, ttl time.Duration) error
+}
+
+// GetAs will get a specific value from the engine as the given type. Values
+// kept as raw JSON bytes, such as the ones stored in files, are decoded.
+func GetAs[T any](engine PersistanceEngine, key string) (T, error) {
+	value, err := engine.Get(key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return convert[T](key, value)
+}
+
+// ListAs will list all the values from the engine with keys starting with the
+// prefix as the given type. Values kept as raw JSON bytes are decoded.
+func ListAs[T any](engine PersistanceEngine, prefix string) (map[string]T, error) {
+	values, err := engine.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make(map[string]T, len(values))
+
+	for key, value := range values {
+		typed[key], err = convert[T](key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return typed, nil
+}
+
+func convert[T any](key string, value interface{}) (T, error) {
+	var typed T
+
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		return typed, fmt.Errorf("rubbernecker: unexpected value of type %T under %s, expected %T", value, key, typed)
+	}
+
+	err := json.Unmarshal(data, &typed)
+	if err != nil {
+		return typed, fmt.Errorf("rubbernecker: could not decode %s: %s", key, err)
+	}
+
+	return typed, nil
+}
+
+// SnapshotKey is the key a snapshot is persisted under, composed of its
+// timestamp in the same format as the ETag.
+func SnapshotKey(s *Snapshot) string {
+	return fmt.Sprintf("%s%d", snapshotPrefix, s.ETag.Unix())
+}
+
+// SaveSnapshot will write the snapshot through the engine as the latest one
+// and, unless the retention is zero, under its own key as well. The older
+// versions are kept for the retention only, either expired by the engine or
+// pruned here, so that the history does not grow forever.
+func SaveSnapshot(engine PersistanceEngine, s *Snapshot, retention time.Duration) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("rubbernecker: could not encode the snapshot: %s", err)
+	}
+
+	err = engine.Put(LatestSnapshotKey, data)
+	if err != nil || retention <= 0 {
+		return err
+	}
+
+	if e, ok := engine.(ExpiringPersistanceEngine); ok {
+		return e.PutWithTTL(SnapshotKey(s), data, retention)
+	}
+
+	err = engine.Put(SnapshotKey(s), data)
+	if err != nil {
+		return err
+	}
+
+	return pruneSnapshots(engine, s.ETag.Add(-retention))
+}
+
+// pruneSnapshots will delete the versions of the board older than the given
+// time.
+func pruneSnapshots(engine PersistanceEngine, before time.Time) error {
+	keys, err := engine.Keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, snapshotPrefix) {
+			continue
+		}
+
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(key, snapshotPrefix), 10, 64)
+		if err != nil || !time.Unix(timestamp, 0).Before(before) {
+			continue
+		}
+
+		err = engine.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadSnapshot will read the snapshot stored under the key through the engine.
+func LoadSnapshot(engine PersistanceEngine, key string) (*Snapshot, error) {
+	s, err := GetAs[*Snapshot](engine, key)
+	if err == nil && s == nil {
+		err = fmt.Errorf("rubbernecker: empty snapshot under %s", key)
+	}
+
+	return s, err
+}
+
+// LoadChanges will work out the changes to the cards from the versions of the
+// board persisted under their own keys, oldest first, so that they survive a
+// restart. Only the versions kept for the retention of SaveSnapshot are known.
+func LoadChanges(engine PersistanceEngine) (Changes, error) {
+	snapshots, err := ListAs[*Snapshot](engine, snapshotPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*Snapshot, 0, len(snapshots))
+	for key, s := range snapshots {
+		if key != LatestSnapshotKey && s != nil {
+			history = append(history, s)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].ETag.Before(history[j].ETag)
+	})
+
+	changes := Changes{}
+	for i := 1; i < len(history); i++ {
+		if history[i-1].Cards == nil && history[i-1].DoneCards == nil {
+			continue
+		}
+
+		changes = append(changes, changesBetween(history[i-1], history[i])...)
+	}
+
+	return changes, nil
 }
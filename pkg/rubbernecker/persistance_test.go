@@ -1 +1,196 @@
 package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/memory"
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Persistance", func() {
+	var (
+		engine   rubbernecker.PersistanceEngine
+		snapshot *rubbernecker.Snapshot
+	)
+
+	BeforeEach(func() {
+		engine = memory.SetupEngine()
+		snapshot = &rubbernecker.Snapshot{
+			ETag:      time.Unix(1539856800, 0).UTC(),
+			Cards:     rubbernecker.Cards{{ID: 1, Title: "Test", Assignees: rubbernecker.Members{1: {ID: 1, Name: "Alice"}}}},
+			DoneCards: rubbernecker.Cards{{ID: 2, Title: "Done"}},
+			Members:   rubbernecker.Members{1: {ID: 1, Name: "Alice"}},
+			Support:   rubbernecker.SupportRota{"in-hours": {Type: "in-hours", Member: "Alice"}},
+		}
+	})
+
+	It("should compose the SnapshotKey() out of the timestamp", func() {
+		Expect(rubbernecker.SnapshotKey(snapshot)).To(Equal("snapshot.1539856800"))
+	})
+
+	It("should SaveSnapshot() under its own key and as the latest", func() {
+		Expect(rubbernecker.SaveSnapshot(engine, snapshot, time.Hour)).To(Succeed())
+
+		for _, key := range []string{"snapshot.1539856800", rubbernecker.LatestSnapshotKey} {
+			loaded, err := rubbernecker.LoadSnapshot(engine, key)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(snapshot))
+		}
+	})
+
+	It("should keep the number of the persisted snapshots bounded by the retention", func() {
+		// Hiding the PutWithTTL, so that the snapshots have to be pruned.
+		engine := struct{ rubbernecker.PersistanceEngine }{engine}
+
+		for i := 0; i < 10; i++ {
+			s := &rubbernecker.Snapshot{ETag: snapshot.ETag.Add(time.Duration(i) * time.Hour)}
+			Expect(rubbernecker.SaveSnapshot(engine, s, 3*time.Hour)).To(Succeed())
+
+			keys, err := engine.Keys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(keys)).To(BeNumerically("<=", 5))
+		}
+
+		keys, err := engine.Keys()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(ConsistOf(
+			rubbernecker.LatestSnapshotKey,
+			"snapshot.1539878400",
+			"snapshot.1539882000",
+			"snapshot.1539885600",
+			"snapshot.1539889200",
+		))
+	})
+
+	It("should let the engine expire the persisted snapshots", func() {
+		Expect(rubbernecker.SaveSnapshot(engine, snapshot, 50*time.Millisecond)).To(Succeed())
+		Expect(engine.Keys()).To(ContainElement("snapshot.1539856800"))
+
+		Eventually(engine.Keys).Should(Equal([]string{rubbernecker.LatestSnapshotKey}))
+	})
+
+	It("should only SaveSnapshot() as the latest with no retention", func() {
+		Expect(rubbernecker.SaveSnapshot(engine, snapshot, 0)).To(Succeed())
+
+		Expect(engine.Keys()).To(Equal([]string{rubbernecker.LatestSnapshotKey}))
+	})
+
+	It("should LoadChanges() out of the persisted snapshots", func() {
+		moved := &rubbernecker.Snapshot{
+			ETag:      snapshot.ETag.Add(time.Hour),
+			Cards:     rubbernecker.Cards{{ID: 1, Title: "Test", Status: "done", Assignees: snapshot.Cards[0].Assignees}},
+			DoneCards: snapshot.DoneCards,
+		}
+		empty := &rubbernecker.Snapshot{ETag: snapshot.ETag.Add(-time.Hour)}
+
+		for _, s := range []*rubbernecker.Snapshot{moved, empty, snapshot} {
+			Expect(rubbernecker.SaveSnapshot(engine, s, 24*time.Hour)).To(Succeed())
+		}
+
+		changes, err := rubbernecker.LoadChanges(engine)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].At).To(BeTemporally("==", moved.ETag))
+		Expect(changes[0].Type).To(Equal(rubbernecker.ChangeMoved))
+		Expect(changes[0].CardID).To(Equal(1))
+		Expect(changes[0].To).To(Equal("done"))
+	})
+
+	It("should LoadChanges() of nothing when there is no history", func() {
+		Expect(rubbernecker.SaveSnapshot(engine, snapshot, 0)).To(Succeed())
+
+		changes, err := rubbernecker.LoadChanges(engine)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("should fail to LoadSnapshot() which does not exist", func() {
+		_, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should LoadSnapshot() kept as it is", func() {
+		Expect(engine.Put(rubbernecker.LatestSnapshotKey, snapshot)).To(Succeed())
+
+		loaded, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(BeIdenticalTo(snapshot))
+	})
+
+	It("should fail to LoadSnapshot() of unexpected type", func() {
+		Expect(engine.Put(rubbernecker.LatestSnapshotKey, 123)).To(Succeed())
+
+		_, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
+
+		Expect(err).To(MatchError(ContainSubstring("unexpected value of type int")))
+	})
+
+	It("should fail to LoadSnapshot() which is empty", func() {
+		Expect(engine.Put(rubbernecker.LatestSnapshotKey, []byte(`null`))).To(Succeed())
+
+		_, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
+
+		Expect(err).To(MatchError(ContainSubstring("empty snapshot")))
+	})
+
+	It("should GetAs() the value kept as it is", func() {
+		Expect(engine.Put("number", 123)).To(Succeed())
+
+		value, err := rubbernecker.GetAs[int](engine, "number")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(123))
+	})
+
+	It("should GetAs() the value kept as JSON", func() {
+		Expect(engine.Put("members", []byte(`{"1":{"id":1,"name":"Alice"}}`))).To(Succeed())
+
+		value, err := rubbernecker.GetAs[rubbernecker.Members](engine, "members")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(HaveKeyWithValue(1, &rubbernecker.Member{ID: 1, Name: "Alice"}))
+	})
+
+	It("should fail to GetAs() the value which does not exist", func() {
+		value, err := rubbernecker.GetAs[*rubbernecker.Snapshot](engine, "missing")
+
+		Expect(err).To(HaveOccurred())
+		Expect(value).To(BeNil())
+	})
+
+	It("should ListAs() the values with the prefix", func() {
+		Expect(rubbernecker.SaveSnapshot(engine, snapshot, time.Hour)).To(Succeed())
+		Expect(engine.Put("other", 123)).To(Succeed())
+
+		snapshots, err := rubbernecker.ListAs[*rubbernecker.Snapshot](engine, "snapshot.")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(2))
+		Expect(snapshots).To(HaveKeyWithValue("snapshot.1539856800", snapshot))
+	})
+
+	It("should fail to ListAs() the values of unexpected type", func() {
+		Expect(engine.Put("snapshot.1", 123)).To(Succeed())
+
+		_, err := rubbernecker.ListAs[*rubbernecker.Snapshot](engine, "snapshot.")
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to LoadSnapshot() which is corrupted", func() {
+		Expect(engine.Put(rubbernecker.LatestSnapshotKey, []byte(`{"cards":`))).To(Succeed())
+
+		_, err := rubbernecker.LoadSnapshot(engine, rubbernecker.LatestSnapshotKey)
+
+		Expect(err).To(MatchError(ContainSubstring("could not decode")))
+	})
+})
@@ -0,0 +1,143 @@
+package rubbernecker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusWriter writes the metrics in the Prometheus text exposition format,
+// remembering the first error encountered.
+type prometheusWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *prometheusWriter) metric(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *prometheusWriter) sample(name string, value float64, labels ...string) {
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+
+	if len(pairs) > 0 {
+		name = name + "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	p.printf("%s %s\n", name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (p *prometheusWriter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+// WritePrometheus will write the metrics of the board and the upstream
+// services in the Prometheus text exposition format. The columns are reported
+// on in the order of the workflow, the default one with no config.
+func WritePrometheus(w io.Writer, snapshot *Snapshot, config *Config, upstreams []UpstreamStatus) error {
+	p := &prometheusWriter{w: bufio.NewWriter(w)}
+	cards := combine(snapshot.Cards, snapshot.DoneCards)
+
+	workflow := DefaultWorkflow()
+	if config != nil && config.Workflow != nil {
+		workflow = config.Workflow
+	}
+
+	p.metric("rubbernecker_cards", "gauge", "Number of cards on the wall per status.")
+	for _, column := range workflow {
+		p.sample("rubbernecker_cards", float64(len(cards.Filter(column.Name))), "status", column.Name)
+	}
+
+	if config != nil {
+		limited := Workflow{}
+		for _, column := range workflow {
+			if column.Limit > 0 {
+				limited = append(limited, column)
+			}
+		}
+
+		p.metric("rubbernecker_cards_limit", "gauge", "Maximum number of cards expected per status.")
+		for _, column := range limited {
+			p.sample("rubbernecker_cards_limit", float64(column.Limit), "status", column.Name)
+		}
+
+		p.metric("rubbernecker_cards_over_limit", "gauge", "Number of cards over the limit per status.")
+		for _, column := range limited {
+			over := len(cards.Filter(column.Name)) - column.Limit
+			if over < 0 {
+				over = 0
+			}
+
+			p.sample("rubbernecker_cards_over_limit", float64(over), "status", column.Name)
+		}
+	}
+
+	stickers := map[string]int{}
+	oldest := 0
+	for _, column := range workflow.InPlay() {
+		for _, card := range cards.Filter(column.Name) {
+			for _, s := range card.Stickers {
+				stickers[s.Name]++
+			}
+
+			if card.Elapsed > oldest {
+				oldest = card.Elapsed
+			}
+		}
+	}
+
+	names := make([]string, 0, len(stickers))
+	for name := range stickers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.metric("rubbernecker_stickers", "gauge", "Number of cards in play per sticker.")
+	for _, name := range names {
+		p.sample("rubbernecker_stickers", float64(stickers[name]), "sticker", name)
+	}
+
+	p.metric("rubbernecker_oldest_card_in_play_working_days", "gauge", "Working days the oldest card in play has been in its status for.")
+	p.sample("rubbernecker_oldest_card_in_play_working_days", float64(oldest))
+
+	if !snapshot.ETag.IsZero() {
+		p.metric("rubbernecker_board_last_updated_timestamp_seconds", "gauge", "Time the board has last changed at.")
+		p.sample("rubbernecker_board_last_updated_timestamp_seconds", float64(snapshot.ETag.Unix()))
+	}
+
+	p.metric("rubbernecker_upstream_fetch_duration_seconds", "summary", "Time spent fetching from the upstream service.")
+	for _, u := range upstreams {
+		p.sample("rubbernecker_upstream_fetch_duration_seconds_sum", u.TotalDuration.Seconds(), "upstream", u.Name)
+		p.sample("rubbernecker_upstream_fetch_duration_seconds_count", float64(u.Fetches), "upstream", u.Name)
+	}
+
+	p.metric("rubbernecker_upstream_fetch_errors_total", "counter", "Number of failed fetches from the upstream service.")
+	for _, u := range upstreams {
+		p.sample("rubbernecker_upstream_fetch_errors_total", float64(u.Errors), "upstream", u.Name)
+	}
+
+	p.metric("rubbernecker_upstream_last_success_timestamp_seconds", "gauge", "Time of the last successful fetch from the upstream service.")
+	for _, u := range upstreams {
+		if !u.LastSuccess.IsZero() {
+			p.sample("rubbernecker_upstream_last_success_timestamp_seconds", float64(u.LastSuccess.Unix()), "upstream", u.Name)
+		}
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+
+	return p.w.Flush()
+}
@@ -0,0 +1,110 @@
+package rubbernecker_test
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("test: closed")
+}
+
+var _ = Describe("Prometheus", func() {
+	var (
+		snapshot *rubbernecker.Snapshot
+	)
+
+	BeforeEach(func() {
+		snapshot = &rubbernecker.Snapshot{
+			ETag: time.Unix(1539856800, 0),
+			Cards: rubbernecker.Cards{
+				{Status: "next", Elapsed: 30, Stickers: rubbernecker.Stickers{{Name: "blocked"}}},
+				{Status: "doing", Elapsed: 3, Stickers: rubbernecker.Stickers{{Name: "blocked"}, {Name: `pair"ing`}}},
+				{Status: "doing", Elapsed: 1, Stickers: rubbernecker.Stickers{{Name: "blocked"}}},
+				{Status: "reviewing", Elapsed: 2},
+				{Status: "reviewing", Elapsed: 7},
+				{Status: "reviewing"},
+			},
+			DoneCards: rubbernecker.Cards{
+				{Status: "done", Elapsed: 40},
+			},
+		}
+	})
+
+	It("should WritePrometheus() metrics of the board", func() {
+		b := &bytes.Buffer{}
+		workflow := rubbernecker.DefaultWorkflow()
+		workflow[2].Limit = 2
+
+		err := rubbernecker.WritePrometheus(b, snapshot, &rubbernecker.Config{Workflow: workflow}, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.String()).To(ContainSubstring("# HELP rubbernecker_cards Number of cards on the wall per status.\n# TYPE rubbernecker_cards gauge\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="next"} 1` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="doing"} 2` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="reviewing"} 3` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="approving"} 0` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="done"} 1` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards_limit{status="reviewing"} 2` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards_over_limit{status="reviewing"} 1` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards_over_limit{status="approving"} 0` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_stickers{sticker="blocked"} 2` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_stickers{sticker="pair\"ing"} 1` + "\n"))
+		Expect(b.String()).To(ContainSubstring("rubbernecker_oldest_card_in_play_working_days 7\n"))
+		Expect(b.String()).To(ContainSubstring("rubbernecker_board_last_updated_timestamp_seconds 1539856800\n"))
+		Expect(b.String()).NotTo(ContainSubstring("rubbernecker_upstream_fetch_errors_total{"))
+	})
+
+	It("should WritePrometheus() metrics of the columns of the workflow", func() {
+		b := &bytes.Buffer{}
+		snapshot.Cards = append(snapshot.Cards, &rubbernecker.Card{Status: "in-qa", Elapsed: 12})
+		workflow := rubbernecker.Workflow{
+			{Name: "doing", InPlay: true},
+			{Name: "in-qa", Limit: 1, InPlay: true},
+			{Name: "done"},
+		}
+
+		err := rubbernecker.WritePrometheus(b, snapshot, &rubbernecker.Config{Workflow: workflow}, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards{status="in-qa"} 1` + "\n"))
+		Expect(b.String()).NotTo(ContainSubstring(`rubbernecker_cards{status="reviewing"}`))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_cards_limit{status="in-qa"} 1` + "\n"))
+		Expect(b.String()).NotTo(ContainSubstring(`rubbernecker_cards_limit{status="doing"}`))
+		Expect(b.String()).To(ContainSubstring("rubbernecker_oldest_card_in_play_working_days 12\n"))
+	})
+
+	It("should WritePrometheus() metrics of the upstreams", func() {
+		b := &bytes.Buffer{}
+		upstreams := rubbernecker.NewUpstreams("members")
+		upstreams.Record("stories", 1500*time.Millisecond, time.Unix(1539856800, 0), nil)
+		upstreams.Record("stories", 500*time.Millisecond, time.Unix(1539856820, 0), fmt.Errorf("test: 500"))
+
+		err := rubbernecker.WritePrometheus(b, &rubbernecker.Snapshot{}, nil, upstreams.Statuses())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.String()).NotTo(ContainSubstring("rubbernecker_cards_limit"))
+		Expect(b.String()).NotTo(ContainSubstring("rubbernecker_board_last_updated_timestamp_seconds"))
+		Expect(b.String()).To(ContainSubstring("# TYPE rubbernecker_upstream_fetch_duration_seconds summary\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_upstream_fetch_duration_seconds_sum{upstream="stories"} 2` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_upstream_fetch_duration_seconds_count{upstream="stories"} 2` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_upstream_fetch_duration_seconds_count{upstream="members"} 0` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_upstream_fetch_errors_total{upstream="stories"} 1` + "\n"))
+		Expect(b.String()).To(ContainSubstring(`rubbernecker_upstream_last_success_timestamp_seconds{upstream="stories"} 1539856800` + "\n"))
+		Expect(b.String()).NotTo(ContainSubstring(`rubbernecker_upstream_last_success_timestamp_seconds{upstream="members"}`))
+	})
+
+	It("should fail to WritePrometheus() to a faulty writer", func() {
+		err := rubbernecker.WritePrometheus(failingWriter{}, snapshot, nil, nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,438 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed filter the cards can be matched against.
+type Query interface {
+	Matches(card *Card) bool
+}
+
+type andQuery []Query
+
+func (q andQuery) Matches(card *Card) bool {
+	for _, sub := range q {
+		if !sub.Matches(card) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type orQuery []Query
+
+func (q orQuery) Matches(card *Card) bool {
+	for _, sub := range q {
+		if sub.Matches(card) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type notQuery struct {
+	Query
+}
+
+func (q notQuery) Matches(card *Card) bool {
+	return !q.Query.Matches(card)
+}
+
+type matchQuery func(card *Card) bool
+
+func (q matchQuery) Matches(card *Card) bool {
+	return q(card)
+}
+
+// ParseQueries will parse each of the filters and match the cards matching all
+// of them.
+func ParseQueries(filters []string) (Query, error) {
+	queries := andQuery{}
+
+	for _, filter := range filters {
+		q, err := ParseQuery(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		queries = append(queries, q)
+	}
+
+	return queries, nil
+}
+
+// ParseQuery will parse the filter, such as
+// `(person:alice OR person:bob) -sticker:blocked estimate:>3`. The terms are
+// written as field:value and matched all unless joined with OR. They can be
+// negated with NOT or a leading minus and grouped with parentheses. The values
+// can be quoted and otherwise span the words up to the next term or operator.
+func ParseQuery(filter string) (Query, error) {
+	tokens, err := lexQuery(filter)
+	if err != nil {
+		return nil, fmt.Errorf("rubbernecker: invalid filter %q: %s", filter, err)
+	}
+
+	p := &queryParser{tokens: tokens}
+	q, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rubbernecker: invalid filter %q: %s", filter, err)
+	}
+
+	return q, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenMinus
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type queryToken struct {
+	kind  tokenKind
+	field string
+	value string
+	text  string
+}
+
+func (t queryToken) String() string {
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lexQuery splits the filter into the words, parentheses and operators. The
+// operators are only recognised in upper case.
+func lexQuery(filter string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "("})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")"})
+			i++
+			continue
+		case r == '-':
+			tokens = append(tokens, queryToken{kind: tokenMinus, text: "-"})
+			i++
+			continue
+		}
+
+		start := i
+		word := strings.Builder{}
+		field := ""
+		quoted := false
+
+		for i < len(runes) {
+			r = runes[i]
+			if r == ' ' || r == '\t' || r == '\n' || r == '(' || r == ')' {
+				break
+			}
+
+			if r == ':' && field == "" && !quoted {
+				field = word.String()
+				word.Reset()
+				i++
+				continue
+			}
+
+			if r == '"' {
+				quoted = true
+				i++
+				for ; i < len(runes) && runes[i] != '"'; i++ {
+					if runes[i] == '\\' && i+1 < len(runes) {
+						i++
+					}
+					word.WriteRune(runes[i])
+				}
+				if i == len(runes) {
+					return nil, fmt.Errorf("unterminated quote")
+				}
+				i++
+				continue
+			}
+
+			word.WriteRune(r)
+			i++
+		}
+
+		token := queryToken{kind: tokenWord, field: strings.ToLower(field), value: word.String(), text: string(runes[start:i])}
+		if !quoted && field == "" {
+			switch token.value {
+			case "AND":
+				token.kind = tokenAnd
+			case "OR":
+				token.kind = tokenOr
+			case "NOT":
+				token.kind = tokenNot
+			}
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (Query, error) {
+	q, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	queries := orQuery{q}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenOr {
+			break
+		}
+		p.pos++
+
+		q, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+
+	return queries, nil
+}
+
+func (p *queryParser) parseAnd() (Query, error) {
+	q, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	queries := andQuery{q}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokenOr || t.kind == tokenClose {
+			break
+		}
+		if t.kind == tokenAnd {
+			p.pos++
+		}
+
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+
+	return queries, nil
+}
+
+func (p *queryParser) parseUnary() (Query, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	switch t.kind {
+	case tokenNot, tokenMinus:
+		p.pos++
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{q}, nil
+	case tokenOpen:
+		p.pos++
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return q, nil
+	case tokenWord:
+		return p.parseTerm()
+	default:
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+}
+
+// parseTerm reads the field:value term, along with the words following it
+// which are not terms themselves, so that `sticker:small task` is a single
+// term, same as `sticker:"small task"`.
+func (p *queryParser) parseTerm() (Query, error) {
+	t := p.tokens[p.pos]
+	p.pos++
+
+	if t.field == "" {
+		return nil, fmt.Errorf("expected field:value, got %s", t)
+	}
+
+	value := t.value
+	for {
+		next, ok := p.peek()
+		if !ok || next.kind != tokenWord || next.field != "" {
+			break
+		}
+		if value != "" {
+			value += " "
+		}
+		value += next.value
+		p.pos++
+	}
+
+	return newTermQuery(t.field, value)
+}
+
+func newTermQuery(field, value string) (Query, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing value of %s", field)
+	}
+	lower := strings.ToLower(value)
+
+	switch field {
+	case "person":
+		return matchQuery(func(card *Card) bool {
+			for _, member := range card.Assignees {
+				if member != nil && strings.Contains(strings.ToLower(member.Name), lower) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "title":
+		return matchQuery(func(card *Card) bool {
+			return strings.Contains(strings.ToLower(card.Title), lower)
+		}), nil
+	case "project":
+		return matchQuery(func(card *Card) bool {
+			return strings.Contains(strings.ToLower(card.Project), lower)
+		}), nil
+	case "sticker":
+		return stickerQuery(lower), nil
+	case "not-sticker":
+		return notQuery{stickerQuery(lower)}, nil
+	case "status":
+		return matchQuery(func(card *Card) bool {
+			return strings.EqualFold(card.Status, value)
+		}), nil
+	case "type":
+		return matchQuery(func(card *Card) bool {
+			return strings.EqualFold(card.StoryType, value)
+		}), nil
+	case "id":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", value)
+		}
+		return matchQuery(func(card *Card) bool {
+			return card.ID == id
+		}), nil
+	case "estimate":
+		compare, err := parseComparison(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid estimate %q", value)
+		}
+		return matchQuery(func(card *Card) bool {
+			return card.Estimate != nil && compare(*card.Estimate)
+		}), nil
+	case "age":
+		compare, err := parseComparison(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age %q", value)
+		}
+		return matchQuery(func(card *Card) bool {
+			return compare(float64(card.Elapsed))
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+}
+
+func stickerQuery(name string) Query {
+	return matchQuery(func(card *Card) bool {
+		for _, sticker := range card.Stickers {
+			if strings.HasPrefix(strings.ToLower(sticker.Name), name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseComparison reads the number, optionally preceded with one of the >, >=,
+// <, <= or = operators.
+func parseComparison(value string) (func(float64) bool, error) {
+	operator := ""
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			operator = op
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(value[len(operator):]), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(x float64) bool {
+		switch operator {
+		case ">=":
+			return x >= n
+		case "<=":
+			return x <= n
+		case ">":
+			return x > n
+		case "<":
+			return x < n
+		default:
+			return x == n
+		}
+	}, nil
+}
@@ -0,0 +1,116 @@
+package rubbernecker_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Query", func() {
+	var cards rubbernecker.Cards
+
+	titles := func(cards rubbernecker.Cards) []string {
+		tmp := []string{}
+		for _, card := range cards {
+			tmp = append(tmp, card.Title)
+		}
+		return tmp
+	}
+
+	filter := func(filters ...string) []string {
+		query, err := rubbernecker.ParseQueries(filters)
+		Expect(err).NotTo(HaveOccurred())
+
+		return titles(cards.Matching(query))
+	}
+
+	BeforeEach(func() {
+		one, five := 1.0, 5.0
+
+		cards = rubbernecker.Cards{
+			{
+				ID:        1,
+				Title:     "Fix the build",
+				Status:    "doing",
+				StoryType: "bug",
+				Estimate:  &one,
+				Elapsed:   2,
+				Assignees: rubbernecker.Members{1: {ID: 1, Name: "Alice"}},
+				Stickers:  rubbernecker.Stickers{{Name: "blocked"}},
+			},
+			{
+				ID:        2,
+				Title:     "Upgrade the database",
+				Status:    "reviewing",
+				StoryType: "feature",
+				Estimate:  &five,
+				Elapsed:   7,
+				Assignees: rubbernecker.Members{2: {ID: 2, Name: "Bob"}},
+				Stickers:  rubbernecker.Stickers{{Name: "'small' task"}},
+			},
+			{
+				ID:        3,
+				Title:     "Rock AND roll",
+				Status:    "next",
+				StoryType: "chore",
+			},
+		}
+	})
+
+	It("should match the terms of all the fields", func() {
+		Expect(filter("person:ali")).To(Equal([]string{"Fix the build"}))
+		Expect(filter("title:DATABASE")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("status:reviewing")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("type:chore")).To(Equal([]string{"Rock AND roll"}))
+		Expect(filter("id:1")).To(Equal([]string{"Fix the build"}))
+		Expect(filter("estimate:>3")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("estimate:<=1")).To(Equal([]string{"Fix the build"}))
+		Expect(filter("estimate:5")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("age:>5")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("age:<1")).To(Equal([]string{"Rock AND roll"}))
+		Expect(filter("sticker:block")).To(Equal([]string{"Fix the build"}))
+	})
+
+	It("should combine the terms with AND, OR, NOT and parentheses", func() {
+		Expect(filter("status:doing OR status:next")).To(Equal([]string{"Fix the build", "Rock AND roll"}))
+		Expect(filter("title:the AND age:>5")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("title:the -sticker:blocked")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("NOT title:the")).To(Equal([]string{"Rock AND roll"}))
+		Expect(filter("-(person:alice OR person:bob)")).To(Equal([]string{"Rock AND roll"}))
+		Expect(filter("(status:doing OR status:reviewing) type:feature")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("status:next OR status:doing type:bug")).To(Equal([]string{"Fix the build", "Rock AND roll"}))
+		Expect(filter("title:the", "-status:doing")).To(Equal([]string{"Upgrade the database"}))
+	})
+
+	It("should match the values which are quoted or span several words", func() {
+		Expect(filter(`title:"rock AND roll"`)).To(Equal([]string{"Rock AND roll"}))
+		Expect(filter("title:fix the build OR id:3")).To(Equal([]string{"Fix the build", "Rock AND roll"}))
+		Expect(filter("title: upgrade")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("sticker:'small' task")).To(Equal([]string{"Upgrade the database"}))
+		Expect(filter("not-sticker:'small' task")).To(Equal([]string{"Fix the build", "Rock AND roll"}))
+	})
+
+	It("should fail to parse the invalid queries", func() {
+		invalid := map[string]string{
+			"foo":                          `expected field:value, got "foo"`,
+			"colour:red":                   `unknown field "colour"`,
+			"title:":                       "missing value of title",
+			"id:one":                       `invalid id "one"`,
+			"estimate:>big":                `invalid estimate ">big"`,
+			"age:old":                      `invalid age "old"`,
+			"(status:doing":                "missing closing parenthesis",
+			"status:doing)":                `unexpected ")"`,
+			"status:doing OR":              "unexpected end of filter",
+			`title:"unterminated`:          "unterminated quote",
+			"":                             "empty filter",
+			"status:doing AND OR type:bug": `unexpected "OR"`,
+		}
+
+		for query, message := range invalid {
+			_, err := rubbernecker.ParseQuery(query)
+			Expect(err).To(MatchError(ContainSubstring(message)), query)
+			Expect(err).To(MatchError(ContainSubstring("invalid filter")), query)
+		}
+	})
+})
@@ -8,22 +8,29 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
 )
 
 // Response will be a standard outcome returned when hitting rubbernecker app.
 type Response struct {
-	Card                 *Card       `json:"card,omitempty"`
-	Cards                Cards       `json:"cards,omitempty"`
-	SampleCard           *Card       `json:"sample_card,omitempty"`
-	Config               *Config     `json:"config,omitempty"`
-	Error                string      `json:"error,omitempty"`
-	Message              string      `json:"message,omitempty"`
-	SupportRota          SupportRota `json:"support,omitempty"`
-	TeamMembers          Members     `json:"team_members,omitempty"`
-	FreeTeamMembers      Members     `json:"free_team_members,omitempty"`
-	Filters              []Filter    `json:"filers,omitempty"`
-	AppliedFilterQueries []string    `json:"applied_filters,omitempty"`
-	TextFilters          string      `json:"text_filters,omitempty"`
+	Card                 *Card            `json:"card,omitempty"`
+	Cards                Cards            `json:"cards,omitempty"`
+	Changes              Changes          `json:"changes,omitempty"`
+	SampleCard           *Card            `json:"sample_card,omitempty"`
+	Config               *Config          `json:"config,omitempty"`
+	Error                string           `json:"error,omitempty"`
+	Flow                 *FlowMetrics     `json:"flow,omitempty"`
+	Message              string           `json:"message,omitempty"`
+	SupportRota          SupportRota      `json:"support,omitempty"`
+	TeamMembers          Members          `json:"team_members,omitempty"`
+	Availability         Availabilities   `json:"availability,omitempty"`
+	Filters              []Filter         `json:"filers,omitempty"`
+	AppliedFilterQueries []string         `json:"applied_filters,omitempty"`
+	TextFilters          string           `json:"text_filters,omitempty"`
+	Upstreams            []UpstreamStatus `json:"upstreams,omitempty"`
+	StaleSince           *time.Time       `json:"stale_since,omitempty"`
+	Breaches             Breaches         `json:"over_limit,omitempty"`
+	Incidents            Incidents        `json:"incidents,omitempty"`
 }
 
 // JSON function will execute the response to our HTTP writer.
@@ -93,6 +100,12 @@ func (r *Response) WithCards(cards Cards, single bool) *Response {
 	return r
 }
 
+// WithChanges will set the changes to the cards for the current response.
+func (r *Response) WithChanges(changes Changes) *Response {
+	r.Changes = changes
+	return r
+}
+
 // WithConfig will set a configuration that will be returned in a response.
 func (r *Response) WithConfig(config *Config) *Response {
 	r.Config = config
@@ -105,6 +118,38 @@ func (r *Response) WithError(err error) *Response {
 	return r
 }
 
+// WithFlow will set the flow metrics for the current response.
+func (r *Response) WithFlow(flow *FlowMetrics) *Response {
+	r.Flow = flow
+	return r
+}
+
+// WithUpstreams will set the statuses of the upstream services for the current
+// response.
+func (r *Response) WithUpstreams(statuses []UpstreamStatus) *Response {
+	r.Upstreams = statuses
+	return r
+}
+
+// WithStaleSince will set the time since which the data is stale for the
+// current response.
+func (r *Response) WithStaleSince(since time.Time) *Response {
+	r.StaleSince = &since
+	return r
+}
+
+// WithBreaches will set the limits breached for the current response.
+func (r *Response) WithBreaches(breaches Breaches) *Response {
+	r.Breaches = breaches
+	return r
+}
+
+// WithIncidents will set the open incidents, the most pressing first.
+func (r *Response) WithIncidents(incidents Incidents) *Response {
+	r.Incidents = incidents.Sort()
+	return r
+}
+
 // WithSupport will set either rota or a single support data for the current
 // response.
 func (r *Response) WithSupport(rota SupportRota) *Response {
@@ -118,24 +163,13 @@ func (r *Response) WithTeamMembers(members Members) *Response {
 	return r
 }
 
-// WithFreeTeamMembers should prepare a list of team members that are free to
-// pickup new work.
-func (r *Response) WithFreeTeamMembers() *Response {
-	if r.TeamMembers != nil && r.Cards != nil {
-		free := Members{}
-		for id, member := range r.TeamMembers {
-			free[id] = member
-		}
-
-		for _, card := range r.Cards {
-			for _, assignee := range card.Assignees {
-				if assignee != nil {
-					delete(free, assignee.ID)
-				}
-			}
-		}
-
-		r.FreeTeamMembers = free
+// WithAvailability should work out what each of the team members is up to,
+// such as whether they are free to pickup new work, out of the cards in play,
+// the support and the absences. The cards are given rather than taken from the
+// response, as these may have been filtered.
+func (r *Response) WithAvailability(cards Cards, rota Rota, leave Absences, now time.Time) *Response {
+	if r.TeamMembers != nil {
+		r.Availability = NewAvailability(r.TeamMembers, cards, r.SupportRota, rota, leave, now)
 	}
 
 	return r
@@ -147,6 +181,11 @@ func (r *Response) WithFilters(filters []Filter) *Response {
 	return r
 }
 
+// FilterGroups will group the filters for the current response.
+func (r *Response) FilterGroups() []FilterGroup {
+	return GroupFilters(r.Filters)
+}
+
 // WithAppliedFilterQueries will set the applied filter queries param for the current response.
 func (r *Response) WithAppliedFilterQueries(queries []string) *Response {
 	r.AppliedFilterQueries = queries
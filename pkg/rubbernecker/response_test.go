@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -40,7 +41,7 @@ var _ = Describe("Response", func() {
 
 	It("should setup the response WithConfig() collection", func() {
 		resp.WithConfig(&rubbernecker.Config{
-			ApprovalLimit: 100,
+			Workflow: rubbernecker.DefaultWorkflow(),
 		})
 
 		Expect(resp.Config).NotTo(BeNil())
@@ -68,19 +69,26 @@ var _ = Describe("Response", func() {
 		Expect(resp.TeamMembers).NotTo(BeNil())
 	})
 
-	It("should setup the response WithFreeTeamMembers()", func() {
+	It("should setup the response WithAvailability()", func() {
 		mem := rubbernecker.Member{ID: 1234, Name: "Tester"}
-		mems := rubbernecker.Members{1234: &mem, 4321: &rubbernecker.Member{ID: 4321, Name: "Free"}}
+		mems := rubbernecker.Members{
+			1234: &mem,
+			4321: &rubbernecker.Member{ID: 4321, Name: "Free"},
+			5678: &rubbernecker.Member{ID: 5678, Name: "Support"},
+		}
 		card := rubbernecker.Card{Title: "Test", Assignees: rubbernecker.Members{1234: &mem}}
 		cards := rubbernecker.Cards{&card}
+		rota := rubbernecker.Rota{{Key: "in-hours", Label: "In hours", Schedule: "In hours", Busy: true}}
 
 		resp.
 			WithCards(cards, false).
 			WithTeamMembers(mems).
-			WithFreeTeamMembers()
+			WithSupport(rubbernecker.SupportRota{"in-hours": {Member: "Support"}}).
+			WithAvailability(cards, rota, nil, time.Now())
 
-		Expect(resp.FreeTeamMembers).NotTo(BeNil())
-		Expect(len(resp.FreeTeamMembers)).To(Equal(1))
+		Expect(resp.Availability).To(HaveLen(3))
+		Expect(resp.Availability.Members(rubbernecker.AvailabilityFree)).To(Equal(rubbernecker.Members{4321: mems[4321]}))
+		Expect(resp.Availability.Members(rubbernecker.AvailabilitySupport)).To(Equal(rubbernecker.Members{5678: mems[5678]}))
 	})
 
 	It("should compose a JSON() response", func() {
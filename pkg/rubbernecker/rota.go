@@ -0,0 +1,168 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RotaEntry will be a single support role shown on the board, taken from the
+// schedule of the support service, matched by its ID or its name. The entries
+// of the same group are shown together. Whoever is on call for the busy roles
+// is not free to pick up new work.
+type RotaEntry struct {
+	Key      string `yaml:"key" json:"key"`
+	Label    string `yaml:"label" json:"label"`
+	Group    string `yaml:"group" json:"group,omitempty"`
+	Schedule string `yaml:"schedule" json:"schedule"`
+	Busy     bool   `yaml:"busy" json:"busy,omitempty"`
+}
+
+// Rota will be a rubbernecker representation of the support roles shown on
+// the board, in the order they are shown in.
+type Rota []RotaEntry
+
+// RotaGroup is a named group of the support roles.
+type RotaGroup struct {
+	Name    string
+	Entries Rota
+}
+
+// DefaultRota is the rota of the board unless configured otherwise.
+func DefaultRota() Rota {
+	return Rota{
+		{Key: "in-hours", Label: "In hours", Group: "in-hours", Schedule: "PaaS team rota - in hours", Busy: true},
+		{Key: "in-hours-comms", Label: "Comms", Group: "in-hours", Schedule: "PaaS team rota - comms lead (in Hours)"},
+		{Key: "out-of-hours", Label: "Out of hours", Group: "out-of-hours", Schedule: "PaaS team rota - out of hours"},
+		{Key: "out-of-hours-comms", Label: "Comms", Group: "out-of-hours", Schedule: "PaaS team rota - comms lead (OOH)"},
+		{Key: "escalations", Label: "Escalations", Group: "escalations", Schedule: "P&T SCS Escalation"},
+	}
+}
+
+// Validate will make sure each of the entries is keyed uniquely and names the
+// schedule.
+func (r Rota) Validate() error {
+	keys := map[string]bool{}
+
+	for i, e := range r {
+		if e.Key == "" {
+			return fmt.Errorf("rubbernecker: rota entry %d has no key", i+1)
+		}
+
+		if keys[e.Key] {
+			return fmt.Errorf("rubbernecker: rota entry %q is defined more than once", e.Key)
+		}
+		keys[e.Key] = true
+
+		if e.Schedule == "" {
+			return fmt.Errorf("rubbernecker: rota entry %q has no schedule", e.Key)
+		}
+	}
+
+	return nil
+}
+
+// Map will key the support of the schedules by the entries of the rota. The
+// schedules nobody is on call for are shown as "-".
+func (r Rota) Map(support SupportRota) SupportRota {
+	tmp := SupportRota{}
+
+	for _, e := range r {
+		if s, ok := r.find(support, e.Schedule); ok {
+			tmp[e.Key] = s
+			continue
+		}
+
+		tmp[e.Key] = &Support{
+			Type:   e.Schedule,
+			Member: "-",
+		}
+	}
+
+	return tmp
+}
+
+// Unmatched lists the schedules of the rota which are not found in the
+// support, such as the ones which have been renamed, along with the names of
+// the schedules found instead.
+func (r Rota) Unmatched(support SupportRota) ([]string, []string) {
+	missing := []string{}
+	for _, e := range r {
+		if _, ok := r.find(support, e.Schedule); !ok {
+			missing = append(missing, e.Schedule)
+		}
+	}
+
+	available := []string{}
+	for _, s := range support {
+		if s != nil {
+			available = append(available, s.Type)
+		}
+	}
+	sort.Strings(available)
+
+	return missing, available
+}
+
+func (r Rota) find(support SupportRota, schedule string) (*Support, bool) {
+	if s, ok := support[schedule]; ok && s != nil {
+		return s, true
+	}
+
+	for _, s := range support {
+		if s != nil && s.ScheduleID != "" && s.ScheduleID == schedule {
+			return s, true
+		}
+	}
+
+	return nil, false
+}
+
+// Label names the support role of the key after its label. The labels shared
+// by several roles, such as the comms leads, are told apart by the label of
+// the first role of their group. The roles missing from the rota, or with no
+// label, are named after their keys.
+func (r Rota) Label(key string) string {
+	shared := map[string]int{}
+	for _, e := range r {
+		shared[e.Label]++
+	}
+
+	for _, group := range r.Groups() {
+		for _, e := range group.Entries {
+			if e.Key != key {
+				continue
+			}
+
+			switch {
+			case e.Label == "":
+				return e.Key
+			case shared[e.Label] > 1 && group.Entries[0].Label != e.Label:
+				return fmt.Sprintf("%s (%s)", e.Label, group.Entries[0].Label)
+			}
+
+			return e.Label
+		}
+	}
+
+	return key
+}
+
+// Groups will group the entries by their group name, in the order the groups
+// first appear in.
+func (r Rota) Groups() []RotaGroup {
+	groups := []RotaGroup{}
+	index := map[string]int{}
+
+	for _, e := range r {
+		i, ok := index[e.Group]
+		if !ok {
+			i = len(groups)
+			index[e.Group] = i
+			groups = append(groups, RotaGroup{Name: e.Group})
+		}
+
+		groups[i].Entries = append(groups[i].Entries, e)
+	}
+
+	return groups
+}
@@ -0,0 +1,78 @@
+package rubbernecker_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Rota", func() {
+	var (
+		rota    rubbernecker.Rota
+		support rubbernecker.SupportRota
+	)
+
+	BeforeEach(func() {
+		rota = rubbernecker.Rota{
+			{Key: "primary", Label: "Primary", Group: "in-hours", Schedule: "PABC123"},
+			{Key: "secondary", Label: "Secondary", Group: "in-hours", Schedule: "Platform - secondary"},
+			{Key: "escalations", Label: "Escalations", Schedule: "Escalations"},
+		}
+
+		support = rubbernecker.SupportRota{
+			"Platform - primary":   {Type: "Platform - primary", ScheduleID: "PABC123", Member: "Alice"},
+			"Platform - secondary": {Type: "Platform - secondary", ScheduleID: "PDEF456", Member: "Bob"},
+			"Other team":           {Type: "Other team", ScheduleID: "PGHI789", Member: "Carol"},
+		}
+	})
+
+	It("should Validate() the DefaultRota()", func() {
+		Expect(rubbernecker.DefaultRota().Validate()).To(Succeed())
+	})
+
+	It("should fail to Validate() the invalid rota", func() {
+		invalid := map[string]rubbernecker.Rota{
+			"entry 1 has no key":                  {{Schedule: "PABC123"}},
+			`"primary" is defined more than once`: {{Key: "primary", Schedule: "PABC123"}, {Key: "primary", Schedule: "PDEF456"}},
+			`"primary" has no schedule`:           {{Key: "primary"}},
+		}
+
+		for message, r := range invalid {
+			Expect(r.Validate()).To(MatchError(ContainSubstring(message)))
+		}
+	})
+
+	It("should Map() the support by the schedule ID or name", func() {
+		Expect(rota.Map(support)).To(Equal(rubbernecker.SupportRota{
+			"primary":     support["Platform - primary"],
+			"secondary":   support["Platform - secondary"],
+			"escalations": {Type: "Escalations", Member: "-"},
+		}))
+	})
+
+	It("should find the Unmatched() schedules", func() {
+		missing, available := rota.Unmatched(support)
+
+		Expect(missing).To(Equal([]string{"Escalations"}))
+		Expect(available).To(Equal([]string{"Other team", "Platform - primary", "Platform - secondary"}))
+	})
+
+	It("should find the Groups() of the entries", func() {
+		groups := rota.Groups()
+
+		Expect(groups).To(HaveLen(2))
+		Expect(groups[0].Name).To(Equal("in-hours"))
+		Expect(groups[0].Entries).To(Equal(rota[:2]))
+		Expect(groups[1].Entries).To(Equal(rota[2:]))
+	})
+
+	It("should Label() the roles, telling apart the shared labels", func() {
+		rota := rubbernecker.DefaultRota()
+
+		Expect(rota.Label("in-hours")).To(Equal("In hours"))
+		Expect(rota.Label("in-hours-comms")).To(Equal("Comms (In hours)"))
+		Expect(rota.Label("out-of-hours-comms")).To(Equal("Comms (Out of hours)"))
+		Expect(rota.Label("other-team")).To(Equal("other-team"))
+	})
+})
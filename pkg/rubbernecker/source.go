@@ -0,0 +1,163 @@
+package rubbernecker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Source is a single project the board is composed of, alongside the name its
+// cards will be tagged with.
+type Source struct {
+	Name    string
+	Service ProjectManagementService
+}
+
+// Sources will be a rubbernecker representative of all the projects making up
+// the board.
+type Sources []*Source
+
+// fetchError is the failure to fetch from some of the sources. The errors of
+// each of them are kept, so that they can still be inspected with errors.As.
+type fetchError struct {
+	what string
+	errs []error
+}
+
+func (e *fetchError) Error() string {
+	messages := []string{}
+	for _, err := range e.errs {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("rubbernecker: could not fetch %s: %s", e.what, strings.Join(messages, "; "))
+}
+
+func (e *fetchError) Unwrap() []error {
+	return e.errs
+}
+
+type fetchResult struct {
+	cards Cards
+	err   error
+}
+
+// FetchCards will fetch and flatten the cards of all the sources concurrently,
+// tagging each of them with the name of the source. The cards are returned in
+// the order of the sources. Sources with no cards matching the criteria do not
+// contribute to the result, but any failure to fetch is reported.
+func (ss Sources) FetchCards(ctx context.Context, status Status, params map[string]string) (Cards, error) {
+	results := make([]fetchResult, len(ss))
+	wg := sync.WaitGroup{}
+
+	for i, s := range ss {
+		wg.Add(1)
+
+		go func(i int, s *Source) {
+			defer wg.Done()
+
+			err := s.Service.FetchCards(ctx, status, params)
+			if err != nil {
+				results[i].err = fmt.Errorf("%s: %w", s.Name, err)
+				return
+			}
+
+			cards, err := s.Service.FlattenStories()
+			if errors.Is(err, ErrNoCards) {
+				return
+			}
+			if err != nil {
+				results[i].err = fmt.Errorf("%s: %w", s.Name, err)
+				return
+			}
+
+			for _, card := range cards {
+				card.Project = s.Name
+			}
+
+			results[i].cards = cards
+		}(i, s)
+	}
+
+	wg.Wait()
+
+	all := Cards{}
+	errs := []error{}
+
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+
+		all = append(all, r.cards...)
+	}
+
+	if len(errs) > 0 {
+		return nil, &fetchError{what: "cards", errs: errs}
+	}
+
+	return all, nil
+}
+
+// FetchMembers will fetch and flatten the members of all the sources which
+// implement the MemberService concurrently. Members being part of several
+// projects are only listed once.
+func (ss Sources) FetchMembers(ctx context.Context) (Members, error) {
+	results := make([]Members, len(ss))
+	errs := make([]error, len(ss))
+	wg := sync.WaitGroup{}
+
+	for i, s := range ss {
+		ms, ok := s.Service.(MemberService)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, name string, ms MemberService) {
+			defer wg.Done()
+
+			err := ms.FetchMembers(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+				return
+			}
+
+			members, err := ms.FlattenMembers()
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+				return
+			}
+
+			results[i] = members
+		}(i, s.Name, ms)
+	}
+
+	wg.Wait()
+
+	all := Members{}
+	failures := []error{}
+
+	for i := range ss {
+		if errs[i] != nil {
+			failures = append(failures, errs[i])
+			continue
+		}
+
+		for id, member := range results[i] {
+			if _, ok := all[id]; !ok {
+				all[id] = member
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, &fetchError{what: "members", errs: failures}
+	}
+
+	return all, nil
+}
@@ -0,0 +1,130 @@
+package rubbernecker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+type fakeService struct {
+	cards   rubbernecker.Cards
+	members rubbernecker.Members
+	err     error
+	invalid error
+	fetched rubbernecker.Cards
+}
+
+func (f *fakeService) AcceptStickers(rubbernecker.Stickers) {}
+
+func (f *fakeService) FetchCards(context.Context, rubbernecker.Status, map[string]string) error {
+	f.fetched = f.cards
+	return f.err
+}
+
+func (f *fakeService) FlattenStories() (rubbernecker.Cards, error) {
+	if f.invalid != nil {
+		return nil, f.invalid
+	}
+	if len(f.fetched) == 0 {
+		return nil, fmt.Errorf("fake: %w", rubbernecker.ErrNoCards)
+	}
+	return f.fetched, nil
+}
+
+func (f *fakeService) FetchMembers(context.Context) error {
+	return f.err
+}
+
+func (f *fakeService) FlattenMembers() (rubbernecker.Members, error) {
+	return f.members, nil
+}
+
+var _ = Describe("Sources", func() {
+	It("should FetchCards() from all the sources and tag them", func() {
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "platform", Service: &fakeService{cards: rubbernecker.Cards{{ID: 1}, {ID: 2}}}},
+			&rubbernecker.Source{Name: "empty", Service: &fakeService{}},
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{cards: rubbernecker.Cards{{ID: 3}}}},
+		}
+
+		cards, err := sources.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cards).To(HaveLen(3))
+		Expect(cards[0].Project).To(Equal("platform"))
+		Expect(cards[1].Project).To(Equal("platform"))
+		Expect(cards[2].Project).To(Equal("tenant"))
+	})
+
+	It("should fail to FetchCards() if any of the sources fails", func() {
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "platform", Service: &fakeService{cards: rubbernecker.Cards{{ID: 1}}}},
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{err: fmt.Errorf("test case: unknown error")}},
+		}
+
+		cards, err := sources.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("tenant"))
+		Expect(cards).To(BeNil())
+	})
+
+	It("should fail to FetchCards() if any of the sources fails to flatten its stories", func() {
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "platform", Service: &fakeService{cards: rubbernecker.Cards{{ID: 1}}}},
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{invalid: fmt.Errorf("test case: invalid id")}},
+		}
+
+		cards, err := sources.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+		Expect(err).To(MatchError("rubbernecker: could not fetch cards: tenant: test case: invalid id"))
+		Expect(cards).To(BeNil())
+	})
+
+	It("should keep the errors of the sources failing to FetchCards()", func() {
+		errTest := errors.New("test case: too many requests")
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "platform", Service: &fakeService{err: fmt.Errorf("test case: unknown error")}},
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{err: errTest}},
+		}
+
+		_, err := sources.FetchCards(context.Background(), rubbernecker.StatusAll, map[string]string{})
+
+		Expect(err).To(MatchError("rubbernecker: could not fetch cards: platform: test case: unknown error; tenant: test case: too many requests"))
+		Expect(errors.Is(err, errTest)).To(BeTrue())
+	})
+
+	It("should FetchMembers() from all the sources without duplicates", func() {
+		tester := &rubbernecker.Member{ID: 1, Name: "Tester"}
+
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "platform", Service: &fakeService{members: rubbernecker.Members{1: tester}}},
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{members: rubbernecker.Members{
+				1: &rubbernecker.Member{ID: 1, Name: "Tester"},
+				2: &rubbernecker.Member{ID: 2, Name: "Other"},
+			}}},
+		}
+
+		members, err := sources.FetchMembers(context.Background())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(members).To(HaveLen(2))
+		Expect(members[1]).To(BeIdenticalTo(tester))
+	})
+
+	It("should fail to FetchMembers() if any of the sources fails", func() {
+		sources := rubbernecker.Sources{
+			&rubbernecker.Source{Name: "tenant", Service: &fakeService{err: fmt.Errorf("test case: unknown error")}},
+		}
+
+		members, err := sources.FetchMembers(context.Background())
+
+		Expect(err).To(HaveOccurred())
+		Expect(members).To(BeNil())
+	})
+})
@@ -1,9 +1,85 @@
 package rubbernecker
 
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Shift will be a rubbernecker representation of a single period of someone
+// being on call. The shifts with no start or end are on call indefinitely.
+type Shift struct {
+	Member          string     `json:"member"`
+	Start           *time.Time `json:"start,omitempty"`
+	End             *time.Time `json:"end,omitempty"`
+	EscalationLevel int        `json:"escalation_level,omitempty"`
+}
+
+// Covers reports whether the shift is on call at the given time.
+func (s Shift) Covers(t time.Time) bool {
+	return (s.Start == nil || !s.Start.After(t)) && (s.End == nil || s.End.After(t))
+}
+
 // Support struct will contain any useful information, relevant to our users.
 type Support struct {
-	Type   string `json:"type,omitempty"`
-	Member string `json:"member,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	ScheduleID string     `json:"schedule_id,omitempty"`
+	Member     string     `json:"member,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	Next       *Shift     `json:"next,omitempty"`
+	Shifts     []Shift    `json:"shifts,omitempty"`
+}
+
+// NewSupport will work out who is on call for the schedule as of now, until
+// when, and who is on next, out of all the shifts. The lowest escalation level
+// on call is the one the handover is worked out for.
+func NewSupport(schedule, scheduleID string, shifts []Shift, now time.Time) *Support {
+	sorted := append([]Shift{}, shifts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.EscalationLevel != b.EscalationLevel {
+			return a.EscalationLevel < b.EscalationLevel
+		}
+		if a.Start == nil || b.Start == nil {
+			return a.Start == nil && b.Start != nil
+		}
+		return a.Start.Before(*b.Start)
+	})
+
+	support := &Support{
+		Type:       schedule,
+		ScheduleID: scheduleID,
+		Member:     "-",
+		Shifts:     sorted,
+	}
+
+	var current *Shift
+	for i := range sorted {
+		if sorted[i].Covers(now) {
+			current = &sorted[i]
+			break
+		}
+	}
+
+	if current != nil {
+		support.Member = current.Member
+		support.Until = current.End
+	}
+
+	for i := range sorted {
+		s := sorted[i]
+		if s.Start == nil || !s.Start.After(now) {
+			continue
+		}
+		if current != nil && s.EscalationLevel != current.EscalationLevel {
+			continue
+		}
+
+		support.Next = &s
+		break
+	}
+
+	return support
 }
 
 // SupportRota will contain a unique list prefixed with a type of support.
@@ -20,9 +96,38 @@ func (s SupportRota) Get(key string) *Support {
 	}
 }
 
+// Trim will cut the shifts of each of the schedules down to the ones on call
+// at the given time and the next one, so that the whole timeline is not
+// carried around with every version of the board.
+func (s SupportRota) Trim(now time.Time) SupportRota {
+	tmp := make(SupportRota, len(s))
+
+	for key, support := range s {
+		if support == nil {
+			tmp[key] = nil
+			continue
+		}
+
+		trimmed := *support
+		trimmed.Shifts = nil
+		for _, shift := range support.Shifts {
+			if shift.Covers(now) {
+				trimmed.Shifts = append(trimmed.Shifts, shift)
+			}
+		}
+		if support.Next != nil {
+			trimmed.Shifts = append(trimmed.Shifts, *support.Next)
+		}
+
+		tmp[key] = &trimmed
+	}
+
+	return tmp
+}
+
 // SupportService interface will establish a standard for any extension handling
 // support data.
 type SupportService interface {
-	FetchSupport() error
+	FetchSupport(context.Context) error
 	FlattenSupport() (SupportRota, error)
 }
@@ -1 +1,89 @@
 package rubbernecker_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Support", func() {
+	var (
+		now = time.Date(2018, 3, 7, 12, 0, 0, 0, time.UTC)
+		at  = func(hours int) *time.Time {
+			t := now.Add(time.Duration(hours) * time.Hour)
+			return &t
+		}
+	)
+
+	It("should work out who is on call NewSupport() and who is on next", func() {
+		support := rubbernecker.NewSupport("Primary", "PABC123", []rubbernecker.Shift{
+			{Member: "Carol", Start: at(30), End: at(54)},
+			{Member: "Dave", Start: at(-20), End: at(80), EscalationLevel: 2},
+			{Member: "Bob", Start: at(6), End: at(30)},
+			{Member: "Alice", Start: at(-18), End: at(6)},
+		}, now)
+
+		Expect(support.Type).To(Equal("Primary"))
+		Expect(support.ScheduleID).To(Equal("PABC123"))
+		Expect(support.Member).To(Equal("Alice"))
+		Expect(support.Until).To(Equal(at(6)))
+		Expect(support.Next.Member).To(Equal("Bob"))
+		Expect(support.Next.Start).To(Equal(at(6)))
+
+		members := []string{}
+		for _, s := range support.Shifts {
+			members = append(members, s.Member)
+		}
+		Expect(members).To(Equal([]string{"Alice", "Bob", "Carol", "Dave"}))
+	})
+
+	It("should Trim() the shifts down to the ones on call and the next one", func() {
+		rota := rubbernecker.SupportRota{
+			"primary": rubbernecker.NewSupport("Primary", "", []rubbernecker.Shift{
+				{Member: "Carol", Start: at(30), End: at(54)},
+				{Member: "Dave", Start: at(-20), End: at(80), EscalationLevel: 2},
+				{Member: "Bob", Start: at(6), End: at(30)},
+				{Member: "Alice", Start: at(-18), End: at(6)},
+			}, now),
+			"secondary": nil,
+		}
+
+		trimmed := rota.Trim(now)
+
+		members := []string{}
+		for _, s := range trimmed["primary"].Shifts {
+			members = append(members, s.Member)
+		}
+		Expect(members).To(Equal([]string{"Alice", "Dave", "Bob"}))
+		Expect(trimmed["primary"].Member).To(Equal("Alice"))
+		Expect(trimmed).To(HaveKeyWithValue("secondary", BeNil()))
+		Expect(rota["primary"].Shifts).To(HaveLen(4))
+	})
+
+	It("should find NewSupport() with nobody on call until the next shift", func() {
+		support := rubbernecker.NewSupport("Primary", "", []rubbernecker.Shift{
+			{Member: "Bob", Start: at(6), End: at(30)},
+		}, now)
+
+		Expect(support.Member).To(Equal("-"))
+		Expect(support.Until).To(BeNil())
+		Expect(support.Next.Member).To(Equal("Bob"))
+	})
+
+	It("should find NewSupport() on call indefinitely", func() {
+		support := rubbernecker.NewSupport("Primary", "", []rubbernecker.Shift{
+			{Member: "Alice"},
+		}, now)
+
+		Expect(support.Member).To(Equal("Alice"))
+		Expect(support.Until).To(BeNil())
+		Expect(support.Next).To(BeNil())
+	})
+
+	It("should Get() the placeholder of the unknown support", func() {
+		Expect(rubbernecker.SupportRota{}.Get("primary")).To(Equal(&rubbernecker.Support{Type: "primary", Member: "-"}))
+	})
+})
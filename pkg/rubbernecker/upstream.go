@@ -0,0 +1,143 @@
+package rubbernecker
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// UpstreamStatus will be a rubbernecker representation of how fetching from
+// one of the upstream services has been going.
+type UpstreamStatus struct {
+	Name                string        `json:"name"`
+	Fetches             int           `json:"fetches"`
+	Errors              int           `json:"errors"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	LastSuccess         time.Time     `json:"last_success"`
+	LastError           string        `json:"last_error,omitempty"`
+	LastErrorAt         time.Time     `json:"last_error_at"`
+	Stale               bool          `json:"stale"`
+	LastDuration        time.Duration `json:"-"`
+	TotalDuration       time.Duration `json:"-"`
+	StaleAfter          time.Duration `json:"-"`
+	Since               time.Time     `json:"-"`
+}
+
+// StaleSince reports whether the data from the upstream service is older than
+// expected as of the given time, and since when. Until the first successful
+// fetch, the data is as old as the tracking of the upstream service. The
+// upstream services with no StaleAfter never become stale.
+func (s UpstreamStatus) StaleSince(now time.Time) (time.Time, bool) {
+	since := s.LastSuccess
+	if since.IsZero() {
+		since = s.Since
+	}
+
+	if s.StaleAfter == 0 || now.Sub(since) <= s.StaleAfter {
+		return time.Time{}, false
+	}
+
+	return since, true
+}
+
+// Upstreams keeps track of the fetches from the upstream services. It is safe
+// for concurrent use.
+type Upstreams struct {
+	mu       sync.Mutex
+	statuses map[string]*UpstreamStatus
+}
+
+// NewUpstreams will compose Upstreams tracking the named services, so that
+// these are reported even before they have been fetched from.
+func NewUpstreams(names ...string) *Upstreams {
+	u := &Upstreams{
+		statuses: map[string]*UpstreamStatus{},
+	}
+
+	for _, name := range names {
+		u.Register(name, 0)
+	}
+
+	return u
+}
+
+// Register will start tracking the named upstream service, which data should
+// be considered stale if not successfully fetched for longer than staleAfter.
+func (u *Upstreams) Register(name string, staleAfter time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.status(name).StaleAfter = staleAfter
+}
+
+// status returns the status of the named upstream service, tracking it from
+// now on if it was not yet. It should be called with the lock held.
+func (u *Upstreams) status(name string) *UpstreamStatus {
+	status, ok := u.statuses[name]
+	if !ok {
+		status = &UpstreamStatus{Name: name, Since: time.Now()}
+		u.statuses[name] = status
+	}
+
+	return status
+}
+
+// Record will note the outcome of a fetch from the named upstream service,
+// which has finished at the given time.
+func (u *Upstreams) Record(name string, duration time.Duration, at time.Time, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	status := u.status(name)
+	status.Fetches++
+	status.LastDuration = duration
+	status.TotalDuration += duration
+
+	if err != nil {
+		status.Errors++
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		status.LastErrorAt = at
+
+		return
+	}
+
+	status.ConsecutiveFailures = 0
+	status.LastSuccess = at
+}
+
+// Statuses returns a copy of the statuses of all the upstream services, sorted
+// by their name.
+func (u *Upstreams) Statuses() []UpstreamStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]UpstreamStatus, 0, len(u.statuses))
+	for _, s := range u.statuses {
+		status := *s
+		_, status.Stale = status.StaleSince(now)
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// StaleSince reports whether the data from any of the upstream services is
+// stale, and since when the earliest of them is.
+func (u *Upstreams) StaleSince() (time.Time, bool) {
+	var earliest time.Time
+	now := time.Now()
+
+	for _, s := range u.Statuses() {
+		if since, stale := s.StaleSince(now); stale && (earliest.IsZero() || since.Before(earliest)) {
+			earliest = since
+		}
+	}
+
+	return earliest, !earliest.IsZero()
+}
@@ -0,0 +1,141 @@
+package rubbernecker_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Upstreams", func() {
+	var (
+		upstreams *rubbernecker.Upstreams
+	)
+
+	BeforeEach(func() {
+		upstreams = rubbernecker.NewUpstreams("stories", "members")
+	})
+
+	It("should report the named upstreams before these have been fetched from", func() {
+		statuses := upstreams.Statuses()
+
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses[0].Name).To(Equal("members"))
+		Expect(statuses[0].Fetches).To(Equal(0))
+		Expect(statuses[0].LastSuccess.IsZero()).To(BeTrue())
+		Expect(statuses[1].Name).To(Equal("stories"))
+	})
+
+	It("should Record() the successful and failed fetches", func() {
+		upstreams.Record("stories", time.Second, time.Now(), fmt.Errorf("test: 500"))
+		upstreams.Record("stories", time.Second, time.Now(), fmt.Errorf("test: 429"))
+
+		statuses := upstreams.Statuses()
+		Expect(statuses[1].Fetches).To(Equal(2))
+		Expect(statuses[1].Errors).To(Equal(2))
+		Expect(statuses[1].ConsecutiveFailures).To(Equal(2))
+		Expect(statuses[1].LastError).To(Equal("test: 429"))
+		Expect(statuses[1].LastErrorAt.IsZero()).To(BeFalse())
+		Expect(statuses[1].LastSuccess.IsZero()).To(BeTrue())
+
+		upstreams.Record("stories", time.Second, time.Now(), nil)
+
+		statuses = upstreams.Statuses()
+		Expect(statuses[1].Fetches).To(Equal(3))
+		Expect(statuses[1].Errors).To(Equal(2))
+		Expect(statuses[1].ConsecutiveFailures).To(Equal(0))
+		Expect(statuses[1].LastError).To(Equal("test: 429"))
+		Expect(statuses[1].LastSuccess.IsZero()).To(BeFalse())
+	})
+
+	It("should Record() the fetches from the upstreams not named upfront", func() {
+		upstreams.Record("support", 2*time.Second, time.Now(), nil)
+		upstreams.Record("support", time.Second, time.Now(), nil)
+
+		statuses := upstreams.Statuses()
+		Expect(statuses).To(HaveLen(3))
+		Expect(statuses[2].Name).To(Equal("support"))
+		Expect(statuses[2].LastDuration).To(Equal(time.Second))
+		Expect(statuses[2].TotalDuration).To(Equal(3 * time.Second))
+	})
+
+	It("should tell the UpstreamStatus StaleSince() the last success", func() {
+		now := time.Now()
+		status := rubbernecker.UpstreamStatus{
+			StaleAfter:  time.Minute,
+			Since:       now.Add(-time.Hour),
+			LastSuccess: now.Add(-2 * time.Minute),
+		}
+
+		since, stale := status.StaleSince(now)
+		Expect(stale).To(BeTrue())
+		Expect(since).To(Equal(status.LastSuccess))
+
+		_, stale = status.StaleSince(now.Add(-90 * time.Second))
+		Expect(stale).To(BeFalse())
+
+		status.StaleAfter = 0
+		_, stale = status.StaleSince(now)
+		Expect(stale).To(BeFalse())
+	})
+
+	It("should tell the UpstreamStatus StaleSince() it is tracked if it has never succeeded", func() {
+		now := time.Now()
+		status := rubbernecker.UpstreamStatus{
+			StaleAfter: time.Minute,
+			Since:      now.Add(-time.Hour),
+		}
+
+		since, stale := status.StaleSince(now)
+		Expect(stale).To(BeTrue())
+		Expect(since).To(Equal(status.Since))
+	})
+
+	It("should Register() upstreams to become stale", func() {
+		upstreams.Register("stories", time.Millisecond)
+		upstreams.Register("support", time.Hour)
+
+		_, stale := upstreams.StaleSince()
+		Expect(stale).To(BeFalse())
+
+		Eventually(func() bool {
+			_, stale := upstreams.StaleSince()
+			return stale
+		}).Should(BeTrue())
+
+		statuses := upstreams.Statuses()
+		Expect(statuses).To(HaveLen(3))
+		Expect(statuses[0].Stale).To(BeFalse())
+		Expect(statuses[1].Stale).To(BeTrue())
+		Expect(statuses[2].Stale).To(BeFalse())
+
+		upstreams.Register("stories", time.Hour)
+		upstreams.Record("stories", time.Second, time.Now(), nil)
+
+		_, stale = upstreams.StaleSince()
+		Expect(stale).To(BeFalse())
+	})
+
+	It("should be safe to Record() concurrently", func() {
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				upstreams.Record("stories", time.Second, time.Now(), nil)
+				upstreams.Statuses()
+			}()
+		}
+
+		wg.Wait()
+
+		Expect(upstreams.Statuses()[1].Fetches).To(Equal(10))
+	})
+})
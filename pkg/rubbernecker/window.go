@@ -0,0 +1,178 @@
+package rubbernecker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	windowDays        = "days"
+	windowWorkingDays = "working-days"
+	windowIteration   = "iteration"
+	windowSince       = "since"
+)
+
+// DoneWindow describes how far back the done cards should be shown from. It
+// can be a number of days, a number of working days, the start of the current
+// iteration or the last given weekday, e.g. for weekly demos. The zero
+// DoneWindow does not limit the done cards at all.
+type DoneWindow struct {
+	kind    string
+	days    int
+	weekday time.Weekday
+}
+
+// IterationService interface will establish a standard for any extension
+// aware of the iterations of the project.
+type IterationService interface {
+	CurrentIterationStart(context.Context) (time.Time, error)
+}
+
+// ParseDoneWindow will read the window in one of the following formats:
+// "days:5", "working-days:5", "iteration" or "since:monday".
+func ParseDoneWindow(value string) (DoneWindow, error) {
+	kind, arg := value, ""
+	if i := strings.Index(value, ":"); i >= 0 {
+		kind, arg = value[:i], value[i+1:]
+	}
+	kind = strings.ToLower(strings.TrimSpace(kind))
+
+	switch kind {
+	case windowDays, windowWorkingDays:
+		days, err := strconv.Atoi(arg)
+		if err != nil || days < 0 {
+			return DoneWindow{}, fmt.Errorf("rubbernecker: invalid number of days in done window %q", value)
+		}
+
+		return DoneWindow{kind: kind, days: days}, nil
+	case windowIteration:
+		if arg != "" {
+			break
+		}
+
+		return DoneWindow{kind: windowIteration}, nil
+	case windowSince:
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(d.String(), arg) {
+				return DoneWindow{kind: windowSince, weekday: d}, nil
+			}
+		}
+
+		return DoneWindow{}, fmt.Errorf("rubbernecker: invalid weekday in done window %q", value)
+	}
+
+	return DoneWindow{}, fmt.Errorf("rubbernecker: invalid done window %q", value)
+}
+
+func (w DoneWindow) String() string {
+	switch w.kind {
+	case windowDays, windowWorkingDays:
+		return fmt.Sprintf("%s:%d", w.kind, w.days)
+	case windowSince:
+		return fmt.Sprintf("%s:%s", w.kind, strings.ToLower(w.weekday.String()))
+	default:
+		return w.kind
+	}
+}
+
+// NeedsIteration reports whether the start of the current iteration is
+// required to work out the window.
+func (w DoneWindow) NeedsIteration() bool {
+	return w.kind == windowIteration
+}
+
+// Since works out the beginning of the window, as of the given time. The days
+// start at midnight UTC and Saturdays and Sundays are not working days.
+func (w DoneWindow) Since(now, iterationStart time.Time) (time.Time, error) {
+	year, month, day := now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	switch w.kind {
+	case windowDays:
+		return today.AddDate(0, 0, -w.days), nil
+	case windowWorkingDays:
+		since := today
+		for days := 0; days < w.days; {
+			since = since.AddDate(0, 0, -1)
+			if since.Weekday() != time.Saturday && since.Weekday() != time.Sunday {
+				days++
+			}
+		}
+
+		return since, nil
+	case windowSince:
+		since := today
+		for since.Weekday() != w.weekday {
+			since = since.AddDate(0, 0, -1)
+		}
+
+		return since, nil
+	case windowIteration:
+		if iterationStart.IsZero() {
+			return time.Time{}, fmt.Errorf("rubbernecker: the start of the iteration is unknown")
+		}
+
+		return iterationStart, nil
+	default:
+		return time.Time{}, nil
+	}
+}
+
+// IterationStart will find the start of the current iteration of all the
+// sources which implement the IterationService. The earliest one is used, so
+// that none of the iterations is cut short.
+func (ss Sources) IterationStart(ctx context.Context) (time.Time, error) {
+	var start time.Time
+
+	for _, s := range ss {
+		is, ok := s.Service.(IterationService)
+		if !ok {
+			continue
+		}
+
+		t, err := is.CurrentIterationStart(ctx)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("rubbernecker: could not fetch the iteration: %s: %w", s.Name, err)
+		}
+
+		if start.IsZero() || t.Before(start) {
+			start = t
+		}
+	}
+
+	if start.IsZero() {
+		return time.Time{}, fmt.Errorf("rubbernecker: none of the sources is aware of iterations")
+	}
+
+	return start, nil
+}
+
+// AcceptedSince will filter the cards accepted at or after the given time.
+// The cards with no acceptance time are always kept.
+func (c Cards) AcceptedSince(since time.Time) Cards {
+	tmp := Cards{}
+
+	for _, card := range c {
+		if card.AcceptedAt == nil || !card.AcceptedAt.Before(since) {
+			tmp = append(tmp, card)
+		}
+	}
+
+	return tmp
+}
+
+// SortByAcceptedAt will sort the cards in place, the most recently accepted
+// first. The cards with no acceptance time keep their order at the end.
+func (c Cards) SortByAcceptedAt() {
+	sort.SliceStable(c, func(i, j int) bool {
+		if c[j].AcceptedAt == nil {
+			return c[i].AcceptedAt != nil
+		}
+
+		return c[i].AcceptedAt != nil && c[i].AcceptedAt.After(*c[j].AcceptedAt)
+	})
+}
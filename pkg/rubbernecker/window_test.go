@@ -0,0 +1,134 @@
+package rubbernecker_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+type iterationService struct {
+	fakeService
+	start time.Time
+	err   error
+}
+
+func (s *iterationService) CurrentIterationStart(context.Context) (time.Time, error) {
+	return s.start, s.err
+}
+
+var _ = Describe("DoneWindow", func() {
+	var (
+		// Thursday afternoon
+		now      = time.Date(2018, 10, 18, 15, 30, 0, 0, time.UTC)
+		midnight = func(day int) time.Time {
+			return time.Date(2018, 10, day, 0, 0, 0, 0, time.UTC)
+		}
+	)
+
+	DescribeTable("should ParseDoneWindow() and work out Since()",
+		func(value string, since time.Time) {
+			w, err := rubbernecker.ParseDoneWindow(value)
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err := w.Since(now, midnight(8))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).To(Equal(since))
+		},
+		Entry("a number of days", "days:5", midnight(13)),
+		Entry("no days at all", "days:0", midnight(18)),
+		Entry("a number of working days", "working-days:5", midnight(11)),
+		Entry("a number of working days, case insensitive", "Working-Days:3", midnight(15)),
+		Entry("the iteration", "iteration", midnight(8)),
+		Entry("a weekday", "since:monday", midnight(15)),
+		Entry("the same weekday", "since:Thursday", midnight(18)),
+		Entry("a weekday in the previous week", "since:friday", midnight(12)),
+	)
+
+	DescribeTable("should fail to ParseDoneWindow()",
+		func(value string) {
+			_, err := rubbernecker.ParseDoneWindow(value)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("unknown", "fortnight"),
+		Entry("missing days", "days"),
+		Entry("negative days", "working-days:-1"),
+		Entry("iteration with an argument", "iteration:2"),
+		Entry("unknown weekday", "since:someday"),
+	)
+
+	It("should convert the window to String()", func() {
+		for _, value := range []string{"days:5", "working-days:2", "iteration", "since:monday"} {
+			w, err := rubbernecker.ParseDoneWindow(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.String()).To(Equal(value))
+		}
+	})
+
+	It("should tell if the window NeedsIteration()", func() {
+		iteration, _ := rubbernecker.ParseDoneWindow("iteration")
+		days, _ := rubbernecker.ParseDoneWindow("days:5")
+
+		Expect(iteration.NeedsIteration()).To(BeTrue())
+		Expect(days.NeedsIteration()).To(BeFalse())
+	})
+
+	It("should fail to work out Since() the iteration which is unknown", func() {
+		w, _ := rubbernecker.ParseDoneWindow("iteration")
+
+		_, err := w.Since(now, time.Time{})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not limit anything with the zero window", func() {
+		since, err := rubbernecker.DoneWindow{}.Since(now, time.Time{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(since.IsZero()).To(BeTrue())
+	})
+
+	It("should find the earliest IterationStart() of the sources", func() {
+		sources := rubbernecker.Sources{
+			{Name: "none", Service: &fakeService{}},
+			{Name: "later", Service: &iterationService{start: midnight(15)}},
+			{Name: "earlier", Service: &iterationService{start: midnight(8)}},
+		}
+
+		start, err := sources.IterationStart(context.Background())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(start).To(Equal(midnight(8)))
+	})
+
+	It("should fail to find the IterationStart() of the sources", func() {
+		_, err := rubbernecker.Sources{{Name: "none", Service: &fakeService{}}}.IterationStart(context.Background())
+		Expect(err).To(HaveOccurred())
+
+		_, err = rubbernecker.Sources{{Name: "faulty", Service: &iterationService{err: fmt.Errorf("test")}}}.IterationStart(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("faulty: test")))
+	})
+
+	It("should filter the cards AcceptedSince() and SortByAcceptedAt()", func() {
+		earlier, later := midnight(10), midnight(17)
+		cards := rubbernecker.Cards{
+			{Title: "Earlier", AcceptedAt: &earlier},
+			{Title: "Unknown"},
+			{Title: "Later", AcceptedAt: &later},
+		}
+
+		Expect(cards.AcceptedSince(midnight(11))).To(HaveLen(2))
+		Expect(cards.AcceptedSince(midnight(10))).To(HaveLen(3))
+
+		cards.SortByAcceptedAt()
+
+		Expect(cards[0].Title).To(Equal("Later"))
+		Expect(cards[1].Title).To(Equal("Earlier"))
+		Expect(cards[2].Title).To(Equal("Unknown"))
+	})
+})
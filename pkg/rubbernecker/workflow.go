@@ -0,0 +1,148 @@
+package rubbernecker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Column will be a single swimlane of the board. The cards are placed in the
+// column by their status, which is the name of the column, and the extensions
+// convert the states of the upstream service listed in the column into it. The
+// column can limit how many cards it holds, as well as how many of them each
+// person holds.
+type Column struct {
+	Name        string   `yaml:"name" json:"name"`
+	Title       string   `yaml:"title" json:"title"`
+	States      []string `yaml:"states" json:"states"`
+	Limit       int      `yaml:"limit" json:"limit,omitempty"`
+	PersonLimit int      `yaml:"person_limit" json:"person_limit,omitempty"`
+	ShowCount   bool     `yaml:"show_count" json:"show_count"`
+	InPlay      bool     `yaml:"in_play" json:"in_play"`
+}
+
+// Workflow will be a rubbernecker representation of the columns of the board,
+// in the order they are shown in. The column named after the StatusDone is
+// where the done cards go.
+type Workflow []Column
+
+// DefaultWorkflow is the workflow of the board unless configured otherwise,
+// mapping the PivotalTracker states into the columns.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		{Name: StatusScheduled.String(), Title: "Next", States: []string{"unstarted", "planned"}},
+		{Name: StatusDoing.String(), Title: "Doing", States: []string{"started"}, ShowCount: true, InPlay: true},
+		{Name: StatusReviewal.String(), Title: "Reviewing", States: []string{"finished"}, Limit: 4, ShowCount: true, InPlay: true},
+		{Name: StatusApproval.String(), Title: "Approving", States: []string{"delivered"}, Limit: 5, ShowCount: true, InPlay: true},
+		{Name: StatusRejected.String(), Title: "Rejected", States: []string{"rejected"}, InPlay: true},
+		{Name: StatusDone.String(), Title: "Done", States: []string{"accepted"}, ShowCount: true},
+	}
+}
+
+// Validate will make sure the columns are named uniquely, none of the states
+// is mapped into several of them and there is the done column.
+func (w Workflow) Validate() error {
+	names := map[string]bool{}
+	states := map[string]string{}
+
+	for _, c := range w {
+		if c.Name == "" {
+			return fmt.Errorf("rubbernecker: workflow column with no name")
+		}
+
+		if names[c.Name] {
+			return fmt.Errorf("rubbernecker: workflow column %q is defined more than once", c.Name)
+		}
+		names[c.Name] = true
+
+		if c.Limit < 0 || c.PersonLimit < 0 {
+			return fmt.Errorf("rubbernecker: workflow column %q has a negative limit", c.Name)
+		}
+
+		for _, s := range c.States {
+			state := strings.ToLower(strings.TrimSpace(s))
+			if other, ok := states[state]; ok {
+				return fmt.Errorf("rubbernecker: workflow state %q is mapped into both %q and %q", s, other, c.Name)
+			}
+			states[state] = c.Name
+		}
+	}
+
+	if !names[StatusDone.String()] {
+		return fmt.Errorf("rubbernecker: workflow has no %q column", StatusDone.String())
+	}
+
+	return nil
+}
+
+// Column finds the column of the given name.
+func (w Workflow) Column(name string) (Column, bool) {
+	for _, c := range w {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	return Column{}, false
+}
+
+// Convert finds the column the state of the upstream service is mapped into.
+// The states are matched case insensitively.
+func (w Workflow) Convert(state string) (Column, bool) {
+	state = strings.TrimSpace(state)
+
+	for _, c := range w {
+		for _, s := range c.States {
+			if strings.EqualFold(strings.TrimSpace(s), state) {
+				return c, true
+			}
+		}
+	}
+
+	return Column{}, false
+}
+
+// Rejected finds the column the rejected cards go to. That is the one the
+// rejected state is mapped into, or the one named after the StatusRejected for
+// the extensions converting the states into the statuses themselves.
+func (w Workflow) Rejected() (Column, bool) {
+	if c, ok := w.Convert(StatusRejected.String()); ok {
+		return c, true
+	}
+
+	return w.Column(StatusRejected.String())
+}
+
+// States lists the states of the upstream service which should be requested
+// for the given status. Similar to the extensions, StatusAll means everything
+// that is not done yet.
+func (w Workflow) States(status Status) []string {
+	states := []string{}
+
+	for _, c := range w {
+		done := c.Name == StatusDone.String()
+		if (status == StatusAll && !done) || c.Name == status.String() {
+			states = append(states, c.States...)
+		}
+	}
+
+	return states
+}
+
+// InPlay lists the columns of the cards being worked on.
+func (w Workflow) InPlay() Workflow {
+	tmp := Workflow{}
+
+	for _, c := range w {
+		if c.InPlay {
+			tmp = append(tmp, c)
+		}
+	}
+
+	return tmp
+}
+
+// WorkflowService interface will establish a standard for any extension
+// converting its states into the columns of a configurable workflow.
+type WorkflowService interface {
+	AcceptWorkflow(Workflow)
+}
@@ -0,0 +1,90 @@
+package rubbernecker_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+)
+
+var _ = Describe("Workflow", func() {
+	var workflow rubbernecker.Workflow
+
+	BeforeEach(func() {
+		workflow = rubbernecker.Workflow{
+			{Name: "next", States: []string{"To Do"}},
+			{Name: "doing", States: []string{"In Progress"}, InPlay: true},
+			{Name: "in-qa", Title: "In QA", States: []string{"QA", "Testing"}, Limit: 2, InPlay: true},
+			{Name: "done", States: []string{"Done", "Closed"}},
+		}
+	})
+
+	It("should Validate() the DefaultWorkflow()", func() {
+		Expect(rubbernecker.DefaultWorkflow().Validate()).To(Succeed())
+	})
+
+	It("should Validate() the workflow", func() {
+		Expect(workflow.Validate()).To(Succeed())
+	})
+
+	It("should fail to Validate() the invalid workflow", func() {
+		invalid := map[string]rubbernecker.Workflow{
+			"with no name":                         {{States: []string{"started"}}, {Name: "done"}},
+			`"doing" is defined more than once`:    {{Name: "doing"}, {Name: "doing"}, {Name: "done"}},
+			`"doing" has a negative limit`:         {{Name: "doing", Limit: -1}, {Name: "done"}},
+			`"in-qa" has a negative limit`:         {{Name: "in-qa", PersonLimit: -1}, {Name: "done"}},
+			`mapped into both "doing" and "in-qa"`: {{Name: "doing", States: []string{"started"}}, {Name: "in-qa", States: []string{"Started"}}, {Name: "done"}},
+			`no "done" column`:                     {{Name: "doing"}},
+		}
+
+		for message, w := range invalid {
+			Expect(w.Validate()).To(MatchError(ContainSubstring(message)))
+		}
+	})
+
+	It("should Convert() the upstream states case insensitively", func() {
+		column, ok := workflow.Convert("testing")
+		Expect(ok).To(BeTrue())
+		Expect(column.Name).To(Equal("in-qa"))
+
+		_, ok = workflow.Convert("icebox")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should find the Column() by its name", func() {
+		column, ok := workflow.Column("in-qa")
+		Expect(ok).To(BeTrue())
+		Expect(column.Title).To(Equal("In QA"))
+
+		_, ok = workflow.Column("reviewing")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should find the column the cards are Rejected() into", func() {
+		column, ok := rubbernecker.DefaultWorkflow().Rejected()
+		Expect(ok).To(BeTrue())
+		Expect(column.Name).To(Equal("rejected"))
+
+		column, ok = rubbernecker.Workflow{{Name: "rework", States: []string{"Rejected"}}, {Name: "done"}}.Rejected()
+		Expect(ok).To(BeTrue())
+		Expect(column.Name).To(Equal("rework"))
+
+		_, ok = workflow.Rejected()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should list the States() of the statuses", func() {
+		Expect(workflow.States(rubbernecker.StatusAll)).To(Equal([]string{"To Do", "In Progress", "QA", "Testing"}))
+		Expect(workflow.States(rubbernecker.StatusDone)).To(Equal([]string{"Done", "Closed"}))
+		Expect(workflow.States(rubbernecker.StatusDoing)).To(Equal([]string{"In Progress"}))
+		Expect(workflow.States(rubbernecker.StatusReviewal)).To(BeEmpty())
+	})
+
+	It("should list the columns InPlay()", func() {
+		inPlay := workflow.InPlay()
+
+		Expect(inPlay).To(HaveLen(2))
+		Expect(inPlay[0].Name).To(Equal("doing"))
+		Expect(inPlay[1].Name).To(Equal("in-qa"))
+	})
+})
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tmpPrefix marks the files being written, which are not values yet.
+const tmpPrefix = ".tmp-"
+
+// SetupEngine should compose the storage, keeping the values as files in the
+// given directory. The directory is created if it does not exist yet.
+func SetupEngine(dir string) (*Engine, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("storage: could not create the directory: %s", err)
+	}
+
+	return &Engine{
+		dir: dir,
+	}, nil
+}
+
+// Engine module configuration.
+type Engine struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// Get a specific value from a file store. The value is returned as raw JSON
+// bytes, for the caller to decode.
+func (e *Engine) Get(key string) (interface{}, error) {
+	path, err := e.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	value, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("storage: key not found in storage")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: could not read %s: %s", key, err)
+	}
+
+	return value, nil
+}
+
+// Put specific value into a file store. Byte slices are stored as they are,
+// anything else is encoded as JSON. The file is replaced atomically, so the
+// readers never see it written partially.
+func (e *Engine) Put(key string, value interface{}) error {
+	path, err := e.path(key)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		data, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("storage: could not encode %s: %s", key, err)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tmp, err := ioutil.TempFile(e.dir, tmpPrefix)
+	if err != nil {
+		return fmt.Errorf("storage: could not write %s: %s", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("storage: could not write %s: %s", key, err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("storage: could not write %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// Delete specific value from a file store. Deleting a key which does not exist
+// is not an error.
+func (e *Engine) Delete(key string) error {
+	path, err := e.path(key)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("storage: could not delete %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// Keys lists all the keys in a file store, sorted.
+func (e *Engine) Keys() ([]string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.keys()
+}
+
+// List all the values in a file store with keys starting with the prefix. The
+// values are returned as raw JSON bytes, same as with Get.
+func (e *Engine) List(prefix string) (map[string]interface{}, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	keys, err := e.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		value, err := ioutil.ReadFile(filepath.Join(e.dir, url.QueryEscape(key)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("storage: could not read %s: %s", key, err)
+		}
+
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+func (e *Engine) keys() ([]string, error) {
+	files, err := ioutil.ReadDir(e.dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: could not list the keys: %s", err)
+	}
+
+	keys := []string{}
+
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), tmpPrefix) {
+			continue
+		}
+
+		key, err := url.QueryUnescape(f.Name())
+		if err != nil {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (e *Engine) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+
+	return filepath.Join(e.dir, url.QueryEscape(key)), nil
+}
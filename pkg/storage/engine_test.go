@@ -0,0 +1,158 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alphagov/paas-rubbernecker/pkg/rubbernecker"
+	"github.com/alphagov/paas-rubbernecker/pkg/storage"
+)
+
+var _ = Describe("Storage Engine", func() {
+	var (
+		dir string
+		se  *storage.Engine
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		dir = GinkgoT().TempDir()
+		se, err = storage.SetupEngine(filepath.Join(dir, "snapshots"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should successfully SetupEngine() creating the directory", func() {
+		info, err := os.Stat(filepath.Join(dir, "snapshots"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.IsDir()).To(BeTrue())
+	})
+
+	It("should fail to SetupEngine() in place of a file", func() {
+		file := filepath.Join(dir, "file")
+		Expect(ioutil.WriteFile(file, []byte{}, 0o644)).To(Succeed())
+
+		_, err := storage.SetupEngine(file)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should Put() and Get() bytes as they are", func() {
+		var me rubbernecker.PersistanceEngine = se
+
+		Expect(me.Put("snapshot.latest", []byte(`{"test":true}`))).To(Succeed())
+
+		value, err := me.Get("snapshot.latest")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal([]byte(`{"test":true}`)))
+	})
+
+	It("should Put() any other value as JSON", func() {
+		Expect(se.Put("number", 123)).To(Succeed())
+
+		value, err := se.Get("number")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(MatchJSON(`123`))
+	})
+
+	It("should keep the values between the engines", func() {
+		Expect(se.Put("test/key", []byte(`"persisted"`))).To(Succeed())
+
+		another, err := storage.SetupEngine(filepath.Join(dir, "snapshots"))
+		Expect(err).NotTo(HaveOccurred())
+
+		value, err := another.Get("test/key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(MatchJSON(`"persisted"`))
+	})
+
+	It("should overwrite the value on Put()", func() {
+		Expect(se.Put("key", []byte(`1`))).To(Succeed())
+		Expect(se.Put("key", []byte(`2`))).To(Succeed())
+
+		value, err := se.Get("key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(MatchJSON(`2`))
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, "snapshots"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("should fail to Put() a value which cannot be encoded", func() {
+		err := se.Put("key", make(chan int))
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to Get() value", func() {
+		value, err := se.Get("missing")
+
+		Expect(err).To(MatchError(ContainSubstring("key not found")))
+		Expect(value).To(BeNil())
+	})
+
+	It("should Delete() value successfully", func() {
+		Expect(se.Put("key", []byte(`1`))).To(Succeed())
+
+		Expect(se.Delete("key")).To(Succeed())
+		Expect(se.Delete("key")).To(Succeed())
+
+		_, err := se.Get("key")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should list the sorted Keys() and List() the values with the prefix", func() {
+		Expect(se.Put("snapshot.2", []byte(`2`))).To(Succeed())
+		Expect(se.Put("snapshot.1", []byte(`1`))).To(Succeed())
+		Expect(se.Put("test/key", []byte(`3`))).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "snapshots", ".tmp-123"), []byte{}, 0o644)).To(Succeed())
+
+		keys, err := se.Keys()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(Equal([]string{"snapshot.1", "snapshot.2", "test/key"}))
+
+		values, err := se.List("snapshot.")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]interface{}{
+			"snapshot.1": []byte(`1`),
+			"snapshot.2": []byte(`2`),
+		}))
+	})
+
+	It("should be safe to use concurrently", func() {
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				for j := 0; j < 20; j++ {
+					Expect(se.Put("shared", j)).To(Succeed())
+					_, err := se.Get("shared")
+					Expect(err).NotTo(HaveOccurred())
+					_, err = se.List("")
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	})
+
+	It("should refuse invalid keys", func() {
+		Expect(se.Put("..", []byte(`1`))).NotTo(Succeed())
+
+		_, err := se.Get("")
+		Expect(err).To(HaveOccurred())
+	})
+})